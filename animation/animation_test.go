@@ -1,8 +1,13 @@
 package animation
 
 import (
+	"math"
+	"runtime"
 	"testing"
 	"time"
+
+	"github.com/flavioheleno/oled-emulator/device"
+	"github.com/flavioheleno/oled-emulator/graphics"
 )
 
 func TestEasingFunctions(t *testing.T) {
@@ -39,6 +44,26 @@ func TestEasingFunctions(t *testing.T) {
 	}
 }
 
+func TestEaseOutSine(t *testing.T) {
+	if EaseOutSine(0) != 0 || EaseOutSine(1) != 1 {
+		t.Errorf("expected EaseOutSine to span 0 to 1, got %v to %v", EaseOutSine(0), EaseOutSine(1))
+	}
+
+	previous := EaseOutSine(0)
+	for i := 1; i <= 100; i++ {
+		value := EaseOutSine(float64(i) / 100)
+		if value < previous {
+			t.Fatalf("EaseOutSine decreases at t=%.2f: %v < %v", float64(i)/100, value, previous)
+		}
+		previous = value
+	}
+
+	// Decelerating: ahead of linear at the midpoint
+	if EaseOutSine(0.5) <= 0.5 {
+		t.Errorf("expected EaseOutSine(0.5) above 0.5, got %v", EaseOutSine(0.5))
+	}
+}
+
 func TestTweenBasic(t *testing.T) {
 	tween := NewTween(0, 100, 1*time.Second, Linear)
 
@@ -92,6 +117,86 @@ func TestTweenCallback(t *testing.T) {
 	}
 }
 
+func TestTweenLoop(t *testing.T) {
+	loops, completions := 0, 0
+	tween := NewTween(0, 10, 100*time.Millisecond, Linear).
+		SetLoop(3).
+		SetOnLoop(func(cycle int) { loops = cycle }).
+		SetOnComplete(func() { completions++ })
+
+	if tween.Update(0.15) {
+		t.Fatal("tween should keep running after the first cycle")
+	}
+	if loops != 1 || math.Abs(tween.GetValue()-5) > 1e-9 {
+		t.Errorf("expected 1 loop at value 5, got %d loops at %v", loops, tween.GetValue())
+	}
+
+	tween.Update(0.1)
+	if !tween.Update(0.1) {
+		t.Error("tween should be complete after three cycles")
+	}
+	if loops != 2 || completions != 1 {
+		t.Errorf("expected 2 loop callbacks and 1 completion, got %d and %d", loops, completions)
+	}
+	if tween.GetValue() != 10 {
+		t.Errorf("expected final value 10, got %v", tween.GetValue())
+	}
+}
+
+func TestTweenYoyo(t *testing.T) {
+	tween := NewTween(0, 10, 100*time.Millisecond, Linear).SetLoop(0).SetYoyo(true)
+
+	tween.Update(0.125)
+	if math.Abs(tween.GetValue()-7.5) > 1e-9 {
+		t.Errorf("expected value 7.5 on the way back, got %v", tween.GetValue())
+	}
+
+	// An infinite loop never completes
+	for i := 0; i < 100; i++ {
+		if tween.Update(0.05) {
+			t.Fatal("infinite tween should not complete")
+		}
+	}
+	if tween.IsComplete() {
+		t.Error("infinite tween should not report completion")
+	}
+}
+
+func TestTweenDelay(t *testing.T) {
+	tween := NewTween(10, 20, 100*time.Millisecond, Linear).SetDelay(200 * time.Millisecond)
+
+	tween.Update(0.1)
+	if tween.GetValue() != 10 {
+		t.Errorf("expected from value during the delay, got %v", tween.GetValue())
+	}
+
+	// The remainder of a step past the delay animates
+	tween.Update(0.15)
+	if math.Abs(tween.GetValue()-15) > 1e-9 {
+		t.Errorf("expected value 15, got %v", tween.GetValue())
+	}
+
+	if !tween.Update(0.05) {
+		t.Error("tween should complete after delay plus duration")
+	}
+}
+
+func TestDelayTweenInSequence(t *testing.T) {
+	second := NewTween(0, 1, 100*time.Millisecond, Linear)
+	seq := NewSequenceTween(NewTween(0, 1, 100*time.Millisecond, Linear), NewDelayTween(50*time.Millisecond), second)
+
+	seq.Update(0.1)
+	seq.Update(0.05)
+	if second.GetProgress() != 0 {
+		t.Error("tween after the delay should not have started")
+	}
+
+	seq.Update(0.05)
+	if math.Abs(second.GetProgress()-0.5) > 1e-9 {
+		t.Errorf("expected second tween at 0.5, got %v", second.GetProgress())
+	}
+}
+
 func TestColorTween(t *testing.T) {
 	ct := NewColorTween(0, 0, 0, 255, 255, 255, 1*time.Second, Linear)
 
@@ -116,6 +221,35 @@ func TestColorTween(t *testing.T) {
 	}
 }
 
+func TestColorTweenLinearLight(t *testing.T) {
+	ct := NewColorTween(0, 0, 0, 255, 255, 255, 100*time.Millisecond, Linear).SetLinearLight(true)
+	ct.Update(0.05)
+
+	// Half the light of white is about 188 in sRGB, brighter than the 127 of a plain lerp
+	r, g, b := ct.GetColor()
+	if r != g || g != b || r < 185 || r > 190 {
+		t.Errorf("expected linear-light midpoint near 188, got (%d, %d, %d)", r, g, b)
+	}
+}
+
+func TestGrayTween(t *testing.T) {
+	var levels []byte
+	gt := NewGrayTween(0x00, 0x0F, 100*time.Millisecond, Linear).
+		SetOnUpdate(func(level byte) { levels = append(levels, level) })
+
+	gt.Update(0.05)
+	if level := gt.GetLevel(); level < 0x07 || level > 0x08 {
+		t.Errorf("expected level near 0x08 at the midpoint, got 0x%02X", level)
+	}
+
+	if !gt.Update(0.05) || gt.GetLevel() != 0x0F {
+		t.Errorf("expected completion at 0x0F, got 0x%02X", gt.GetLevel())
+	}
+	if len(levels) != 2 || levels[1] != 0x0F {
+		t.Errorf("unexpected update levels: %v", levels)
+	}
+}
+
 func TestSequenceTween(t *testing.T) {
 	t1 := NewTween(0, 100, 100*time.Millisecond, Linear)
 	t2 := NewTween(100, 0, 100*time.Millisecond, Linear)
@@ -165,6 +299,128 @@ func TestParallelTween(t *testing.T) {
 	}
 }
 
+func TestSequenceTweenProgress(t *testing.T) {
+	seq := NewSequenceTween(
+		NewTween(0, 1, 100*time.Millisecond, Linear),
+		NewTween(0, 1, 100*time.Millisecond, Linear),
+	)
+
+	// Halfway through the chain is the end of the first tween
+	seq.Update(0.1)
+	if progress := seq.GetProgress(); math.Abs(progress-0.5) > 1e-9 {
+		t.Errorf("expected progress 0.5, got %v", progress)
+	}
+
+	seq.Update(0.05)
+	if progress := seq.GetProgress(); math.Abs(progress-0.75) > 1e-9 {
+		t.Errorf("expected progress 0.75, got %v", progress)
+	}
+}
+
+func TestSequenceTweenProgressDelayAndLoops(t *testing.T) {
+	// 100ms delay + 100ms, then two 100ms cycles: 400ms in total
+	seq := NewSequenceTween(
+		NewTween(0, 1, 100*time.Millisecond, Linear).SetDelay(100*time.Millisecond),
+		NewTween(0, 1, 100*time.Millisecond, Linear).SetLoop(2),
+	)
+
+	steps := []struct {
+		dt       float64
+		expected float64
+	}{
+		{0.05, 0.125}, // Waiting in the delay
+		{0.15, 0.5},   // First tween done
+		{0.15, 0.875}, // Halfway through the second cycle
+		{0.05, 1},
+	}
+
+	for i, step := range steps {
+		seq.Update(step.dt)
+		if progress := seq.GetProgress(); math.Abs(progress-step.expected) > 1e-6 {
+			t.Errorf("step %d: expected progress %v, got %v", i, step.expected, progress)
+		}
+	}
+}
+
+func TestParallelTweenProgress(t *testing.T) {
+	par := NewParallelTween(
+		NewTween(0, 1, 100*time.Millisecond, Linear),
+		NewTween(0, 1, 200*time.Millisecond, Linear),
+	)
+
+	par.Update(0.1)
+	if progress := par.GetProgress(); math.Abs(progress-0.5) > 1e-9 {
+		t.Errorf("expected minimum progress 0.5, got %v", progress)
+	}
+
+	par.SetProgressMode(ProgressAverage)
+	if progress := par.GetProgress(); math.Abs(progress-0.75) > 1e-9 {
+		t.Errorf("expected average progress 0.75, got %v", progress)
+	}
+
+	// Delays and loops count towards each tween's progress
+	looped := NewTween(0, 1, 100*time.Millisecond, Linear).SetLoop(3)
+	delayed := NewTween(0, 1, 100*time.Millisecond, Linear).SetDelay(100 * time.Millisecond)
+	par = NewParallelTween(looped, delayed)
+
+	par.Update(0.05)
+	if progress := par.GetProgress(); math.Abs(progress-1.0/6) > 1e-9 {
+		t.Errorf("expected the looping tween at 1/6, got %v", progress)
+	}
+
+	par.SetProgressMode(ProgressAverage)
+	if progress := par.GetProgress(); math.Abs(progress-(1.0/6+0.25)/2) > 1e-9 {
+		t.Errorf("expected average progress %v, got %v", (1.0/6+0.25)/2, progress)
+	}
+}
+
+func TestSpringAnimation(t *testing.T) {
+	completions := 0
+	spring := NewSpringAnimation(0, 100, 0, 0, 0).SetOnComplete(func() { completions++ })
+
+	var u Updater = spring
+	settled := false
+	for i := 0; i < 600 && !settled; i++ {
+		settled = u.Update(1.0 / 60)
+	}
+
+	if !settled || spring.GetValue() != 100 || spring.GetVelocity() != 0 {
+		t.Fatalf("expected spring settled at 100, got %v moving at %v", spring.GetValue(), spring.GetVelocity())
+	}
+	if completions != 1 {
+		t.Errorf("expected 1 completion, got %d", completions)
+	}
+
+	// A new target sets a settled spring moving again
+	spring.SetTarget(50)
+	if spring.IsComplete() {
+		t.Fatal("spring should move toward the new target")
+	}
+	spring.Update(1.0 / 60)
+	if spring.GetValue() >= 100 || spring.GetVelocity() >= 0 {
+		t.Errorf("expected spring heading down, got %v at %v", spring.GetValue(), spring.GetVelocity())
+	}
+}
+
+func TestSpringRetargetMidFlight(t *testing.T) {
+	spring := NewSpringAnimation(0, 100, 300, 10, 1)
+	for i := 0; i < 10; i++ {
+		spring.Update(1.0 / 60)
+	}
+
+	velocity := spring.GetVelocity()
+	spring.SetTarget(-100)
+	if spring.GetVelocity() != velocity {
+		t.Error("retargeting should keep the current velocity")
+	}
+
+	for i := 0; i < 1200 && !spring.Update(1.0/60); i++ {
+	}
+	if spring.GetValue() != -100 {
+		t.Errorf("expected spring settled at -100, got %v", spring.GetValue())
+	}
+}
+
 func TestAnimator(t *testing.T) {
 	animator := NewAnimator(60)
 
@@ -188,6 +444,180 @@ func TestAnimator(t *testing.T) {
 	animator.Stop()
 }
 
+func TestAnimatorBindFrameBuffer(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	fb := graphics.NewFrameBuffer(dev)
+
+	animator := NewAnimator(60)
+	animator.BindFrameBuffer(fb)
+	animator.AddAnimation(func(frame int, dt float64) bool {
+		fb.SetPixel(frame, 0, 0x0F)
+		return false
+	})
+
+	// Step frames instead of waiting on the ticker
+	animator.Pause()
+	animator.Step()
+
+	if pixel, _ := dev.GetPixel(0, 0); pixel != 0x0F {
+		t.Fatal("frame should be flushed to the device without an explicit Flush")
+	}
+
+	animator.SetAutoClear(0x00)
+	animator.Step()
+
+	if pixel, _ := dev.GetPixel(0, 0); pixel != 0 {
+		t.Error("auto clear should erase the previous frame")
+	}
+	if pixel, _ := dev.GetPixel(1, 0); pixel != 0x0F {
+		t.Error("expected the second frame on the device")
+	}
+	if err := animator.LastFlushError(); err != nil {
+		t.Errorf("unexpected flush error: %v", err)
+	}
+}
+
+func TestAnimatorPauseStep(t *testing.T) {
+	animator := NewAnimator(50)
+
+	var frames []int
+	var dts []float64
+	animator.AddAnimation(func(frame int, dt float64) bool {
+		frames = append(frames, frame)
+		dts = append(dts, dt)
+		return false
+	})
+
+	animator.Step()
+	if len(frames) != 0 {
+		t.Error("Step should do nothing unless paused")
+	}
+
+	animator.Pause()
+	if !animator.IsPaused() {
+		t.Fatal("expected animator to be paused")
+	}
+	animator.Step()
+	animator.Step()
+
+	if len(frames) != 2 || frames[1] != 1 || animator.GetFrameCount() != 2 {
+		t.Fatalf("expected two stepped frames, got %v", frames)
+	}
+	if math.Abs(dts[0]-0.02) > 1e-9 {
+		t.Errorf("expected step dt of one frame (0.02), got %v", dts[0])
+	}
+
+	// A running but paused animator does not advance on its own
+	animator.Start()
+	time.Sleep(60 * time.Millisecond)
+	if animator.GetFrameCount() != 0 {
+		t.Errorf("paused animator advanced to frame %d", animator.GetFrameCount())
+	}
+
+	animator.Resume()
+	if animator.IsPaused() {
+		t.Error("expected animator to resume")
+	}
+	time.Sleep(60 * time.Millisecond)
+	animator.Stop()
+	if animator.GetFrameCount() == 0 {
+		t.Error("resumed animator should advance")
+	}
+}
+
+func TestAnimatorStartStopNoLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	animator := NewAnimator(1000)
+	animator.AddAnimation(func(frame int, dt float64) bool { return false })
+	for i := 0; i < 200; i++ {
+		animator.Start()
+		if i%3 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		animator.Stop()
+	}
+
+	if animator.IsRunning() {
+		t.Error("animator should be stopped")
+	}
+
+	// Stop waits for the loop to exit, so nothing should be left behind
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines leaked: %d before, %d after", before, after)
+	}
+}
+
+func TestAnimatorFixedTimestep(t *testing.T) {
+	animator := NewAnimator(60)
+	animator.SetFixedTimestep(0.02)
+
+	var dts []float64
+	animator.AddAnimation(func(frame int, dt float64) bool {
+		dts = append(dts, dt)
+		return false
+	})
+
+	// Simulate ticks by backdating the previous frame time
+	animator.running = true
+	animator.lastTime = time.Now().Add(-50 * time.Millisecond)
+	animator.update()
+
+	if len(dts) != 2 || dts[0] != 0.02 || dts[1] != 0.02 {
+		t.Fatalf("expected two fixed steps for a 50ms hitch, got %v", dts)
+	}
+
+	// The 10ms remainder carries into the next tick
+	animator.lastTime = time.Now().Add(-15 * time.Millisecond)
+	animator.update()
+	if len(dts) != 3 {
+		t.Errorf("expected the carried remainder to complete a step, got %d steps", len(dts))
+	}
+
+	// A long stall is capped instead of replaying every missed step
+	animator.lastTime = time.Now().Add(-time.Second)
+	animator.update()
+	if len(dts) != 3+maxFixedSteps {
+		t.Errorf("expected %d steps after a stall, got %d", 3+maxFixedSteps, len(dts))
+	}
+}
+
+func TestAnimatorOnAllComplete(t *testing.T) {
+	animator := NewAnimator(60)
+	animator.Pause()
+
+	calls := 0
+	animator.SetOnAllComplete(func() {
+		calls++
+		if calls == 1 {
+			// Chaining the next scene from the callback re-arms it
+			animator.AddUpdater(NewTween(0, 1, 40*time.Millisecond, Linear))
+		}
+	})
+
+	animator.AddUpdater(NewTween(0, 1, 20*time.Millisecond, Linear))
+	animator.AddUpdater(NewTween(0, 1, 40*time.Millisecond, Linear))
+
+	animator.Step()
+	animator.Step()
+	if calls != 0 {
+		t.Fatal("callback should wait for the last animation")
+	}
+
+	animator.Step()
+	if calls != 1 || animator.GetAnimationCount() != 1 {
+		t.Fatalf("expected one call and a chained animation, got %d calls and %d animations", calls, animator.GetAnimationCount())
+	}
+
+	// Idle frames do not fire again
+	for i := 0; i < 6; i++ {
+		animator.Step()
+	}
+	if calls != 2 {
+		t.Errorf("expected the chained animation to fire the callback once more, got %d calls", calls)
+	}
+}
+
 func TestAnimatorRemovesComplete(t *testing.T) {
 	animator := NewAnimator(60)
 
@@ -3,14 +3,25 @@ package animation
 import (
 	"sync"
 	"time"
+
+	"github.com/flavioheleno/oled-emulator/graphics"
 )
 
+// maxFixedSteps limits the fixed steps run per tick, so a long stall cannot snowball into ever longer frames
+const maxFixedSteps = 8
+
 // AnimationFunc defines a callback function for animations
 // frame: current frame number
 // dt: delta time in seconds since last frame
 // Returns true when animation is complete
 type AnimationFunc func(frame int, dt float64) bool
 
+// Updater is implemented by tweens and springs that advance by delta time
+// Update returns true when the animation is complete
+type Updater interface {
+	Update(dt float64) bool
+}
+
 // Animator manages frame-based animations
 type Animator struct {
 	mu         sync.Mutex
@@ -18,11 +29,21 @@ type Animator struct {
 	targetDt   float64
 	ticker     *time.Ticker
 	running    bool
+	paused     bool
+	fixedDt    float64 // Fixed step in seconds, 0 passes the measured dt
+	accum      float64 // Elapsed time not yet consumed by fixed steps
 	frameCount int
 	animations []AnimationFunc
 	lastTime   time.Time
-	stopChan   chan struct{}
+	done       chan struct{} // Closed by Stop to end the loop of the current Start
+	loopExited chan struct{} // Closed by the loop when it returns
 	onFrame    func(frame int, dt float64)
+	onAllDone  func()
+	allDone    bool                  // Set when the last animation completes, cleared once onAllDone is called
+	fb         *graphics.FrameBuffer // Flushed after every frame when bound
+	autoClear  bool
+	clearColor byte
+	flushErr   error
 }
 
 // NewAnimator creates a new animator with the specified FPS
@@ -35,7 +56,6 @@ func NewAnimator(fps int) *Animator {
 		fps:      fps,
 		targetDt: 1.0 / float64(fps),
 		running:  false,
-		stopChan: make(chan struct{}),
 	}
 }
 
@@ -52,6 +72,21 @@ func (a *Animator) SetFrameRate(fps int) {
 	a.targetDt = 1.0 / float64(fps)
 }
 
+// SetFixedTimestep makes every frame advance animations by exactly dt seconds
+// Real elapsed time accumulates and runs as many whole steps as fit, carrying the remainder
+// Passing 0 restores the default of passing the measured time between ticks
+func (a *Animator) SetFixedTimestep(dt float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if dt < 0 {
+		dt = 0
+	}
+
+	a.fixedDt = dt
+	a.accum = 0
+}
+
 // AddAnimation adds an animation function
 func (a *Animator) AddAnimation(fn AnimationFunc) {
 	a.mu.Lock()
@@ -60,6 +95,22 @@ func (a *Animator) AddAnimation(fn AnimationFunc) {
 	a.animations = append(a.animations, fn)
 }
 
+// AddUpdater adds a tween, spring or other Updater as an animation
+func (a *Animator) AddUpdater(u Updater) {
+	a.AddAnimation(func(frame int, dt float64) bool {
+		return u.Update(dt)
+	})
+}
+
+// SetOnAllComplete sets a callback called when the last active animation completes
+// It fires again whenever animations are added and later all complete
+func (a *Animator) SetOnAllComplete(fn func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.onAllDone = fn
+}
+
 // SetOnFrame sets a callback called every frame
 func (a *Animator) SetOnFrame(fn func(frame int, dt float64)) {
 	a.mu.Lock()
@@ -68,6 +119,40 @@ func (a *Animator) SetOnFrame(fn func(frame int, dt float64)) {
 	a.onFrame = fn
 }
 
+// BindFrameBuffer flushes fb to its device after the animations of every frame
+// Passing nil unbinds it
+func (a *Animator) BindFrameBuffer(fb *graphics.FrameBuffer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.fb = fb
+}
+
+// SetAutoClear clears the bound framebuffer to color before each frame's animations run
+func (a *Animator) SetAutoClear(color byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.autoClear = true
+	a.clearColor = color
+}
+
+// DisableAutoClear stops clearing the bound framebuffer between frames
+func (a *Animator) DisableAutoClear() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.autoClear = false
+}
+
+// LastFlushError returns the error of the most recent clear or flush of the bound framebuffer
+func (a *Animator) LastFlushError() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.flushErr
+}
+
 // Start begins animation
 func (a *Animator) Start() {
 	a.mu.Lock()
@@ -80,13 +165,17 @@ func (a *Animator) Start() {
 	a.frameCount = 0
 	a.lastTime = time.Now()
 	a.ticker = time.NewTicker(time.Duration(float64(time.Second) / float64(a.fps)))
+	a.done = make(chan struct{})
+	a.loopExited = make(chan struct{})
+	ticker, done, exited := a.ticker, a.done, a.loopExited
 	a.mu.Unlock()
 
 	// Run animation loop in goroutine
-	go a.loop()
+	go a.loop(ticker, done, exited)
 }
 
-// Stop halts animation
+// Stop halts animation and waits for the animation goroutine to exit
+// It must not be called from an animation or frame callback
 func (a *Animator) Stop() {
 	a.mu.Lock()
 	if !a.running {
@@ -95,35 +184,25 @@ func (a *Animator) Stop() {
 	}
 
 	a.running = false
-	if a.ticker != nil {
-		a.ticker.Stop()
-		a.ticker = nil
-	}
+	a.ticker.Stop()
+	a.ticker = nil
+	close(a.done)
+	exited := a.loopExited
 	a.mu.Unlock()
 
-	// Signal the loop to stop
-	select {
-	case a.stopChan <- struct{}{}:
-	default:
-	}
+	<-exited
 }
 
-// loop runs the animation update loop
-func (a *Animator) loop() {
-	for {
-		a.mu.Lock()
-		if a.ticker == nil {
-			a.mu.Unlock()
-			return
-		}
-		ticker := a.ticker
-		a.mu.Unlock()
+// loop runs the animation update loop until done is closed
+func (a *Animator) loop(ticker *time.Ticker, done <-chan struct{}, exited chan<- struct{}) {
+	defer close(exited)
 
+	for {
 		select {
 		case <-ticker.C:
 			a.update()
 
-		case <-a.stopChan:
+		case <-done:
 			return
 		}
 	}
@@ -131,10 +210,16 @@ func (a *Animator) loop() {
 
 // update processes animations for the current frame
 func (a *Animator) update() {
+	a.tick()
+	a.notifyAllComplete()
+}
+
+// tick advances the frames due since the last tick
+func (a *Animator) tick() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	if !a.running {
+	if !a.running || a.paused {
 		return
 	}
 
@@ -142,6 +227,32 @@ func (a *Animator) update() {
 	dt := now.Sub(a.lastTime).Seconds()
 	a.lastTime = now
 
+	if a.fixedDt <= 0 {
+		a.advance(dt)
+		return
+	}
+
+	a.accum += dt
+	for steps := 0; a.accum >= a.fixedDt; steps++ {
+		if steps == maxFixedSteps {
+			// Drop the backlog rather than falling further behind
+			a.accum = 0
+			break
+		}
+
+		a.advance(a.fixedDt)
+		a.accum -= a.fixedDt
+	}
+}
+
+// advance runs one frame with the given delta time
+// The caller must hold a.mu
+func (a *Animator) advance(dt float64) {
+	var frameErr error
+	if a.fb != nil && a.autoClear {
+		frameErr = a.fb.Clear(a.clearColor)
+	}
+
 	// Call onFrame callback if set
 	if a.onFrame != nil {
 		a.onFrame(a.frameCount, dt)
@@ -161,11 +272,75 @@ func (a *Animator) update() {
 	}
 
 	// Remove completed animations
+	if len(a.animations) > 0 && len(activeAnimations) == 0 {
+		a.allDone = true
+	}
 	a.animations = activeAnimations
 
+	if a.fb != nil {
+		if err := a.fb.Flush(); frameErr == nil {
+			frameErr = err
+		}
+		a.flushErr = frameErr
+	}
+
 	a.frameCount++
 }
 
+// notifyAllComplete calls the all-complete callback outside the lock, so it may add animations
+func (a *Animator) notifyAllComplete() {
+	a.mu.Lock()
+	fire, fn := a.allDone, a.onAllDone
+	a.allDone = false
+	a.mu.Unlock()
+
+	if fire && fn != nil {
+		fn()
+	}
+}
+
+// Pause stops frames from advancing while the animator keeps running
+func (a *Animator) Pause() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.paused = true
+}
+
+// Resume continues advancing frames after Pause
+// The time spent paused is not passed to animations
+func (a *Animator) Resume() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.paused = false
+	a.lastTime = time.Now()
+}
+
+// IsPaused returns whether frames are paused
+func (a *Animator) IsPaused() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.paused
+}
+
+// Step advances exactly one frame of the target frame duration, or the fixed timestep, while paused
+// It works without Start, for stepping animations in tests, and does nothing when not paused
+func (a *Animator) Step() {
+	a.mu.Lock()
+	if a.paused {
+		dt := a.targetDt
+		if a.fixedDt > 0 {
+			dt = a.fixedDt
+		}
+		a.advance(dt)
+	}
+	a.mu.Unlock()
+
+	a.notifyAllComplete()
+}
+
 // IsRunning returns whether animations are currently running
 func (a *Animator) IsRunning() bool {
 	a.mu.Lock()
@@ -122,7 +122,7 @@ func EaseInSine(t float64) float64 {
 // EaseOutSine decelerating to zero velocity (sinusoidal)
 func EaseOutSine(t float64) float64 {
 	t = clamp(t)
-	return math.Sin(math.Pi*t) / 2
+	return math.Sin(math.Pi * t / 2)
 }
 
 // EaseInOutSine acceleration until halfway, then deceleration (sinusoidal)
@@ -0,0 +1,140 @@
+package animation
+
+import (
+	"math"
+)
+
+const (
+	DefaultSpringStiffness = 170.0
+	DefaultSpringDamping   = 26.0
+	DefaultSpringMass      = 1.0
+	DefaultSpringThreshold = 0.01
+)
+
+// maxSpringStep is the longest integration step, keeping stiff springs stable at low frame rates
+const maxSpringStep = 1.0 / 240
+
+// SpringAnimation moves a value toward a target like a damped spring
+// It has no fixed duration and settles once it is close to the target and nearly still
+type SpringAnimation struct {
+	value      float64
+	velocity   float64
+	target     float64
+	stiffness  float64
+	damping    float64
+	mass       float64
+	threshold  float64
+	complete   bool
+	onComplete func()
+	onUpdate   func(value float64)
+}
+
+// NewSpringAnimation creates a spring starting at from and pulling toward to
+// Non-positive stiffness, damping or mass use the defaults
+func NewSpringAnimation(from, to, stiffness, damping, mass float64) *SpringAnimation {
+	if stiffness <= 0 {
+		stiffness = DefaultSpringStiffness
+	}
+	if damping <= 0 {
+		damping = DefaultSpringDamping
+	}
+	if mass <= 0 {
+		mass = DefaultSpringMass
+	}
+
+	return &SpringAnimation{
+		value:     from,
+		target:    to,
+		stiffness: stiffness,
+		damping:   damping,
+		mass:      mass,
+		threshold: DefaultSpringThreshold,
+	}
+}
+
+// SetOnComplete sets a callback when the spring settles
+func (sa *SpringAnimation) SetOnComplete(fn func()) *SpringAnimation {
+	sa.onComplete = fn
+	return sa
+}
+
+// SetOnUpdate sets a callback called each frame with the current value
+func (sa *SpringAnimation) SetOnUpdate(fn func(value float64)) *SpringAnimation {
+	sa.onUpdate = fn
+	return sa
+}
+
+// SetThreshold sets how close to the target and how slow the spring must be to settle
+func (sa *SpringAnimation) SetThreshold(threshold float64) *SpringAnimation {
+	sa.threshold = threshold
+	return sa
+}
+
+// SetTarget changes the destination, keeping the current value and velocity
+// A settled spring starts moving again
+func (sa *SpringAnimation) SetTarget(value float64) {
+	sa.target = value
+	sa.complete = sa.settled()
+}
+
+// GetTarget returns the destination value
+func (sa *SpringAnimation) GetTarget() float64 {
+	return sa.target
+}
+
+// GetValue returns the current value
+func (sa *SpringAnimation) GetValue() float64 {
+	return sa.value
+}
+
+// GetVelocity returns the current velocity in units per second
+func (sa *SpringAnimation) GetVelocity() float64 {
+	return sa.velocity
+}
+
+// IsComplete returns whether the spring has settled at its target
+func (sa *SpringAnimation) IsComplete() bool {
+	return sa.complete
+}
+
+// Update advances the spring by dt seconds
+// Returns true once the spring has settled
+func (sa *SpringAnimation) Update(dt float64) bool {
+	if sa.complete {
+		return true
+	}
+
+	for dt > 0 {
+		step := math.Min(dt, maxSpringStep)
+		dt -= step
+
+		// Semi-implicit Euler: update velocity first, then position with the new velocity
+		force := -sa.stiffness*(sa.value-sa.target) - sa.damping*sa.velocity
+		sa.velocity += force / sa.mass * step
+		sa.value += sa.velocity * step
+	}
+
+	if sa.settled() {
+		sa.value = sa.target
+		sa.velocity = 0
+		sa.complete = true
+	}
+
+	if sa.onUpdate != nil {
+		sa.onUpdate(sa.value)
+	}
+
+	if sa.complete {
+		if sa.onComplete != nil {
+			sa.onComplete()
+		}
+		return true
+	}
+
+	return false
+}
+
+// settled reports whether the spring is within the threshold of rest at its target
+func (sa *SpringAnimation) settled() bool {
+	return math.Abs(sa.value-sa.target) < sa.threshold && math.Abs(sa.velocity) < sa.threshold
+}
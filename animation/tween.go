@@ -1,6 +1,7 @@
 package animation
 
 import (
+	"math"
 	"time"
 )
 
@@ -11,8 +12,14 @@ type Tween struct {
 	duration   time.Duration
 	elapsed    time.Duration
 	easing     EasingFunc
+	loops      int  // Number of cycles to run, 0 repeats forever
+	cycle      int  // Cycles completed so far
+	yoyo       bool // Every other cycle runs from to back to from
+	delay      time.Duration
+	waited     time.Duration // Time spent in the start delay
 	onComplete func()
 	onUpdate   func(value float64)
+	onLoop     func(cycle int)
 }
 
 // NewTween creates a new tween animation
@@ -27,10 +34,17 @@ func NewTween(from, to float64, duration time.Duration, easing EasingFunc) *Twee
 		duration: duration,
 		elapsed:  0,
 		easing:   easing,
+		loops:    1,
 	}
 }
 
+// NewDelayTween creates a tween that only waits for d, for spacing steps of a SequenceTween
+func NewDelayTween(d time.Duration) *Tween {
+	return NewTween(0, 0, d, Linear)
+}
+
 // SetOnComplete sets a callback when the tween completes
+// With looping it fires once, after the last cycle
 func (t *Tween) SetOnComplete(fn func()) *Tween {
 	t.onComplete = fn
 	return t
@@ -42,6 +56,35 @@ func (t *Tween) SetOnUpdate(fn func(value float64)) *Tween {
 	return t
 }
 
+// SetOnLoop sets a callback called each time a cycle ends and another starts
+// It receives the number of cycles completed so far
+func (t *Tween) SetOnLoop(fn func(cycle int)) *Tween {
+	t.onLoop = fn
+	return t
+}
+
+// SetLoop sets how many cycles the tween runs, 0 repeats forever
+func (t *Tween) SetLoop(count int) *Tween {
+	if count < 0 {
+		count = 0
+	}
+
+	t.loops = count
+	return t
+}
+
+// SetDelay holds the tween at its from value for d before it starts animating
+func (t *Tween) SetDelay(d time.Duration) *Tween {
+	t.delay = d
+	return t
+}
+
+// SetYoyo makes every other cycle run backwards, bouncing between the two values
+func (t *Tween) SetYoyo(yoyo bool) *Tween {
+	t.yoyo = yoyo
+	return t
+}
+
 // GetValue returns the current interpolated value
 func (t *Tween) GetValue() float64 {
 	if t.duration == 0 {
@@ -53,16 +96,33 @@ func (t *Tween) GetValue() float64 {
 		normalizedTime = 1
 	}
 
+	// The finished tween holds the end of its last cycle
+	cycle := t.cycle
+	if t.loops > 0 && cycle >= t.loops {
+		cycle = t.loops - 1
+	}
+	if t.yoyo && cycle%2 == 1 {
+		normalizedTime = 1 - normalizedTime
+	}
+
 	easedTime := t.easing(normalizedTime)
 	return t.from + (t.to-t.from)*easedTime
 }
 
-// IsComplete returns whether the tween has finished
+// IsComplete returns whether the tween has finished all of its cycles
+// A zero duration tween is complete once its delay has passed
 func (t *Tween) IsComplete() bool {
-	return t.elapsed >= t.duration
+	if t.waited < t.delay {
+		return false
+	}
+	if t.duration == 0 {
+		return true
+	}
+
+	return t.loops > 0 && t.cycle >= t.loops
 }
 
-// GetProgress returns the progress (0 to 1)
+// GetProgress returns the progress of the current cycle (0 to 1)
 func (t *Tween) GetProgress() float64 {
 	if t.duration == 0 {
 		return 1
@@ -75,16 +135,51 @@ func (t *Tween) GetProgress() float64 {
 	return progress
 }
 
+// timeline returns the total running time of the tween, including its delay and every cycle, and how much of it has passed
+// A tween that repeats forever counts as its delay plus one cycle, with the current cycle's time passed
+func (t *Tween) timeline() (total, done time.Duration) {
+	if t.IsComplete() {
+		total = t.delay + t.duration*time.Duration(max(t.loops, 1))
+		return total, total
+	}
+
+	if t.loops == 0 {
+		return t.delay + t.duration, t.waited + t.elapsed
+	}
+
+	return t.delay + t.duration*time.Duration(t.loops), t.waited + t.duration*time.Duration(t.cycle) + t.elapsed
+}
+
 // Update updates the tween with delta time
+// Returns true once all cycles are done
 func (t *Tween) Update(dt float64) bool {
 	if t.IsComplete() {
 		return true
 	}
 
-	t.elapsed += time.Duration(dt * float64(time.Second))
+	step := time.Duration(dt * float64(time.Second))
 
-	if t.elapsed > t.duration {
-		t.elapsed = t.duration
+	// Time spent in the start delay does not advance the animation
+	if t.waited < t.delay {
+		wait := min(step, t.delay-t.waited)
+		t.waited += wait
+		step -= wait
+	}
+
+	t.elapsed += step
+
+	for t.duration > 0 && t.elapsed >= t.duration {
+		t.cycle++
+		if t.loops > 0 && t.cycle >= t.loops {
+			t.elapsed = t.duration
+			break
+		}
+
+		// Carry the overshoot into the next cycle
+		t.elapsed -= t.duration
+		if t.onLoop != nil {
+			t.onLoop(t.cycle)
+		}
 	}
 
 	value := t.GetValue()
@@ -119,6 +214,7 @@ type ColorTween struct {
 	duration   time.Duration
 	elapsed    time.Duration
 	easing     EasingFunc
+	linear     bool // Interpolate in linear light instead of sRGB
 	onComplete func()
 	onUpdate   func(r, g, b byte)
 }
@@ -154,6 +250,13 @@ func (ct *ColorTween) SetOnUpdate(fn func(r, g, b byte)) *ColorTween {
 	return ct
 }
 
+// SetLinearLight makes the tween interpolate in linear light
+// Gamma-correct blending keeps midpoints from looking darker than either end
+func (ct *ColorTween) SetLinearLight(linear bool) *ColorTween {
+	ct.linear = linear
+	return ct
+}
+
 // GetColor returns the current interpolated color
 func (ct *ColorTween) GetColor() (byte, byte, byte) {
 	if ct.duration == 0 {
@@ -167,6 +270,12 @@ func (ct *ColorTween) GetColor() (byte, byte, byte) {
 
 	easedTime := ct.easing(normalizedTime)
 
+	if ct.linear {
+		return lerpLinearLight(ct.fromR, ct.toR, easedTime),
+			lerpLinearLight(ct.fromG, ct.toG, easedTime),
+			lerpLinearLight(ct.fromB, ct.toB, easedTime)
+	}
+
 	r := byte(float64(ct.fromR) + (float64(ct.toR)-float64(ct.fromR))*easedTime)
 	g := byte(float64(ct.fromG) + (float64(ct.toG)-float64(ct.fromG))*easedTime)
 	b := byte(float64(ct.fromB) + (float64(ct.toB)-float64(ct.fromB))*easedTime)
@@ -174,6 +283,33 @@ func (ct *ColorTween) GetColor() (byte, byte, byte) {
 	return r, g, b
 }
 
+// lerpLinearLight interpolates two sRGB channel values in linear light
+func lerpLinearLight(from, to byte, t float64) byte {
+	a := srgbToLinear(from)
+	b := srgbToLinear(to)
+	return linearToSRGB(a + (b-a)*t)
+}
+
+// srgbToLinear converts an sRGB channel value to linear light (0 to 1)
+func srgbToLinear(v byte) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB converts linear light (0 to 1) to an sRGB channel value
+func linearToSRGB(c float64) byte {
+	c = math.Max(0, math.Min(1, c))
+	if c <= 0.0031308 {
+		c *= 12.92
+	} else {
+		c = 1.055*math.Pow(c, 1/2.4) - 0.055
+	}
+	return byte(math.Round(c * 255))
+}
+
 // IsComplete returns whether the tween has finished
 func (ct *ColorTween) IsComplete() bool {
 	return ct.elapsed >= ct.duration
@@ -206,6 +342,58 @@ func (ct *ColorTween) Update(dt float64) bool {
 	return false
 }
 
+// GrayTween tweens a 4-bit gray level, such as the brightness of an SSD1322 element
+type GrayTween struct {
+	tween    *Tween
+	onUpdate func(level byte)
+}
+
+// NewGrayTween creates a tween between two 4-bit gray levels
+func NewGrayTween(from, to byte, duration time.Duration, easing EasingFunc) *GrayTween {
+	gt := &GrayTween{
+		tween: NewTween(float64(from&0x0F), float64(to&0x0F), duration, easing),
+	}
+	gt.tween.SetOnUpdate(func(float64) {
+		if gt.onUpdate != nil {
+			gt.onUpdate(gt.GetLevel())
+		}
+	})
+
+	return gt
+}
+
+// SetOnComplete sets a callback when the tween completes
+func (gt *GrayTween) SetOnComplete(fn func()) *GrayTween {
+	gt.tween.SetOnComplete(fn)
+	return gt
+}
+
+// SetOnUpdate sets a callback called each frame with the current level
+func (gt *GrayTween) SetOnUpdate(fn func(level byte)) *GrayTween {
+	gt.onUpdate = fn
+	return gt
+}
+
+// GetLevel returns the current level rounded to the nearest 4-bit value
+func (gt *GrayTween) GetLevel() byte {
+	return byte(math.Round(gt.tween.GetValue()))
+}
+
+// IsComplete returns whether the tween has finished
+func (gt *GrayTween) IsComplete() bool {
+	return gt.tween.IsComplete()
+}
+
+// GetProgress returns the progress (0 to 1)
+func (gt *GrayTween) GetProgress() float64 {
+	return gt.tween.GetProgress()
+}
+
+// Update updates the tween with delta time
+func (gt *GrayTween) Update(dt float64) bool {
+	return gt.tween.Update(dt)
+}
+
 // SequenceTween chains multiple tweens together
 type SequenceTween struct {
 	tweens       []*Tween
@@ -256,10 +444,40 @@ func (st *SequenceTween) IsComplete() bool {
 	return st.currentIndex >= len(st.tweens)
 }
 
+// GetProgress returns the progress of the whole chain (0 to 1)
+// Each tween counts in proportion to its delay plus all of its cycles; zero-length chains count each tween equally
+func (st *SequenceTween) GetProgress() float64 {
+	if len(st.tweens) == 0 {
+		return 1
+	}
+
+	var total, done time.Duration
+	for _, tween := range st.tweens {
+		tweenTotal, tweenDone := tween.timeline()
+		total += tweenTotal
+		done += tweenDone
+	}
+
+	if total == 0 {
+		return float64(st.currentIndex) / float64(len(st.tweens))
+	}
+
+	return float64(done) / float64(total)
+}
+
+// ProgressMode selects how ParallelTween combines the progress of its tweens
+type ProgressMode int
+
+const (
+	ProgressMin     ProgressMode = iota // Progress of the slowest tween
+	ProgressAverage                     // Mean progress of all tweens
+)
+
 // ParallelTween runs multiple tweens in parallel
 type ParallelTween struct {
-	tweens     []*Tween
-	onComplete func()
+	tweens       []*Tween
+	progressMode ProgressMode
+	onComplete   func()
 }
 
 // NewParallelTween creates a new parallel tween
@@ -293,6 +511,37 @@ func (pt *ParallelTween) Update(dt float64) bool {
 	return allComplete
 }
 
+// SetProgressMode selects how GetProgress combines the tweens
+func (pt *ParallelTween) SetProgressMode(mode ProgressMode) *ParallelTween {
+	pt.progressMode = mode
+	return pt
+}
+
+// GetProgress returns the combined progress of all tweens (0 to 1)
+// By default this is the minimum, so it reaches 1 only when every tween is done
+func (pt *ParallelTween) GetProgress() float64 {
+	if len(pt.tweens) == 0 {
+		return 1
+	}
+
+	// Each tween counts its delay and all of its cycles, like in SequenceTween
+	minimum, sum := 1.0, 0.0
+	for _, tween := range pt.tweens {
+		progress := 1.0
+		if total, done := tween.timeline(); total > 0 {
+			progress = float64(done) / float64(total)
+		}
+		minimum = math.Min(minimum, progress)
+		sum += progress
+	}
+
+	if pt.progressMode == ProgressAverage {
+		return sum / float64(len(pt.tweens))
+	}
+
+	return minimum
+}
+
 // IsComplete returns whether all tweens have finished
 func (pt *ParallelTween) IsComplete() bool {
 	for _, tween := range pt.tweens {
@@ -12,6 +12,8 @@ const (
 	VerticalByte
 	// RGB888: 24-bit RGB color
 	RGB888
+	// EightBitGray: 1 byte per pixel, full 0-255 gray
+	EightBitGray
 )
 
 // Config holds device configuration
@@ -21,7 +23,10 @@ type Config struct {
 	ColorDepth   int         // Bits per pixel: 1, 4, 8, 24
 	PixelFormat  PixelFormat // How pixels are packed in memory
 	ColumnOffset int         // Offset for VRAM column (e.g., 28 for SSD1322)
-	InitCommands []byte      // Custom initialization sequence
+	// Internal columns per row in nibble-packed VRAM (e.g., 480 for SSD1322)
+	// Zero derives it from Width + ColumnOffset
+	InternalColumns int
+	InitCommands    []byte // Custom initialization sequence
 }
 
 // Device defines the interface for display emulation
@@ -55,6 +60,49 @@ type Device interface {
 	GetPixel(x, y int) (byte, error)
 }
 
+// DeviceState is a snapshot of a controller's settings, for debug overlays and tests
+type DeviceState struct {
+	DisplayOn     bool
+	Contrast      byte
+	MasterCurrent byte // 0-15
+	Inverted      bool
+	AllOn         bool // Entire display forced on
+	CommandLocked bool
+	Scrolling     bool
+	StartLine     int
+	DisplayOffset int
+	Remap         byte // Remap settings byte, controller specific
+
+	// Addressing window and the current address, in controller units (SSD1322 columns are 4 pixels wide)
+	ColumnStart int
+	ColumnEnd   int
+	RowStart    int
+	RowEnd      int
+	Column      int
+	Row         int
+}
+
+// Inspectable is implemented by devices that report their full state
+type Inspectable interface {
+	State() DeviceState
+}
+
+// InvertReporter is implemented by devices supporting inverse display
+type InvertReporter interface {
+	IsInverted() bool
+}
+
+// AllOnReporter is implemented by devices supporting the entire-display-on mode
+type AllOnReporter interface {
+	IsAllOn() bool
+}
+
+// BrightnessReporter is implemented by devices with contrast and master current control
+type BrightnessReporter interface {
+	GetContrastLevel() byte
+	GetMasterCurrent() byte
+}
+
 // BaseDevice provides common functionality for device implementations
 type BaseDevice struct {
 	config   Config
@@ -94,17 +142,19 @@ func (bd *BaseDevice) allocateVRAM() []byte {
 
 	switch bd.config.PixelFormat {
 	case HorizontalNibble:
-		// 2 pixels per byte (4 bits each)
-		// Include column offset for SSD1322 (480 columns internal)
-		columns := 480 // SSD1322 has 480 columns internally
+		// 2 pixels per byte (4 bits each), covering the controller's internal columns
+		columns := internalColumns(bd.config.Width, bd.config.ColumnOffset, bd.config.InternalColumns)
 		rows := bd.config.Height
-		byteCount = (columns * rows) / 2
+		byteCount = nibbleRowBytes(columns) * rows
 	case VerticalByte:
 		// 8 pixels per byte, packed vertically
 		byteCount = bd.config.Width * ((bd.config.Height + 7) / 8)
 	case RGB888:
 		// 24-bit color (3 bytes per pixel)
 		byteCount = bd.config.Width * bd.config.Height * 3
+	case EightBitGray:
+		// 1 byte per pixel
+		byteCount = bd.config.Width * bd.config.Height
 	default:
 		panic("unsupported pixel format")
 	}
@@ -112,6 +162,17 @@ func (bd *BaseDevice) allocateVRAM() []byte {
 	return make([]byte, byteCount)
 }
 
+// internalColumns returns the configured internal column count,
+// or Width + ColumnOffset rounded up to a whole byte of nibbles when none is configured
+func internalColumns(width, colOffset, configured int) int {
+	if configured > 0 {
+		return configured
+	}
+
+	columns := width + colOffset
+	return columns + columns%2
+}
+
 // GetFrameBuffer returns the VRAM
 func (bd *BaseDevice) GetFrameBuffer() []byte {
 	return bd.vram
@@ -192,3 +253,8 @@ func (bd *BaseDevice) ColorDepth() int {
 func (bd *BaseDevice) PixelFormat() PixelFormat {
 	return bd.config.PixelFormat
 }
+
+// ColumnOffset returns the internal column address of display x = 0
+func (bd *BaseDevice) ColumnOffset() int {
+	return bd.config.ColumnOffset
+}
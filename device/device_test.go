@@ -1,6 +1,7 @@
 package device
 
 import (
+	"math"
 	"testing"
 )
 
@@ -59,6 +60,133 @@ func TestNibblePacking(t *testing.T) {
 	}
 }
 
+func TestNibblePackingExhaustive(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+	vram := ssd.GetFrameBuffer()
+	mh := NewMemoryHelper(256, 64, HorizontalNibble, 28)
+	mh.SetInternalColumns(480)
+
+	value := func(x, y int) byte { return byte(x*7+y*3) & 0x0F }
+
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 256; x++ {
+			offset, _, err := mh.PixelToByteOffsetNibble(x, y)
+			if err != nil {
+				t.Fatalf("offset (%d, %d): %v", x, y, err)
+			}
+			if offset >= len(vram) {
+				t.Fatalf("offset (%d, %d) = %d overflows %d bytes", x, y, offset, len(vram))
+			}
+			if err := mh.SetPixelNibble(vram, x, y, value(x, y)); err != nil {
+				t.Fatalf("set (%d, %d): %v", x, y, err)
+			}
+		}
+	}
+
+	// Reading back after all writes catches any pixel that clobbered a neighbor
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 256; x++ {
+			if pixel, _ := mh.GetPixelNibble(vram, x, y); pixel != value(x, y) {
+				t.Fatalf("pixel (%d, %d): expected 0x%X, got 0x%X", x, y, value(x, y), pixel)
+			}
+		}
+	}
+}
+
+func TestNibblePackingOddColumns(t *testing.T) {
+	mh := NewMemoryHelper(4, 3, HorizontalNibble, 1)
+	mh.SetInternalColumns(5)
+	vram := NewBaseDevice(Config{Width: 4, Height: 3, PixelFormat: HorizontalNibble, ColumnOffset: 1, InternalColumns: 5}).GetFrameBuffer()
+
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			if err := mh.SetPixelNibble(vram, x, y, byte(y*4+x+1)); err != nil {
+				t.Fatalf("set (%d, %d): %v", x, y, err)
+			}
+		}
+	}
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			if pixel, _ := mh.GetPixelNibble(vram, x, y); pixel != byte(y*4+x+1) {
+				t.Errorf("pixel (%d, %d): expected 0x%X, got 0x%X", x, y, y*4+x+1, pixel)
+			}
+		}
+	}
+
+	// Pixels past the internal columns are rejected instead of spilling into the next row
+	narrow := NewMemoryHelper(8, 2, HorizontalNibble, 2)
+	narrow.SetInternalColumns(8)
+	if err := narrow.SetPixelNibble(make([]byte, 8), 6, 0, 0x0F); err == nil {
+		t.Error("expected error for column past the internal width")
+	}
+}
+
+func TestInternalColumns(t *testing.T) {
+	// SSD1322 keeps its 480 internal columns
+	ssd := NewSSD1322(256, 64)
+	if len(ssd.GetFrameBuffer()) != 480*64/2 {
+		t.Errorf("expected %d VRAM bytes, got %d", 480*64/2, len(ssd.GetFrameBuffer()))
+	}
+
+	// A narrower nibble panel derives its row width from Width + ColumnOffset
+	config := Config{Width: 128, Height: 32, ColorDepth: 4, PixelFormat: HorizontalNibble, ColumnOffset: 3}
+	bd := NewBaseDevice(config)
+	vram := bd.GetFrameBuffer()
+	if len(vram) != 132*32/2 {
+		t.Fatalf("expected %d VRAM bytes, got %d", 132*32/2, len(vram))
+	}
+
+	mh := NewMemoryHelper(128, 32, HorizontalNibble, 3)
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 128; x++ {
+			if err := mh.SetPixelNibble(vram, x, y, byte(x+y)&0x0F); err != nil {
+				t.Fatalf("failed to set pixel (%d, %d): %v", x, y, err)
+			}
+		}
+	}
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 128; x++ {
+			if pixel, _ := mh.GetPixelNibble(vram, x, y); pixel != byte(x+y)&0x0F {
+				t.Fatalf("pixel (%d, %d): expected 0x%X, got 0x%X", x, y, byte(x+y)&0x0F, pixel)
+			}
+		}
+	}
+
+	// An explicit count overrides the derived one
+	config.InternalColumns = 160
+	if len(NewBaseDevice(config).GetFrameBuffer()) != 160*32/2 {
+		t.Error("expected VRAM sized from InternalColumns")
+	}
+}
+
+func TestEightBitGrayPacking(t *testing.T) {
+	bd := NewBaseDevice(Config{Width: 16, Height: 8, ColorDepth: 8, PixelFormat: EightBitGray})
+	vram := bd.GetFrameBuffer()
+	if len(vram) != 16*8 {
+		t.Fatalf("expected %d VRAM bytes, got %d", 16*8, len(vram))
+	}
+
+	mh := NewMemoryHelper(16, 8, EightBitGray, 0)
+	for i, value := range []byte{0x00, 0x01, 0x7F, 0x80, 0xFE, 0xFF} {
+		x, y := i*3, i%8
+		if err := mh.SetPixel8(vram, x, y, value); err != nil {
+			t.Fatalf("failed to set pixel: %v", err)
+		}
+
+		pixel, err := mh.GetPixel8(vram, x, y)
+		if err != nil {
+			t.Fatalf("failed to get pixel: %v", err)
+		}
+		if pixel != value {
+			t.Errorf("pixel (%d, %d): expected 0x%02X, got 0x%02X", x, y, value, pixel)
+		}
+	}
+
+	if err := mh.SetPixel8(vram, 16, 0, 0xFF); err == nil {
+		t.Error("expected error for out of bounds pixel")
+	}
+}
+
 func TestDirtyTracking(t *testing.T) {
 	config := Config{
 		Width:        256,
@@ -212,3 +340,709 @@ func TestSSD1322Reset(t *testing.T) {
 		t.Errorf("contrast should be 0x7F after reset, got 0x%02X", ssd.GetContrastLevel())
 	}
 }
+
+func TestSSD1322VerticalAddressIncrement(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	ssd.ProcessCommand(CmdSetRemap, []byte{0x14 | RemapVerticalIncrement})
+	ssd.ProcessCommand(CmdSetColumnAddress, []byte{0x1C, 0x1F})
+	ssd.ProcessCommand(CmdSetRowAddress, []byte{0, 3})
+	ssd.ProcessCommand(CmdWriteRAM, nil)
+
+	// Two bytes per row should fill the first 4-pixel column top to bottom
+	if err := ssd.WriteData([]byte{0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11}); err != nil {
+		t.Fatalf("write data failed: %v", err)
+	}
+
+	for row := 0; row < 4; row++ {
+		pixel, _ := ssd.GetPixel(3, row)
+		if pixel != 0x01 {
+			t.Errorf("expected pixel (3, %d) to be 0x01, got 0x%02X", row, pixel)
+		}
+	}
+
+	if pixel, _ := ssd.GetPixel(4, 0); pixel != 0 {
+		t.Errorf("next column should not be written yet, got 0x%02X", pixel)
+	}
+}
+
+func TestSSD1322HorizontalScroll(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	ssd.SetPixel(0, 0, 0x0F)
+	ssd.SetPixel(255, 1, 0x0F)
+	ssd.SetPixel(0, 10, 0x0F)
+
+	// Scroll rows 0-1 by 2 columns, 8 pixels, per step
+	ssd.ProcessCommand(CmdHorizontalScroll, []byte{0x00, 0x00, 0x00, 0x01, 0x02})
+	ssd.ProcessCommand(CmdActivateScroll, nil)
+	ssd.StepScroll()
+
+	if pixel, _ := ssd.GetPixel(8, 0); pixel != 0x0F {
+		t.Errorf("expected pixel shifted to (8, 0), got 0x%02X", pixel)
+	}
+	if pixel, _ := ssd.GetPixel(7, 1); pixel != 0x0F {
+		t.Errorf("expected pixel wrapped to (7, 1), got 0x%02X", pixel)
+	}
+	if pixel, _ := ssd.GetPixel(0, 10); pixel != 0x0F {
+		t.Error("rows outside the scroll area should not move")
+	}
+
+	// Deactivating freezes the current offset
+	ssd.ProcessCommand(CmdDeactivateScroll, nil)
+	ssd.StepScroll()
+
+	if pixel, _ := ssd.GetPixel(8, 0); pixel != 0x0F {
+		t.Error("display should stay at its scrolled offset after deactivation")
+	}
+}
+
+func TestSSD1322ScrollRowBounds(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+	for y := 1; y <= 4; y++ {
+		ssd.SetPixel(0, y, 0x0F)
+	}
+
+	// Setup alone does not start scrolling
+	if err := ssd.ProcessCommand(CmdContinuousScroll, []byte{0x02, 0x00, 0x00, 0x03, 0x01}); err != nil {
+		t.Fatalf("scroll setup failed: %v", err)
+	}
+	if ssd.IsScrolling() {
+		t.Error("scroll setup should not activate scrolling")
+	}
+
+	// Only rows 2-3, the inclusive start and end rows, move
+	ssd.ProcessCommand(CmdActivateScroll, nil)
+	ssd.StepScroll()
+	for y, moved := range map[int]bool{1: false, 2: true, 3: true, 4: false} {
+		if pixel, _ := ssd.GetPixel(4, y); (pixel == 0x0F) != moved {
+			t.Errorf("row %d: expected moved=%v, got level 0x%X at x=4", y, moved, pixel)
+		}
+	}
+
+	// The end row is clamped to the display
+	ssd.ProcessCommand(CmdHorizontalScroll, []byte{0x3E, 0x00, 0x00, 0xFF, 0x01})
+	if state := ssd.State(); !state.Scrolling {
+		t.Error("changing the scroll setup should keep scrolling active")
+	}
+	if ssd.scrollEndRow != 63 {
+		t.Errorf("expected end row clamped to 63, got %d", ssd.scrollEndRow)
+	}
+
+	if err := ssd.ProcessCommand(CmdHorizontalScroll, []byte{0x10, 0x00, 0x00, 0x0F, 0x01}); err == nil {
+		t.Error("expected error for an end row before the start row")
+	}
+}
+
+func TestSSD1322DisplayStartLine(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	ssd.SetPixel(0, 16, 0x0F)
+
+	// With start line 16, RAM row 16 is shown on screen row 0
+	ssd.ProcessCommand(CmdSetStartLine, []byte{16})
+
+	if pixel, _ := ssd.GetDisplayPixel(0, 0); pixel != 0x0F {
+		t.Errorf("expected RAM row 16 on screen row 0, got 0x%02X", pixel)
+	}
+
+	// Start line plus offset wraps modulo the multiplex ratio
+	ssd.ProcessCommand(CmdSetMultiplexRatio, []byte{31})
+	ssd.ProcessCommand(CmdDisplayOffset, []byte{8})
+	ssd.SetPixel(0, 4, 0x0A)
+
+	// Screen row 12: (12 + 16 + 8) % 32 = 4
+	if pixel, _ := ssd.GetDisplayPixel(0, 12); pixel != 0x0A {
+		t.Errorf("expected wrapped RAM row 4 on screen row 12, got 0x%02X", pixel)
+	}
+
+	// Rows beyond the multiplex ratio are blank
+	if pixel, _ := ssd.GetDisplayPixel(0, 40); pixel != 0 {
+		t.Errorf("rows beyond the multiplex ratio should be blank, got 0x%02X", pixel)
+	}
+}
+
+func TestSSD1322EntireDisplayOn(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	if ssd.IsAllOn() {
+		t.Error("entire display on should be off initially")
+	}
+
+	ssd.ProcessCommand(CmdEntireDisplayOn, nil)
+	if !ssd.IsAllOn() {
+		t.Error("entire display on should be active after 0xA5")
+	}
+
+	ssd.ProcessCommand(CmdDisplayMode, nil)
+	if ssd.IsAllOn() {
+		t.Error("0xA4 should return to showing VRAM")
+	}
+}
+
+func TestSSD1322InverseDisplayCommand(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	ssd.ProcessCommand(CmdInverseDisplay, nil)
+	if !ssd.IsInverted() {
+		t.Error("0xA7 should invert the display")
+	}
+
+	ssd.ProcessCommand(CmdInvertDisplay, nil)
+	if ssd.IsInverted() {
+		t.Error("0xA6 without data should select normal display")
+	}
+}
+
+func TestSSD1306Creation(t *testing.T) {
+	ssd := NewSSD1306(128, 64)
+
+	if ssd.Width() != 128 || ssd.Height() != 64 {
+		t.Errorf("expected 128x64, got %dx%d", ssd.Width(), ssd.Height())
+	}
+
+	if ssd.PixelFormat() != VerticalByte {
+		t.Error("SSD1306 should use VerticalByte pixel format")
+	}
+
+	if len(ssd.GetFrameBuffer()) != 128*8 {
+		t.Errorf("expected 1024 bytes of VRAM, got %d", len(ssd.GetFrameBuffer()))
+	}
+}
+
+func TestSSD1306SetPixel(t *testing.T) {
+	ssd := NewSSD1306(128, 64)
+
+	tests := []struct {
+		x, y     int
+		color    byte
+		expected byte
+	}{
+		{0, 0, 0x0F, 0x0F},
+		{100, 32, 0x01, 0x0F},
+		{127, 63, 0x00, 0x00},
+	}
+
+	for _, test := range tests {
+		if err := ssd.SetPixel(test.x, test.y, test.color); err != nil {
+			t.Fatalf("failed to set pixel: %v", err)
+		}
+
+		pixel, err := ssd.GetPixel(test.x, test.y)
+		if err != nil {
+			t.Fatalf("failed to get pixel: %v", err)
+		}
+
+		if pixel != test.expected {
+			t.Errorf("expected pixel 0x%02X, got 0x%02X", test.expected, pixel)
+		}
+	}
+}
+
+func TestSSD1306Commands(t *testing.T) {
+	ssd := NewSSD1306(128, 64)
+
+	ssd.ProcessCommand(SSD1306CmdDisplayOn, nil)
+	if !ssd.IsDisplayOn() {
+		t.Error("display should be on")
+	}
+
+	ssd.ProcessCommand(SSD1306CmdSetContrast, []byte{0xCF})
+	if ssd.GetContrastLevel() != 0xCF {
+		t.Errorf("expected contrast 0xCF, got 0x%02X", ssd.GetContrastLevel())
+	}
+
+	ssd.ProcessCommand(SSD1306CmdInverseDisplay, nil)
+	if !ssd.IsInverted() {
+		t.Error("display should be inverted")
+	}
+
+	// Page addressing: page 2, column 0x12
+	ssd.ProcessCommand(SSD1306CmdSetPageStart|0x02, nil)
+	ssd.ProcessCommand(SSD1306CmdSetLowColumn|0x02, nil)
+	ssd.ProcessCommand(SSD1306CmdSetHighColumn|0x01, nil)
+
+	if err := ssd.WriteData([]byte{0x81, 0xFF}); err != nil {
+		t.Fatalf("write data failed: %v", err)
+	}
+
+	// 0x81 lights the top and bottom rows of page 2 at column 18
+	if pixel, _ := ssd.GetPixel(18, 16); pixel == 0 {
+		t.Error("expected pixel (18, 16) to be lit")
+	}
+	if pixel, _ := ssd.GetPixel(18, 17); pixel != 0 {
+		t.Error("expected pixel (18, 17) to be off")
+	}
+	if pixel, _ := ssd.GetPixel(18, 23); pixel == 0 {
+		t.Error("expected pixel (18, 23) to be lit")
+	}
+	if pixel, _ := ssd.GetPixel(19, 20); pixel == 0 {
+		t.Error("expected column 19 of page 2 to be lit")
+	}
+}
+
+func TestSSD1306HorizontalAddressing(t *testing.T) {
+	ssd := NewSSD1306(128, 64)
+
+	ssd.ProcessCommand(SSD1306CmdSetMemoryMode, []byte{SSD1306HorizontalAddressing})
+	ssd.ProcessCommand(SSD1306CmdSetColumnAddress, []byte{0, 1})
+	ssd.ProcessCommand(SSD1306CmdSetPageAddress, []byte{0, 1})
+
+	// Two columns per page, so the third byte wraps to page 1
+	ssd.WriteData([]byte{0x01, 0x01, 0x01})
+
+	if pixel, _ := ssd.GetPixel(0, 8); pixel == 0 {
+		t.Error("expected write to wrap to page 1 column 0")
+	}
+	if pixel, _ := ssd.GetPixel(2, 0); pixel != 0 {
+		t.Error("column outside the window should be untouched")
+	}
+}
+
+func TestSSD1351RGBPixels(t *testing.T) {
+	ssd := NewSSD1351(128, 128)
+
+	if len(ssd.GetFrameBuffer()) != 128*128*3 {
+		t.Errorf("expected VRAM size %d, got %d", 128*128*3, len(ssd.GetFrameBuffer()))
+	}
+
+	ssd.SetPixelRGB(10, 20, 0xFF, 0x80, 0x00)
+	r, g, b, err := ssd.GetPixelRGB(10, 20)
+	if err != nil {
+		t.Fatalf("GetPixelRGB failed: %v", err)
+	}
+	if r != 0xFF || g != 0x80 || b != 0x00 {
+		t.Errorf("expected (255,128,0), got (%d,%d,%d)", r, g, b)
+	}
+
+	// Grayscale writes expand to an equivalent gray
+	ssd.SetPixel(0, 0, 0x0F)
+	r, g, b, _ = ssd.GetPixelRGB(0, 0)
+	if r != 0xFF || g != 0xFF || b != 0xFF {
+		t.Errorf("expected white, got (%d,%d,%d)", r, g, b)
+	}
+	if level, _ := ssd.GetPixel(0, 0); level != 0x0F {
+		t.Errorf("expected level 0x0F, got 0x%X", level)
+	}
+}
+
+func TestSSD1351WriteDataRGB565(t *testing.T) {
+	ssd := NewSSD1351(128, 128)
+
+	ssd.ProcessCommand(SSD1351CmdSetColumnAddress, []byte{4, 5})
+	ssd.ProcessCommand(SSD1351CmdSetRowAddress, []byte{2, 3})
+	ssd.ProcessCommand(SSD1351CmdWriteRAM, nil)
+
+	// Pure red, pure green, then pure blue wrapping to the next row
+	if err := ssd.WriteData([]byte{0xF8, 0x00, 0x07, 0xE0, 0x00, 0x1F}); err != nil {
+		t.Fatalf("WriteData failed: %v", err)
+	}
+
+	if r, g, b, _ := ssd.GetPixelRGB(4, 2); r != 0xFF || g != 0 || b != 0 {
+		t.Errorf("expected red at (4, 2), got (%d,%d,%d)", r, g, b)
+	}
+	if r, g, b, _ := ssd.GetPixelRGB(5, 2); r != 0 || g != 0xFF || b != 0 {
+		t.Errorf("expected green at (5, 2), got (%d,%d,%d)", r, g, b)
+	}
+	if r, g, b, _ := ssd.GetPixelRGB(4, 3); r != 0 || g != 0 || b != 0xFF {
+		t.Errorf("expected blue at (4, 3), got (%d,%d,%d)", r, g, b)
+	}
+
+	// Any other command ends the RAM write
+	ssd.ProcessCommand(SSD1351CmdNormalMode, nil)
+	if err := ssd.WriteData([]byte{0x00, 0x00}); err == nil {
+		t.Error("expected error writing data outside RAM write mode")
+	}
+}
+
+func TestSSD1322ReadDataRoundTrip(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+	pattern := []byte{0x21, 0x43, 0x65, 0x87, 0xA9, 0xCB}
+
+	ssd.ProcessCommand(CmdSetColumnAddress, []byte{0x1D, 0x1F})
+	ssd.ProcessCommand(CmdSetRowAddress, []byte{10, 11})
+	ssd.ProcessCommand(CmdWriteRAM, nil)
+	ssd.WriteData(pattern)
+
+	// Each byte holds two pixels, low nibble first, and column 0x1D starts at x=4
+	if pixel, _ := ssd.GetPixel(4, 10); pixel != 0x01 {
+		t.Errorf("expected pixel (4, 10) to be 0x01, got 0x%02X", pixel)
+	}
+	if pixel, _ := ssd.GetPixel(5, 10); pixel != 0x02 {
+		t.Errorf("expected pixel (5, 10) to be 0x02, got 0x%02X", pixel)
+	}
+
+	ssd.ProcessCommand(CmdSetColumnAddress, []byte{0x1D, 0x1F})
+	ssd.ProcessCommand(CmdSetRowAddress, []byte{10, 11})
+	ssd.ProcessCommand(CmdReadRAM, nil)
+
+	data, err := ssd.ReadData(len(pattern) + 1)
+	if err != nil {
+		t.Fatalf("ReadData failed: %v", err)
+	}
+
+	if data[0] != 0x00 {
+		t.Errorf("expected dummy first byte, got 0x%02X", data[0])
+	}
+	for i, want := range pattern {
+		if data[i+1] != want {
+			t.Errorf("byte %d: expected 0x%02X, got 0x%02X", i, want, data[i+1])
+		}
+	}
+}
+
+func TestSSD1322ReadDataRequiresReadRAM(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	if _, err := ssd.ReadData(1); err == nil {
+		t.Error("expected error reading without CmdReadRAM")
+	}
+
+	ssd.ProcessCommand(CmdReadRAM, nil)
+	ssd.ProcessCommand(CmdWriteRAM, nil)
+	if _, err := ssd.ReadData(1); err == nil {
+		t.Error("expected error reading after switching to CmdWriteRAM")
+	}
+}
+
+func TestSSD1322GrayscaleTable(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	table := ssd.GetGrayscaleTable()
+	if len(table) != GrayscaleTableSize || table[GrayscaleTableSize-1] != GrayscaleMax {
+		t.Fatalf("unexpected default table: %v", table)
+	}
+
+	custom := make([]byte, GrayscaleTableSize)
+	for i := range custom {
+		custom[i] = byte((i + 1) * (i + 1) * GrayscaleMax / 225)
+	}
+	if err := ssd.ProcessCommand(CmdSetGrayscaleTable, custom); err != nil {
+		t.Fatalf("CmdSetGrayscaleTable failed: %v", err)
+	}
+	if got := ssd.GetGrayscaleTable(); got[0] != custom[0] || got[7] != custom[7] {
+		t.Errorf("expected custom table, got %v", got)
+	}
+
+	if err := ssd.SetGrayscaleTable(custom[:3]); err == nil {
+		t.Error("expected error for short table")
+	}
+	custom[0] = GrayscaleMax + 1
+	if err := ssd.SetGrayscaleTable(custom); err == nil {
+		t.Error("expected error for out of range entry")
+	}
+
+	ssd.ProcessCommand(CmdGrayscaleTable, nil)
+	if got := ssd.GetGrayscaleTable(); got[0] != table[0] || got[14] != table[14] {
+		t.Errorf("expected default table restored, got %v", got)
+	}
+}
+
+func TestSSD1322CommandLogger(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	var logged []byte
+	ssd.SetCommandLogger(func(cmd byte, data []byte) {
+		logged = append(logged, cmd)
+	})
+	ssd.ProcessCommand(CmdSetContrast, []byte{0x80})
+	ssd.ProcessCommand(CmdNormalDisplay, nil)
+
+	if len(logged) != 2 || logged[0] != CmdSetContrast || logged[1] != CmdNormalDisplay {
+		t.Errorf("expected both commands logged in order, got %v", logged)
+	}
+
+	ssd.SetCommandLogger(nil)
+	ssd.ProcessCommand(CmdSleepMode, nil)
+	if len(logged) != 2 {
+		t.Error("logger should not be called after being cleared")
+	}
+}
+
+func TestSSD1322CommandHistory(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	ssd.ProcessCommand(CmdSetContrast, []byte{0x10})
+	if len(ssd.GetCommandHistory()) != 0 {
+		t.Error("history should be disabled by default")
+	}
+
+	ssd.SetCommandHistorySize(3)
+	data := []byte{0x20}
+	ssd.ProcessCommand(CmdSetContrast, data)
+	data[0] = 0xFF // History keeps its own copy
+	ssd.ProcessCommand(CmdNormalDisplay, nil)
+	ssd.ProcessCommand(CmdInverseDisplay, nil)
+	ssd.ProcessCommand(CmdSleepMode, nil)
+
+	history := ssd.GetCommandHistory()
+	expected := []byte{CmdNormalDisplay, CmdInverseDisplay, CmdSleepMode}
+	if len(history) != len(expected) {
+		t.Fatalf("expected %d records, got %d", len(expected), len(history))
+	}
+	for i, code := range expected {
+		if history[i].Code != code {
+			t.Errorf("record %d: expected 0x%02X, got 0x%02X", i, code, history[i].Code)
+		}
+	}
+
+	ssd.SetCommandHistorySize(2)
+	ssd.ProcessCommand(CmdSetContrast, data[:1])
+	if history := ssd.GetCommandHistory(); len(history) != 1 || history[0].Data[0] != 0xFF {
+		t.Errorf("expected resized history with a single record, got %v", history)
+	}
+}
+
+func TestSSD1322StrictMode(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	if ssd.IsStrictMode() {
+		t.Error("expected strict mode to be off by default")
+	}
+	if err := ssd.ProcessCommand(CmdSetContrast, nil); err != nil {
+		t.Errorf("expected short command to be accepted, got %v", err)
+	}
+
+	ssd.SetStrictMode(true)
+	if err := ssd.ProcessCommand(CmdSetContrast, nil); err == nil {
+		t.Error("expected error for contrast command without data")
+	}
+	if err := ssd.ProcessCommand(CmdSetColumnAddress, []byte{0x1C}); err == nil {
+		t.Error("expected error for column address with one data byte")
+	}
+	if err := ssd.ProcessCommand(CmdSetContrast, []byte{0x40}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if ssd.GetContrastLevel() != 0x40 {
+		t.Errorf("expected contrast 0x40, got 0x%02X", ssd.GetContrastLevel())
+	}
+	if err := ssd.ProcessCommand(CmdNormalDisplay, nil); err != nil {
+		t.Errorf("unexpected error for command without data: %v", err)
+	}
+}
+
+func TestSSD1322DumpLoadVRAM(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+	ssd.SetPixel(0, 0, 0x0A)
+	ssd.SetPixel(255, 63, 0x05)
+
+	dump := ssd.DumpVRAM()
+	if len(dump) != 9+len(ssd.GetFrameBuffer()) || string(dump[:4]) != "VRAM" {
+		t.Fatalf("unexpected dump header or size: %d bytes", len(dump))
+	}
+
+	restored := NewSSD1322(256, 64)
+	restored.ClearDirtyRegion()
+	if err := restored.LoadVRAM(dump); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	for _, p := range [][3]int{{0, 0, 0x0A}, {255, 63, 0x05}, {1, 0, 0}} {
+		if pixel, _ := restored.GetPixel(p[0], p[1]); pixel != byte(p[2]) {
+			t.Errorf("expected 0x%X at (%d, %d), got 0x%X", p[2], p[0], p[1], pixel)
+		}
+	}
+
+	if x0, y0, x1, y1 := restored.GetDirtyRegion(); x0 != 0 || y0 != 0 || x1 != 255 || y1 != 63 {
+		t.Errorf("expected full dirty region, got (%d, %d)-(%d, %d)", x0, y0, x1, y1)
+	}
+
+	if err := NewSSD1322(128, 64).LoadVRAM(dump); err == nil {
+		t.Error("expected error for mismatched dimensions")
+	}
+	if err := restored.LoadVRAM(dump[:len(dump)-1]); err == nil {
+		t.Error("expected error for truncated dump")
+	}
+	if err := restored.LoadVRAM([]byte("PNG")); err == nil {
+		t.Error("expected error for missing header")
+	}
+}
+
+func TestNullDevice(t *testing.T) {
+	var dev Device = NewNullDevice(32, 16)
+
+	if len(dev.GetFrameBuffer()) != 32*16 {
+		t.Errorf("expected one byte per pixel, got %d bytes", len(dev.GetFrameBuffer()))
+	}
+
+	if err := dev.ProcessCommand(0xAF, nil); err != nil {
+		t.Errorf("expected commands to be ignored, got %v", err)
+	}
+
+	dev.ClearDirtyRegion()
+	if err := dev.SetPixel(3, 4, 0xC8); err != nil {
+		t.Fatalf("set pixel failed: %v", err)
+	}
+	if pixel, _ := dev.GetPixel(3, 4); pixel != 0xC8 {
+		t.Errorf("expected 0xC8, got 0x%X", pixel)
+	}
+	if x0, y0, x1, y1 := dev.GetDirtyRegion(); x0 != 3 || y0 != 4 || x1 != 3 || y1 != 4 {
+		t.Errorf("expected dirty pixel (3, 4), got (%d, %d)-(%d, %d)", x0, y0, x1, y1)
+	}
+
+	if err := dev.SetPixel(32, 0, 1); err == nil {
+		t.Error("expected error for out of bounds pixel")
+	}
+
+	dev.Reset()
+	if pixel, _ := dev.GetPixel(3, 4); pixel != 0 {
+		t.Error("expected reset to clear the buffer")
+	}
+}
+
+func TestMockDeviceRecordsOperations(t *testing.T) {
+	mock := WrapMockDevice(NewSSD1322(256, 64))
+
+	data := []byte{0x1C, 0x5B}
+	mock.ProcessCommand(CmdSetColumnAddress, data)
+	data[0] = 0xFF
+	mock.ProcessCommand(CmdWriteRAM, nil)
+	mock.WriteData([]byte{0xF0})
+	mock.SetPixel(5, 6, 0x0A)
+
+	ops := mock.Operations()
+	if len(ops) != 4 {
+		t.Fatalf("expected 4 operations, got %v", ops)
+	}
+	if ops[0].Kind != OpCommand || ops[0].Command != CmdSetColumnAddress || ops[0].Data[0] != 0x1C {
+		t.Errorf("expected column address command with copied data, got %v", ops[0])
+	}
+	if ops[2].Kind != OpData || ops[2].Data[0] != 0xF0 {
+		t.Errorf("expected data write, got %v", ops[2])
+	}
+	if ops[3].Kind != OpPixel || ops[3].X != 5 || ops[3].Y != 6 || ops[3].Color != 0x0A {
+		t.Errorf("expected pixel write, got %v", ops[3])
+	}
+
+	// Calls still reach the backing device
+	if pixel, _ := mock.GetPixel(5, 6); pixel != 0x0A {
+		t.Errorf("expected pixel forwarded, got 0x%X", pixel)
+	}
+	if pixel, _ := mock.GetPixel(1, 0); pixel != 0x0F {
+		t.Errorf("expected RAM data forwarded, got 0x%X", pixel)
+	}
+
+	mock.ClearOperations()
+	if len(mock.Operations()) != 0 {
+		t.Error("expected empty log after clear")
+	}
+
+	// The null backing accepts RAM data without storing it
+	if err := NewMockDevice(8, 8).WriteData([]byte{1}); err != nil {
+		t.Errorf("expected data accepted, got %v", err)
+	}
+}
+
+func TestSSD1322WindowedWriteRAM(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+
+	// A 4x4 pixel window: column 0x1E (x = 8..11), rows 2-5
+	ssd.ProcessCommand(CmdSetColumnAddress, []byte{0x1E, 0x1E})
+	ssd.ProcessCommand(CmdSetRowAddress, []byte{2, 5})
+	ssd.ProcessCommand(CmdWriteRAM, nil)
+
+	// Each byte is two pixels, low nibble first; a row ends after the column's two bytes and wraps
+	data := make([]byte, 8)
+	for i := range data {
+		data[i] = byte(2*i+1)<<4 | byte(2*i)
+	}
+	if err := ssd.WriteData(data); err != nil {
+		t.Fatalf("write data failed: %v", err)
+	}
+
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 256; x++ {
+			var expected byte
+			if x >= 8 && x <= 11 && y >= 2 && y <= 5 {
+				expected = byte((y-2)*4 + (x - 8))
+			}
+
+			if pixel, _ := ssd.GetPixel(x, y); pixel != expected {
+				t.Fatalf("expected 0x%X at (%d, %d), got 0x%X", expected, x, y, pixel)
+			}
+		}
+	}
+
+	// Past the last row the address wraps back to the window start
+	ssd.WriteData([]byte{0xFF})
+	if pixel, _ := ssd.GetPixel(9, 2); pixel != 0x0F {
+		t.Errorf("expected wrap to (8, 2), got 0x%X", pixel)
+	}
+}
+
+func TestSSD1322State(t *testing.T) {
+	var dev Device = NewSSD1322(256, 64)
+	inspectable, ok := dev.(Inspectable)
+	if !ok {
+		t.Fatal("expected SSD1322 to implement Inspectable")
+	}
+
+	ssd := dev.(*SSD1322)
+	ssd.ProcessCommand(CmdCommandLock, []byte{0xB1})
+	ssd.ProcessCommand(CmdSetContrast, []byte{0x90})
+	ssd.ProcessCommand(CmdInverseDisplay, nil)
+	ssd.ProcessCommand(CmdNormalDisplay, nil)
+	ssd.ProcessCommand(CmdSetColumnAddress, []byte{0x1E, 0x20})
+	ssd.ProcessCommand(CmdSetRowAddress, []byte{2, 5})
+	ssd.ProcessCommand(CmdWriteRAM, nil)
+	ssd.WriteData([]byte{0x11, 0x22})
+
+	state := inspectable.State()
+	if !state.DisplayOn || state.Contrast != 0x90 || !state.Inverted || state.CommandLocked {
+		t.Errorf("unexpected display settings: %+v", state)
+	}
+	if state.ColumnStart != 0x1E || state.ColumnEnd != 0x20 || state.RowStart != 2 || state.RowEnd != 5 {
+		t.Errorf("unexpected addressing window: %+v", state)
+	}
+	if state.Column != 0x1F || state.Row != 2 {
+		t.Errorf("expected address (0x1F, 2) after one column of data, got (0x%X, %d)", state.Column, state.Row)
+	}
+}
+
+func TestSSD1322EstimatePower(t *testing.T) {
+	ssd := NewSSD1322(256, 64)
+	ssd.ProcessCommand(CmdCommandLock, []byte{0xB1})
+	ssd.ProcessCommand(CmdSetContrast, []byte{0xFF})
+
+	// Full-white panel at full contrast and master current
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 256; x++ {
+			ssd.SetPixel(x, y, 0x0F)
+		}
+	}
+	if ma := ssd.EstimatePowerMilliamps(); ma != 0 {
+		t.Errorf("expected no current with the display off, got %.2f mA", ma)
+	}
+
+	ssd.ProcessCommand(CmdNormalDisplay, nil)
+	full := ssd.EstimatePowerMilliamps()
+	if expected := 256 * 64 * DefaultPixelCurrent; math.Abs(full-expected) > 1e-6 {
+		t.Errorf("expected %.2f mA for a full-white panel, got %.2f mA", expected, full)
+	}
+
+	// Fewer lit pixels, dimmer levels and lower contrast all draw less
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 256; x++ {
+			ssd.SetPixel(x, y, 0)
+		}
+	}
+	if half := ssd.EstimatePowerMilliamps(); math.Abs(half-full/2) > 1e-6 {
+		t.Errorf("expected half the current with half the pixels lit, got %.2f of %.2f mA", half, full)
+	}
+
+	ssd.ProcessCommand(CmdSetContrast, []byte{0x7F})
+	if dimmed := ssd.EstimatePowerMilliamps(); dimmed >= full/2 {
+		t.Errorf("expected lower contrast to draw less than %.2f mA, got %.2f mA", full/2, dimmed)
+	}
+
+	// The coefficient scales the estimate linearly
+	before := ssd.EstimatePowerMilliamps()
+	ssd.SetPixelCurrent(2 * DefaultPixelCurrent)
+	if after := ssd.EstimatePowerMilliamps(); math.Abs(after-2*before) > 1e-6 {
+		t.Errorf("expected doubling the pixel current to double the estimate, got %.2f and %.2f mA", before, after)
+	}
+
+	if err := ssd.SetPixelCurrent(-1); err == nil {
+		t.Error("expected error for negative pixel current")
+	}
+}
@@ -8,6 +8,7 @@ type MemoryHelper struct {
 	height      int
 	pixelFormat PixelFormat
 	colOffset   int
+	columns     int // Internal columns per row for HorizontalNibble
 }
 
 // NewMemoryHelper creates a new memory helper
@@ -17,9 +18,16 @@ func NewMemoryHelper(width, height int, pixelFormat PixelFormat, colOffset int)
 		height:      height,
 		pixelFormat: pixelFormat,
 		colOffset:   colOffset,
+		columns:     internalColumns(width, colOffset, 0),
 	}
 }
 
+// SetInternalColumns sets the internal column count of nibble-packed VRAM
+// Zero restores the default of width + colOffset
+func (mh *MemoryHelper) SetInternalColumns(columns int) {
+	mh.columns = internalColumns(mh.width, mh.colOffset, columns)
+}
+
 // PixelToByteOffset converts pixel coordinates to VRAM byte offset for HorizontalNibble format
 func (mh *MemoryHelper) PixelToByteOffsetNibble(x, y int) (int, int, error) {
 	if x < 0 || x >= mh.width || y < 0 || y >= mh.height {
@@ -27,14 +35,24 @@ func (mh *MemoryHelper) PixelToByteOffsetNibble(x, y int) (int, int, error) {
 	}
 
 	// For SSD1322 with HorizontalNibble format (2 pixels per byte)
-	// Each row has 480 columns internally (even if display is 256 wide)
-	columns := 480
-	byteOffset := (y*columns + x + mh.colOffset) / 2
-	nibbleIndex := (x + mh.colOffset) % 2
+	// Each row spans all internal columns, even if the display is narrower
+	column := x + mh.colOffset
+	if column >= mh.columns {
+		return 0, 0, fmt.Errorf("column out of bounds: %d (internal columns: %d)", column, mh.columns)
+	}
+
+	// Rows start on a byte boundary, so an odd column count cannot shift the nibbles of later rows
+	byteOffset := y*nibbleRowBytes(mh.columns) + column/2
+	nibbleIndex := column % 2
 
 	return byteOffset, nibbleIndex, nil
 }
 
+// nibbleRowBytes returns the bytes used by one row of nibble-packed VRAM
+func nibbleRowBytes(columns int) int {
+	return (columns + 1) / 2
+}
+
 // SetPixelNibble sets a pixel in HorizontalNibble format (4-bit gray)
 func (mh *MemoryHelper) SetPixelNibble(vram []byte, x, y int, color byte) error {
 	byteOffset, nibbleIndex, err := mh.PixelToByteOffsetNibble(x, y)
@@ -159,6 +177,35 @@ func (mh *MemoryHelper) GetPixelRGB888(vram []byte, x, y int) (byte, byte, byte,
 	return vram[offset], vram[offset+1], vram[offset+2], nil
 }
 
+// SetPixel8 sets a pixel in EightBitGray format (8-bit gray)
+func (mh *MemoryHelper) SetPixel8(vram []byte, x, y int, value byte) error {
+	if x < 0 || x >= mh.width || y < 0 || y >= mh.height {
+		return fmt.Errorf("pixel out of bounds: (%d, %d)", x, y)
+	}
+
+	offset := y*mh.width + x
+	if offset >= len(vram) {
+		return fmt.Errorf("VRAM offset out of bounds: %d", offset)
+	}
+
+	vram[offset] = value
+	return nil
+}
+
+// GetPixel8 reads a pixel in EightBitGray format
+func (mh *MemoryHelper) GetPixel8(vram []byte, x, y int) (byte, error) {
+	if x < 0 || x >= mh.width || y < 0 || y >= mh.height {
+		return 0, fmt.Errorf("pixel out of bounds: (%d, %d)", x, y)
+	}
+
+	offset := y*mh.width + x
+	if offset >= len(vram) {
+		return 0, fmt.Errorf("VRAM offset out of bounds: %d", offset)
+	}
+
+	return vram[offset], nil
+}
+
 // FillRegionNibble fills a rectangular region with a color in HorizontalNibble format
 func (mh *MemoryHelper) FillRegionNibble(vram []byte, x0, y0, x1, y1 int, color byte) error {
 	color = color & 0x0F
@@ -0,0 +1,96 @@
+package device
+
+import "fmt"
+
+// OpKind identifies the call recorded by a MockDevice
+type OpKind int
+
+const (
+	OpCommand OpKind = iota // ProcessCommand
+	OpData                  // WriteData
+	OpPixel                 // SetPixel
+)
+
+// Op is a single call recorded by a MockDevice
+type Op struct {
+	Kind    OpKind
+	Command byte   // OpCommand only
+	Data    []byte // Command data for OpCommand, RAM data for OpData
+	X       int    // OpPixel only
+	Y       int    // OpPixel only
+	Color   byte   // OpPixel only
+}
+
+// String formats the operation for test failure messages
+func (op Op) String() string {
+	switch op.Kind {
+	case OpCommand:
+		return fmt.Sprintf("command 0x%02X % X", op.Command, op.Data)
+	case OpData:
+		return fmt.Sprintf("data % X", op.Data)
+	case OpPixel:
+		return fmt.Sprintf("pixel (%d, %d) = 0x%X", op.X, op.Y, op.Color)
+	default:
+		return fmt.Sprintf("unknown op %d", op.Kind)
+	}
+}
+
+// dataWriter is implemented by devices that accept display RAM data (e.g. SSD1322.WriteData)
+type dataWriter interface {
+	WriteData(data []byte) error
+}
+
+// MockDevice records every ProcessCommand, WriteData and SetPixel call in order
+// Calls are forwarded to a backing device, so drawing still renders
+type MockDevice struct {
+	Device
+	ops []Op
+}
+
+// NewMockDevice creates a mock device backed by a null device
+func NewMockDevice(width, height int) *MockDevice {
+	return WrapMockDevice(NewNullDevice(width, height))
+}
+
+// WrapMockDevice creates a mock device that records calls to dev, e.g. an SSD1322
+func WrapMockDevice(dev Device) *MockDevice {
+	return &MockDevice{
+		Device: dev,
+		ops:    make([]Op, 0),
+	}
+}
+
+// ProcessCommand records the command and forwards it to the backing device
+func (md *MockDevice) ProcessCommand(cmd byte, data []byte) error {
+	md.ops = append(md.ops, Op{Kind: OpCommand, Command: cmd, Data: append([]byte(nil), data...)})
+	return md.Device.ProcessCommand(cmd, data)
+}
+
+// WriteData records the data and forwards it when the backing device accepts RAM data
+func (md *MockDevice) WriteData(data []byte) error {
+	md.ops = append(md.ops, Op{Kind: OpData, Data: append([]byte(nil), data...)})
+
+	if writer, ok := md.Device.(dataWriter); ok {
+		return writer.WriteData(data)
+	}
+
+	return nil
+}
+
+// SetPixel records the pixel and forwards it to the backing device
+func (md *MockDevice) SetPixel(x, y int, color byte) error {
+	md.ops = append(md.ops, Op{Kind: OpPixel, X: x, Y: y, Color: color})
+	return md.Device.SetPixel(x, y, color)
+}
+
+// Operations returns the recorded calls, oldest first
+func (md *MockDevice) Operations() []Op {
+	result := make([]Op, len(md.ops))
+	copy(result, md.ops)
+	return result
+}
+
+// ClearOperations empties the operation log
+func (md *MockDevice) ClearOperations() {
+	md.ops = md.ops[:0]
+}
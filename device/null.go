@@ -0,0 +1,53 @@
+package device
+
+// NullDevice is a minimal Device with one byte per pixel and no controller behavior
+// It accepts and ignores every command, which makes it a fast target for testing drawing code
+type NullDevice struct {
+	*BaseDevice
+	memory *MemoryHelper
+}
+
+// NewNullDevice creates a new null device
+func NewNullDevice(width, height int) *NullDevice {
+	config := Config{
+		Width:       width,
+		Height:      height,
+		ColorDepth:  8,
+		PixelFormat: EightBitGray,
+	}
+
+	return &NullDevice{
+		BaseDevice: NewBaseDevice(config),
+		memory:     NewMemoryHelper(width, height, EightBitGray, 0),
+	}
+}
+
+// ProcessCommand implements the Device interface, ignoring the command
+func (nd *NullDevice) ProcessCommand(cmd byte, data []byte) error {
+	return nil
+}
+
+// Reset implements the Device interface, clearing the buffer
+func (nd *NullDevice) Reset() error {
+	for i := range nd.vram {
+		nd.vram[i] = 0
+	}
+
+	nd.MarkDirty(0, 0, nd.Width()-1, nd.Height()-1)
+	return nil
+}
+
+// SetPixel implements the Device interface, storing the value as given
+func (nd *NullDevice) SetPixel(x, y int, color byte) error {
+	if err := nd.memory.SetPixel8(nd.vram, x, y, color); err != nil {
+		return err
+	}
+
+	nd.MarkDirty(x, y, x, y)
+	return nil
+}
+
+// GetPixel implements the Device interface
+func (nd *NullDevice) GetPixel(x, y int) (byte, error) {
+	return nd.memory.GetPixel8(nd.vram, x, y)
+}
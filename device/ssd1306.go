@@ -0,0 +1,371 @@
+package device
+
+import (
+	"fmt"
+)
+
+// SSD1306 command codes
+const (
+	// Fundamental Commands
+	SSD1306CmdSetContrast       = 0x81 // Set contrast
+	SSD1306CmdDisplayResume     = 0xA4 // Resume to RAM content display
+	SSD1306CmdEntireDisplayOn   = 0xA5 // Entire display ON
+	SSD1306CmdNormalDisplay     = 0xA6 // Normal display
+	SSD1306CmdInverseDisplay    = 0xA7 // Inverse display
+	SSD1306CmdDisplayOff        = 0xAE // Display OFF (sleep mode)
+	SSD1306CmdDisplayOn         = 0xAF // Display ON
+	SSD1306CmdSetLowColumn      = 0x00 // Set lower column start nibble (0x00-0x0F, page mode)
+	SSD1306CmdSetHighColumn     = 0x10 // Set higher column start nibble (0x10-0x1F, page mode)
+	SSD1306CmdSetPageStart      = 0xB0 // Set page start address (0xB0-0xB7, page mode)
+	SSD1306CmdSetStartLine      = 0x40 // Set display start line (0x40-0x7F)
+	SSD1306CmdSetMemoryMode     = 0x20 // Set memory addressing mode
+	SSD1306CmdSetColumnAddress  = 0x21 // Set column address (horizontal/vertical mode)
+	SSD1306CmdSetPageAddress    = 0x22 // Set page address (horizontal/vertical mode)
+	SSD1306CmdSetSegmentRemap   = 0xA0 // Segment remap (0xA0/0xA1)
+	SSD1306CmdSetMultiplexRatio = 0xA8 // Set MUX ratio
+	SSD1306CmdSetCOMScanInc     = 0xC0 // COM output scan direction normal
+	SSD1306CmdSetCOMScanDec     = 0xC8 // COM output scan direction remapped
+	SSD1306CmdSetDisplayOffset  = 0xD3 // Set display offset
+	SSD1306CmdSetClockDivider   = 0xD5 // Set display clock divide ratio
+	SSD1306CmdSetPrecharge      = 0xD9 // Set pre-charge period
+	SSD1306CmdSetCOMPins        = 0xDA // Set COM pins hardware configuration
+	SSD1306CmdSetVCOMH          = 0xDB // Set V_COMH deselect level
+	SSD1306CmdChargePump        = 0x8D // Charge pump setting
+)
+
+// SSD1306 memory addressing modes (SSD1306CmdSetMemoryMode data byte)
+const (
+	SSD1306HorizontalAddressing = 0x00
+	SSD1306VerticalAddressing   = 0x01
+	SSD1306PageAddressing       = 0x02
+)
+
+// SSD1306 display controller emulation (1-bit monochrome, page addressed)
+type SSD1306 struct {
+	*BaseDevice
+	memory          *MemoryHelper
+	displayOn       bool
+	contrastLevel   byte
+	invertDisplay   bool
+	displayAllOn    bool
+	addressingMode  byte
+	columnStart     int
+	columnEnd       int
+	pageStart       int
+	pageEnd         int
+	currentColumn   int
+	currentPage     int
+	startLine       int
+	displayOffset   int
+	multiplexRatio  byte
+	segmentRemap    bool
+	comScanReversed bool
+	clockDivider    byte
+	prechargePeriod byte
+	comPins         byte
+	vcomhLevel      byte
+	chargePump      byte
+}
+
+// NewSSD1306 creates a new SSD1306 device
+func NewSSD1306(width, height int) *SSD1306 {
+	config := Config{
+		Width:       width,
+		Height:      height,
+		ColorDepth:  1,
+		PixelFormat: VerticalByte,
+	}
+
+	ssd1306 := &SSD1306{
+		BaseDevice: NewBaseDevice(config),
+		memory:     NewMemoryHelper(width, height, VerticalByte, 0),
+	}
+	ssd1306.resetState()
+
+	return ssd1306
+}
+
+// ProcessCommand handles SSD1306 commands
+func (ssd *SSD1306) ProcessCommand(cmd byte, data []byte) error {
+	// Commands that encode their argument in the command byte itself
+	switch {
+	case cmd <= 0x0F:
+		// Lower column start nibble (page addressing)
+		ssd.currentColumn = (ssd.currentColumn & 0xF0) | int(cmd&0x0F)
+		return nil
+
+	case cmd >= 0x10 && cmd <= 0x1F:
+		// Higher column start nibble (page addressing)
+		ssd.currentColumn = (ssd.currentColumn & 0x0F) | (int(cmd&0x0F) << 4)
+		return nil
+
+	case cmd >= 0x40 && cmd <= 0x7F:
+		ssd.startLine = int(cmd & 0x3F)
+		return nil
+
+	case cmd >= 0xB0 && cmd <= 0xB7:
+		ssd.currentPage = int(cmd & 0x07)
+		return nil
+	}
+
+	switch cmd {
+	case SSD1306CmdDisplayOn:
+		ssd.displayOn = true
+		return nil
+
+	case SSD1306CmdDisplayOff:
+		ssd.displayOn = false
+		return nil
+
+	case SSD1306CmdSetContrast:
+		if len(data) > 0 {
+			ssd.contrastLevel = data[0]
+		}
+		return nil
+
+	case SSD1306CmdDisplayResume:
+		ssd.displayAllOn = false
+		return nil
+
+	case SSD1306CmdEntireDisplayOn:
+		ssd.displayAllOn = true
+		return nil
+
+	case SSD1306CmdNormalDisplay:
+		ssd.invertDisplay = false
+		return nil
+
+	case SSD1306CmdInverseDisplay:
+		ssd.invertDisplay = true
+		return nil
+
+	case SSD1306CmdSetMemoryMode:
+		if len(data) > 0 {
+			ssd.addressingMode = data[0] & 0x03
+		}
+		return nil
+
+	case SSD1306CmdSetColumnAddress:
+		if len(data) >= 2 {
+			ssd.columnStart = int(data[0])
+			ssd.columnEnd = int(data[1])
+			ssd.currentColumn = ssd.columnStart
+		}
+		return nil
+
+	case SSD1306CmdSetPageAddress:
+		if len(data) >= 2 {
+			ssd.pageStart = int(data[0] & 0x07)
+			ssd.pageEnd = int(data[1] & 0x07)
+			ssd.currentPage = ssd.pageStart
+		}
+		return nil
+
+	case SSD1306CmdSetSegmentRemap, SSD1306CmdSetSegmentRemap | 0x01:
+		ssd.segmentRemap = cmd&0x01 != 0
+		return nil
+
+	case SSD1306CmdSetCOMScanInc:
+		ssd.comScanReversed = false
+		return nil
+
+	case SSD1306CmdSetCOMScanDec:
+		ssd.comScanReversed = true
+		return nil
+
+	case SSD1306CmdSetMultiplexRatio:
+		if len(data) > 0 {
+			ssd.multiplexRatio = data[0] & 0x3F
+		}
+		return nil
+
+	case SSD1306CmdSetDisplayOffset:
+		if len(data) > 0 {
+			ssd.displayOffset = int(data[0] & 0x3F)
+		}
+		return nil
+
+	case SSD1306CmdSetClockDivider:
+		if len(data) > 0 {
+			ssd.clockDivider = data[0]
+		}
+		return nil
+
+	case SSD1306CmdSetPrecharge:
+		if len(data) > 0 {
+			ssd.prechargePeriod = data[0]
+		}
+		return nil
+
+	case SSD1306CmdSetCOMPins:
+		if len(data) > 0 {
+			ssd.comPins = data[0]
+		}
+		return nil
+
+	case SSD1306CmdSetVCOMH:
+		if len(data) > 0 {
+			ssd.vcomhLevel = data[0]
+		}
+		return nil
+
+	case SSD1306CmdChargePump:
+		if len(data) > 0 {
+			ssd.chargePump = data[0]
+		}
+		return nil
+
+	default:
+		// Unknown command - silently ignore
+		return nil
+	}
+}
+
+// WriteData writes display RAM bytes at the current page/column address
+// Each byte holds 8 vertical pixels, LSB at the top of the page
+func (ssd *SSD1306) WriteData(data []byte) error {
+	pages := (ssd.Height() + 7) / 8
+
+	for _, byteVal := range data {
+		col := ssd.currentColumn
+		page := ssd.currentPage
+
+		if col >= 0 && col < ssd.Width() && page >= 0 && page < pages {
+			offset := col*pages + page
+			if offset >= len(ssd.vram) {
+				return fmt.Errorf("VRAM offset out of bounds: %d", offset)
+			}
+
+			ssd.vram[offset] = byteVal
+			ssd.MarkDirty(col, page*8, col, page*8+7)
+		}
+
+		ssd.advanceAddress()
+	}
+
+	return nil
+}
+
+// advanceAddress moves the write cursor according to the addressing mode
+func (ssd *SSD1306) advanceAddress() {
+	switch ssd.addressingMode {
+	case SSD1306HorizontalAddressing:
+		ssd.currentColumn++
+		if ssd.currentColumn > ssd.columnEnd {
+			ssd.currentColumn = ssd.columnStart
+			ssd.currentPage++
+			if ssd.currentPage > ssd.pageEnd {
+				ssd.currentPage = ssd.pageStart
+			}
+		}
+
+	case SSD1306VerticalAddressing:
+		ssd.currentPage++
+		if ssd.currentPage > ssd.pageEnd {
+			ssd.currentPage = ssd.pageStart
+			ssd.currentColumn++
+			if ssd.currentColumn > ssd.columnEnd {
+				ssd.currentColumn = ssd.columnStart
+			}
+		}
+
+	default:
+		// Page addressing: the column wraps within the current page
+		ssd.currentColumn++
+		if ssd.currentColumn >= ssd.Width() {
+			ssd.currentColumn = 0
+		}
+	}
+}
+
+// SetPixel implements the Device interface
+// Any non-zero color lights the pixel
+func (ssd *SSD1306) SetPixel(x, y int, color byte) error {
+	if x < 0 || x >= ssd.Width() || y < 0 || y >= ssd.Height() {
+		return fmt.Errorf("pixel out of bounds: (%d, %d)", x, y)
+	}
+
+	if err := ssd.memory.SetPixelVertical(ssd.vram, x, y, color&0x0F); err != nil {
+		return err
+	}
+
+	ssd.MarkDirty(x, y, x, y)
+	return nil
+}
+
+// GetPixel implements the Device interface
+// Lit pixels are reported as full-scale 0x0F so 4-bit consumers work unchanged
+func (ssd *SSD1306) GetPixel(x, y int) (byte, error) {
+	pixel, err := ssd.memory.GetPixelVertical(ssd.vram, x, y)
+	if err != nil {
+		return 0, err
+	}
+
+	if pixel != 0 {
+		return 0x0F, nil
+	}
+	return 0, nil
+}
+
+// Reset performs a hardware reset
+func (ssd *SSD1306) Reset() error {
+	// Clear VRAM
+	for i := range ssd.vram {
+		ssd.vram[i] = 0
+	}
+
+	ssd.resetState()
+
+	ssd.MarkDirty(0, 0, ssd.Width()-1, ssd.Height()-1)
+	return nil
+}
+
+// resetState restores all settings to their power-on defaults
+func (ssd *SSD1306) resetState() {
+	ssd.displayOn = false
+	ssd.contrastLevel = 0x7F
+	ssd.invertDisplay = false
+	ssd.displayAllOn = false
+	ssd.addressingMode = SSD1306PageAddressing
+	ssd.columnStart = 0
+	ssd.columnEnd = ssd.Width() - 1
+	ssd.pageStart = 0
+	ssd.pageEnd = (ssd.Height()+7)/8 - 1
+	ssd.currentColumn = 0
+	ssd.currentPage = 0
+	ssd.startLine = 0
+	ssd.displayOffset = 0
+	ssd.multiplexRatio = 0x3F
+	ssd.segmentRemap = false
+	ssd.comScanReversed = false
+	ssd.clockDivider = 0x80
+	ssd.prechargePeriod = 0x22
+	ssd.comPins = 0x12
+	ssd.vcomhLevel = 0x20
+	ssd.chargePump = 0x10
+}
+
+// IsDisplayOn returns whether the display is powered on
+func (ssd *SSD1306) IsDisplayOn() bool {
+	return ssd.displayOn
+}
+
+// GetContrastLevel returns current contrast
+func (ssd *SSD1306) GetContrastLevel() byte {
+	return ssd.contrastLevel
+}
+
+// IsInverted returns whether display is inverted
+func (ssd *SSD1306) IsInverted() bool {
+	return ssd.invertDisplay
+}
+
+// IsAllOn returns whether the entire display is forced on (0xA5)
+func (ssd *SSD1306) IsAllOn() bool {
+	return ssd.displayAllOn
+}
+
+// GetAddressingMode returns the current memory addressing mode
+func (ssd *SSD1306) GetAddressingMode() byte {
+	return ssd.addressingMode
+}
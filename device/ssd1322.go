@@ -1,6 +1,7 @@
 package device
 
 import (
+	"encoding/binary"
 	"fmt"
 )
 
@@ -17,7 +18,9 @@ const (
 	CmdSetStartLine      = 0xA1 // Set display start line
 	CmdDisplayOffset     = 0xA2 // Set display offset
 	CmdDisplayMode       = 0xA4 // Set display mode (normal/entire on)
+	CmdEntireDisplayOn   = 0xA5 // Entire display ON (all pixels at full brightness)
 	CmdInvertDisplay     = 0xA6 // Set normal/inverse display
+	CmdInverseDisplay    = 0xA7 // Inverse display
 	CmdSetMultiplexRatio = 0xCA // Set MUX ratio
 
 	// Display On/Off
@@ -38,12 +41,18 @@ const (
 	CmdSetVCOMH        = 0xBE // Set V_COMH deselect level
 
 	// Grayscale Table
-	CmdGrayscaleTable = 0xB9 // Set default grayscale table
+	CmdSetGrayscaleTable = 0xB8 // Load custom grayscale table (15 data bytes)
+	CmdGrayscaleTable    = 0xB9 // Set default grayscale table
 
 	// Command Lock
 	CmdCommandLock = 0xFD // Set command lock
 )
 
+// SSD1322 remap byte bits (CmdSetRemap first data byte)
+const (
+	RemapVerticalIncrement = 0x01 // Vertical address increment (rows first, then columns)
+)
+
 // SSD1322 display controller emulation
 type SSD1322 struct {
 	*BaseDevice
@@ -51,16 +60,24 @@ type SSD1322 struct {
 	commandLocked      bool
 	displayOn          bool
 	dataMode           bool // true = data, false = command
+	readMode           bool // true after CmdReadRAM, ReadData returns VRAM contents
+	readDummyPending   bool // first read after CmdReadRAM returns a dummy byte
 	contrastLevel      byte
 	masterCurrentLevel byte
 	invertDisplay      bool
+	displayAllOn       bool
 	columnStart        int
 	columnEnd          int
 	rowStart           int
 	rowEnd             int
 	currentColumn      int
 	currentRow         int
+	columnByte         int // Data byte within the current column address, 0 or 1
 	scrollEnabled      bool
+	scrollStep         int // 4-pixel columns shifted per scroll step
+	scrollStartRow     int
+	scrollEndRow       int
+	scrollInterval     int // frames between scroll steps
 	startLine          int
 	displayOffset      int
 	multiplexRatio     byte
@@ -70,16 +87,92 @@ type SSD1322 struct {
 	vcomhLevel         byte
 	remapSettings      byte
 	grayscaleTableMode int // 0 = default, 1 = custom
+	grayscaleTable     [GrayscaleTableSize]byte
+	commandLogger      func(cmd byte, data []byte)
+	history            []CommandRecord // Ring buffer of processed commands
+	historySize        int             // 0 = history disabled
+	historyNext        int             // Index the next record is written to
+	strictMode         bool
+	pixelCurrent       float64 // mA drawn by one pixel at full brightness, for EstimatePowerMilliamps
+}
+
+// ssd1322DataBytes is the number of data bytes each known command takes
+var ssd1322DataBytes = map[byte]int{
+	CmdSetColumnAddress:  2,
+	CmdSetRowAddress:     2,
+	CmdWriteRAM:          0,
+	CmdReadRAM:           0,
+	CmdSetContrast:       1,
+	CmdMasterContrast:    1,
+	CmdSetRemap:          1,
+	CmdSetStartLine:      1,
+	CmdDisplayOffset:     1,
+	CmdDisplayMode:       0,
+	CmdEntireDisplayOn:   0,
+	CmdInvertDisplay:     0,
+	CmdInverseDisplay:    0,
+	CmdSleepMode:         0,
+	CmdNormalDisplay:     0,
+	CmdSetMultiplexRatio: 1,
+	CmdSetClockDivider:   1,
+	CmdSetPhaseLength:    1,
+	CmdSetPrecharge:      1,
+	CmdSetVCOMH:          1,
+	CmdEnhanceDisplay:    1,
+	0xD1:                 1, // Display enhancement B
+	CmdHorizontalScroll:  5,
+	CmdContinuousScroll:  5,
+	CmdDeactivateScroll:  0,
+	CmdActivateScroll:    0,
+	CmdSetGrayscaleTable: GrayscaleTableSize,
+	CmdGrayscaleTable:    0,
+	CmdCommandLock:       1,
+}
+
+// SSD1322DataBytes returns the number of data bytes a command takes and whether the command is known
+func SSD1322DataBytes(cmd byte) (int, bool) {
+	count, ok := ssd1322DataBytes[cmd]
+	return count, ok
+}
+
+// CommandRecord is a command processed by the device, as kept in the command history
+type CommandRecord struct {
+	Code byte
+	Data []byte
+}
+
+// ssd1322ColumnPixels is the number of pixels (4-bit segments) behind one column address
+// Each column address takes two data bytes
+const ssd1322ColumnPixels = 4
+
+// GrayscaleTableSize is the number of entries in the grayscale table (GS1..GS15)
+const GrayscaleTableSize = 15
+
+// GrayscaleMax is the largest grayscale table value accepted by the controller
+const GrayscaleMax = 180
+
+// DefaultPixelCurrent is the estimated current in mA of one pixel at full gray level, contrast and master current
+// It puts a full-white 256x64 panel at about 160 mA, in line with typical SSD1322 modules
+const DefaultPixelCurrent = 0.01
+
+// defaultGrayscaleTable returns the linear table selected by CmdGrayscaleTable
+func defaultGrayscaleTable() [GrayscaleTableSize]byte {
+	var table [GrayscaleTableSize]byte
+	for i := range table {
+		table[i] = byte((i + 1) * GrayscaleMax / GrayscaleTableSize)
+	}
+	return table
 }
 
 // NewSSD1322 creates a new SSD1322 device
 func NewSSD1322(width, height int) *SSD1322 {
 	config := Config{
-		Width:        width,
-		Height:       height,
-		ColorDepth:   4,
-		PixelFormat:  HorizontalNibble,
-		ColumnOffset: 28, // SSD1322 has 480 internal columns, display starts at column 28
+		Width:           width,
+		Height:          height,
+		ColorDepth:      4,
+		PixelFormat:     HorizontalNibble,
+		ColumnOffset:    28,  // Display starts at internal column 28
+		InternalColumns: 480, // SSD1322 has 480 internal columns
 	}
 
 	baseDevice := NewBaseDevice(config)
@@ -93,13 +186,18 @@ func NewSSD1322(width, height int) *SSD1322 {
 		contrastLevel:      0x7F,
 		masterCurrentLevel: 0x0F,
 		invertDisplay:      false,
-		columnStart:        0,
-		columnEnd:          width - 1,
+		displayAllOn:       false,
+		columnStart:        config.ColumnOffset,
+		columnEnd:          config.ColumnOffset + (width+ssd1322ColumnPixels-1)/ssd1322ColumnPixels - 1,
 		rowStart:           0,
 		rowEnd:             height - 1,
-		currentColumn:      0,
+		currentColumn:      config.ColumnOffset,
 		currentRow:         0,
 		scrollEnabled:      false,
+		scrollStep:         1,
+		scrollStartRow:     0,
+		scrollEndRow:       height - 1,
+		scrollInterval:     1,
 		startLine:          0,
 		displayOffset:      0,
 		multiplexRatio:     0x3F,
@@ -109,13 +207,28 @@ func NewSSD1322(width, height int) *SSD1322 {
 		vcomhLevel:         0x07,
 		remapSettings:      0x14,
 		grayscaleTableMode: 0,
+		grayscaleTable:     defaultGrayscaleTable(),
+		pixelCurrent:       DefaultPixelCurrent,
 	}
+	ssd1322.memory.SetInternalColumns(config.InternalColumns)
 
 	return ssd1322
 }
 
 // ProcessCommand handles SSD1322 commands
 func (ssd *SSD1322) ProcessCommand(cmd byte, data []byte) error {
+	if ssd.commandLogger != nil {
+		ssd.commandLogger(cmd, data)
+	}
+	if ssd.historySize > 0 {
+		ssd.recordCommand(cmd, data)
+	}
+	if ssd.strictMode {
+		if expected, ok := ssd1322DataBytes[cmd]; ok && len(data) < expected {
+			return fmt.Errorf("command 0x%02X needs %d data bytes, got %d", cmd, expected, len(data))
+		}
+	}
+
 	// Most commands are locked unless unlocked with CmdCommandLock
 	switch cmd {
 	case CmdCommandLock:
@@ -140,10 +253,14 @@ func (ssd *SSD1322) ProcessCommand(cmd byte, data []byte) error {
 	case CmdWriteRAM:
 		// This switches to data mode for RAM writing
 		ssd.dataMode = true
+		ssd.readMode = false
 		return nil
 
 	case CmdReadRAM:
+		// The chip returns a dummy byte before the first real RAM byte
 		ssd.dataMode = true
+		ssd.readMode = true
+		ssd.readDummyPending = true
 		return nil
 	}
 
@@ -158,6 +275,7 @@ func (ssd *SSD1322) ProcessCommand(cmd byte, data []byte) error {
 			ssd.columnStart = int(data[0])
 			ssd.columnEnd = int(data[1])
 			ssd.currentColumn = ssd.columnStart
+			ssd.columnByte = 0
 		}
 		return nil
 
@@ -166,6 +284,7 @@ func (ssd *SSD1322) ProcessCommand(cmd byte, data []byte) error {
 			ssd.rowStart = int(data[0])
 			ssd.rowEnd = int(data[1])
 			ssd.currentRow = ssd.rowStart
+			ssd.columnByte = 0
 		}
 		return nil
 
@@ -182,9 +301,12 @@ func (ssd *SSD1322) ProcessCommand(cmd byte, data []byte) error {
 		return nil
 
 	case CmdInvertDisplay:
-		if len(data) > 0 {
-			ssd.invertDisplay = (data[0] & 0x01) != 0
-		}
+		// 0xA6 without data selects normal display
+		ssd.invertDisplay = len(data) > 0 && (data[0]&0x01) != 0
+		return nil
+
+	case CmdInverseDisplay:
+		ssd.invertDisplay = true
 		return nil
 
 	case CmdSetMultiplexRatio:
@@ -239,10 +361,13 @@ func (ssd *SSD1322) ProcessCommand(cmd byte, data []byte) error {
 		}
 		return nil
 
+	case CmdSetGrayscaleTable:
+		return ssd.SetGrayscaleTable(data)
+
 	case CmdGrayscaleTable:
-		if len(data) > 0 {
-			ssd.grayscaleTableMode = int(data[0])
-		}
+		ssd.grayscaleTableMode = 0
+		ssd.grayscaleTable = defaultGrayscaleTable()
+		ssd.MarkDirty(0, 0, ssd.Width()-1, ssd.Height()-1)
 		return nil
 
 	case CmdDeactivateScroll:
@@ -253,14 +378,17 @@ func (ssd *SSD1322) ProcessCommand(cmd byte, data []byte) error {
 		ssd.scrollEnabled = true
 		return nil
 
-	case CmdHorizontalScroll:
-		if len(data) >= 5 {
-			ssd.scrollEnabled = true
-		}
-		return nil
+	case CmdHorizontalScroll, CmdContinuousScroll:
+		return ssd.setupScroll(data)
 
 	case CmdDisplayMode:
-		// 0xA4 = normal, 0xA5 = entire display ON
+		// 0xA4 = normal, shows VRAM contents
+		ssd.displayAllOn = false
+		return nil
+
+	case CmdEntireDisplayOn:
+		// 0xA5 = entire display ON, regardless of VRAM contents
+		ssd.displayAllOn = true
 		return nil
 
 	default:
@@ -275,51 +403,182 @@ func (ssd *SSD1322) WriteData(data []byte) error {
 		return fmt.Errorf("not in data write mode")
 	}
 
+	// The written area is marked dirty once, so a full-frame burst costs a single update
+	dirtyX0, dirtyY0, dirtyX1, dirtyY1 := ssd.Width(), ssd.Height(), -1, -1
+
 	for _, byteVal := range data {
 		// Each byte contains 2 pixels (4-bit each)
-		// Convert from VRAM column addressing to display coordinates
-		col := ssd.currentColumn
-		row := ssd.currentRow
-
-		if col >= ssd.columnStart && col <= ssd.columnEnd &&
-			row >= ssd.rowStart && row <= ssd.rowEnd {
-
-			// Get actual display coordinates
-			// (accounting for column offset)
-			displayCol := col - ssd.columnStart
-
-			if displayCol < ssd.Width() {
-				// Write lower nibble (first pixel)
-				pixel1 := byteVal & 0x0F
-				if err := ssd.memory.SetPixelNibble(ssd.vram, displayCol, row, pixel1); err == nil {
-					ssd.MarkDirty(displayCol, row, displayCol, row)
-				}
-
-				// Write upper nibble (second pixel)
-				displayCol++
-				if displayCol < ssd.Width() {
-					pixel2 := (byteVal >> 4) & 0x0F
-					if err := ssd.memory.SetPixelNibble(ssd.vram, displayCol, row, pixel2); err == nil {
-						ssd.MarkDirty(displayCol, row, displayCol, row)
-					}
-				}
-
-				// Advance to next column pair
-				ssd.currentColumn++
-				if ssd.currentColumn > ssd.columnEnd {
-					ssd.currentColumn = ssd.columnStart
-					ssd.currentRow++
-					if ssd.currentRow > ssd.rowEnd {
-						ssd.currentRow = ssd.rowStart
-					}
-				}
+		if x, ok := ssd.ramPixel(); ok {
+			// Write lower nibble (first pixel)
+			lastX := x
+			ssd.memory.SetPixelNibble(ssd.vram, x, ssd.currentRow, byteVal&0x0F)
+
+			// Write upper nibble (second pixel)
+			if x+1 < ssd.Width() {
+				ssd.memory.SetPixelNibble(ssd.vram, x+1, ssd.currentRow, (byteVal>>4)&0x0F)
+				lastX = x + 1
+			}
+
+			dirtyX0 = min(dirtyX0, x)
+			dirtyY0 = min(dirtyY0, ssd.currentRow)
+			dirtyX1 = max(dirtyX1, lastX)
+			dirtyY1 = max(dirtyY1, ssd.currentRow)
+		}
+
+		// Advance to next column pair
+		ssd.advanceAddress()
+	}
+
+	if dirtyX1 >= 0 {
+		ssd.MarkDirty(dirtyX0, dirtyY0, dirtyX1, dirtyY1)
+	}
+
+	return nil
+}
+
+// ReadData reads pixel data from VRAM at the current addressing position
+// Like the real chip, the first byte after CmdReadRAM is a dummy read (0x00)
+func (ssd *SSD1322) ReadData(length int) ([]byte, error) {
+	if !ssd.readMode {
+		return nil, fmt.Errorf("not in data read mode")
+	}
+
+	result := make([]byte, 0, length)
+
+	for len(result) < length {
+		if ssd.readDummyPending {
+			ssd.readDummyPending = false
+			result = append(result, 0x00)
+			continue
+		}
+
+		var byteVal byte
+		if x, ok := ssd.ramPixel(); ok {
+			pixel1, _ := ssd.memory.GetPixelNibble(ssd.vram, x, ssd.currentRow)
+			byteVal = pixel1 & 0x0F
+
+			if x+1 < ssd.Width() {
+				pixel2, _ := ssd.memory.GetPixelNibble(ssd.vram, x+1, ssd.currentRow)
+				byteVal |= (pixel2 & 0x0F) << 4
 			}
 		}
+
+		result = append(result, byteVal)
+		ssd.advanceAddress()
 	}
 
+	return result, nil
+}
+
+// ramPixel returns the display x coordinate of the first pixel at the current address
+// Column addresses are internal 4-pixel columns, with display x=0 at the column offset
+// (0x1C on a 256px panel); ok is false when the address is off the display
+func (ssd *SSD1322) ramPixel() (int, bool) {
+	x := (ssd.currentColumn-ssd.config.ColumnOffset)*ssd1322ColumnPixels + ssd.columnByte*2
+	if x < 0 || x >= ssd.Width() || ssd.currentRow < 0 || ssd.currentRow >= ssd.Height() {
+		return 0, false
+	}
+
+	return x, true
+}
+
+// advanceAddress moves the write cursor one data byte according to the address increment mode
+// The column or row only advances after both bytes of a column address
+func (ssd *SSD1322) advanceAddress() {
+	ssd.columnByte++
+	if ssd.columnByte < 2 {
+		return
+	}
+	ssd.columnByte = 0
+
+	if ssd.remapSettings&RemapVerticalIncrement != 0 {
+		// Vertical increment: fill the column top to bottom before moving right
+		ssd.currentRow++
+		if ssd.currentRow > ssd.rowEnd {
+			ssd.currentRow = ssd.rowStart
+			ssd.currentColumn++
+			if ssd.currentColumn > ssd.columnEnd {
+				ssd.currentColumn = ssd.columnStart
+			}
+		}
+		return
+	}
+
+	ssd.currentColumn++
+	if ssd.currentColumn > ssd.columnEnd {
+		ssd.currentColumn = ssd.columnStart
+		ssd.currentRow++
+		if ssd.currentRow > ssd.rowEnd {
+			ssd.currentRow = ssd.rowStart
+		}
+	}
+}
+
+// setupScroll configures horizontal scrolling without starting it; CmdActivateScroll starts it
+// Data: start row, reserved, speed (frames per step), end row (inclusive), offset (columns per step)
+func (ssd *SSD1322) setupScroll(data []byte) error {
+	if len(data) < 5 {
+		return nil
+	}
+
+	startRow := int(data[0])
+	endRow := min(int(data[3]), ssd.Height()-1)
+	if endRow < startRow {
+		return fmt.Errorf("scroll end row %d is before start row %d", data[3], startRow)
+	}
+
+	ssd.scrollStartRow = startRow
+	ssd.scrollEndRow = endRow
+	ssd.scrollInterval = max(int(data[2]), 1)
+	ssd.scrollStep = max(int(data[4]), 1)
 	return nil
 }
 
+// StepScroll shifts the scroll rows right by the configured step, wrapping at the display edge
+// The step counts 4-pixel columns, like every other SSD1322 column address.
+// It does nothing while scrolling is deactivated, so the display keeps its current offset
+func (ssd *SSD1322) StepScroll() {
+	if !ssd.scrollEnabled {
+		return
+	}
+
+	width := ssd.Width()
+	step := ssd.scrollStep * ssd1322ColumnPixels % width
+	if step == 0 {
+		return
+	}
+
+	startRow := ssd.scrollStartRow
+	endRow := ssd.scrollEndRow
+	if endRow >= ssd.Height() {
+		endRow = ssd.Height() - 1
+	}
+
+	row := make([]byte, width)
+	for y := startRow; y <= endRow; y++ {
+		for x := 0; x < width; x++ {
+			row[x], _ = ssd.memory.GetPixelNibble(ssd.vram, x, y)
+		}
+		for x := 0; x < width; x++ {
+			ssd.memory.SetPixelNibble(ssd.vram, (x+step)%width, y, row[x])
+		}
+	}
+
+	if startRow <= endRow {
+		ssd.MarkDirty(0, startRow, width-1, endRow)
+	}
+}
+
+// IsScrolling returns whether horizontal scrolling is active
+func (ssd *SSD1322) IsScrolling() bool {
+	return ssd.scrollEnabled
+}
+
+// ScrollInterval returns the number of frames between scroll steps
+func (ssd *SSD1322) ScrollInterval() int {
+	return ssd.scrollInterval
+}
+
 // SetPixel implements the Device interface
 func (ssd *SSD1322) SetPixel(x, y int, color byte) error {
 	if x < 0 || x >= ssd.Width() || y < 0 || y >= ssd.Height() {
@@ -339,6 +598,43 @@ func (ssd *SSD1322) GetPixel(x, y int) (byte, error) {
 	return ssd.memory.GetPixelNibble(ssd.vram, x, y)
 }
 
+// GetDisplayPixel reads the pixel shown at screen coordinates (x, y)
+// The row is mapped through the display start line and offset, wrapping
+// modulo the multiplex ratio like the hardware COM scan does
+func (ssd *SSD1322) GetDisplayPixel(x, y int) (byte, error) {
+	if x < 0 || x >= ssd.Width() || y < 0 || y >= ssd.Height() {
+		return 0, fmt.Errorf("pixel out of bounds: (%d, %d)", x, y)
+	}
+
+	mux := int(ssd.multiplexRatio) + 1
+	if y >= mux {
+		// Rows beyond the multiplex ratio are not driven
+		return 0, nil
+	}
+
+	row := (y + ssd.startLine + ssd.displayOffset) % mux
+	if row >= ssd.Height() {
+		return 0, nil
+	}
+
+	return ssd.memory.GetPixelNibble(ssd.vram, x, row)
+}
+
+// GetStartLine returns the display start line
+func (ssd *SSD1322) GetStartLine() int {
+	return ssd.startLine
+}
+
+// GetDisplayOffset returns the vertical display offset
+func (ssd *SSD1322) GetDisplayOffset() int {
+	return ssd.displayOffset
+}
+
+// GetMultiplexRatio returns the number of rows driven by the COM scan
+func (ssd *SSD1322) GetMultiplexRatio() int {
+	return int(ssd.multiplexRatio) + 1
+}
+
 // Reset performs a hardware reset
 func (ssd *SSD1322) Reset() error {
 	// Clear VRAM
@@ -350,23 +646,151 @@ func (ssd *SSD1322) Reset() error {
 	ssd.commandLocked = true
 	ssd.displayOn = false
 	ssd.dataMode = false
+	ssd.readMode = false
+	ssd.readDummyPending = false
 	ssd.contrastLevel = 0x7F
 	ssd.masterCurrentLevel = 0x0F
 	ssd.invertDisplay = false
-	ssd.columnStart = 0
-	ssd.columnEnd = ssd.Width() - 1
+	ssd.displayAllOn = false
+	ssd.columnStart = ssd.config.ColumnOffset
+	ssd.columnEnd = ssd.config.ColumnOffset + (ssd.Width()+ssd1322ColumnPixels-1)/ssd1322ColumnPixels - 1
 	ssd.rowStart = 0
 	ssd.rowEnd = ssd.Height() - 1
-	ssd.currentColumn = 0
+	ssd.currentColumn = ssd.columnStart
 	ssd.currentRow = 0
+	ssd.columnByte = 0
 	ssd.scrollEnabled = false
+	ssd.scrollStep = 1
+	ssd.scrollStartRow = 0
+	ssd.scrollEndRow = ssd.Height() - 1
+	ssd.scrollInterval = 1
 	ssd.startLine = 0
 	ssd.displayOffset = 0
+	ssd.grayscaleTableMode = 0
+	ssd.grayscaleTable = defaultGrayscaleTable()
 
 	ssd.MarkDirty(0, 0, ssd.Width()-1, ssd.Height()-1)
 	return nil
 }
 
+// vramDumpMagic starts every DumpVRAM snapshot
+const vramDumpMagic = "VRAM"
+
+// vramDumpHeaderSize is the magic, big-endian 16-bit width and height, and the pixel format byte
+const vramDumpHeaderSize = len(vramDumpMagic) + 5
+
+// DumpVRAM returns the VRAM in its native nibble packing, after a header with the
+// width, height and pixel format so LoadVRAM can reject snapshots from another display
+func (ssd *SSD1322) DumpVRAM() []byte {
+	dump := make([]byte, vramDumpHeaderSize, vramDumpHeaderSize+len(ssd.vram))
+	copy(dump, vramDumpMagic)
+	binary.BigEndian.PutUint16(dump[4:], uint16(ssd.Width()))
+	binary.BigEndian.PutUint16(dump[6:], uint16(ssd.Height()))
+	dump[8] = byte(ssd.PixelFormat())
+
+	return append(dump, ssd.vram...)
+}
+
+// LoadVRAM restores a snapshot taken with DumpVRAM and marks the whole display dirty
+// Only VRAM is restored; addressing and display settings are left unchanged
+func (ssd *SSD1322) LoadVRAM(dump []byte) error {
+	if len(dump) < vramDumpHeaderSize || string(dump[:4]) != vramDumpMagic {
+		return fmt.Errorf("invalid VRAM dump header")
+	}
+
+	width := int(binary.BigEndian.Uint16(dump[4:]))
+	height := int(binary.BigEndian.Uint16(dump[6:]))
+	if width != ssd.Width() || height != ssd.Height() {
+		return fmt.Errorf("VRAM dump is %dx%d, display is %dx%d", width, height, ssd.Width(), ssd.Height())
+	}
+
+	if format := PixelFormat(dump[8]); format != ssd.PixelFormat() {
+		return fmt.Errorf("VRAM dump pixel format %d does not match display format %d", format, ssd.PixelFormat())
+	}
+
+	if data := dump[vramDumpHeaderSize:]; len(data) != len(ssd.vram) {
+		return fmt.Errorf("VRAM dump has %d bytes, expected %d", len(data), len(ssd.vram))
+	}
+
+	copy(ssd.vram, dump[vramDumpHeaderSize:])
+	ssd.MarkDirty(0, 0, ssd.Width()-1, ssd.Height()-1)
+	return nil
+}
+
+// SetStrictMode makes ProcessCommand reject known commands given fewer data bytes than they take
+// Off by default, so short commands are ignored as before
+func (ssd *SSD1322) SetStrictMode(strict bool) {
+	ssd.strictMode = strict
+}
+
+// IsStrictMode returns whether data byte counts are validated
+func (ssd *SSD1322) IsStrictMode() bool {
+	return ssd.strictMode
+}
+
+// SetCommandLogger registers fn to be called with every command before it is processed
+// Pass nil to disable logging. protocol.FormatCommand renders a command for display
+func (ssd *SSD1322) SetCommandLogger(fn func(cmd byte, data []byte)) {
+	ssd.commandLogger = fn
+}
+
+// SetCommandHistorySize keeps the last n processed commands for GetCommandHistory
+// A size of 0 disables the history and discards recorded commands
+func (ssd *SSD1322) SetCommandHistorySize(n int) {
+	ssd.historySize = max(n, 0)
+	ssd.history = nil
+	ssd.historyNext = 0
+}
+
+// GetCommandHistory returns the recorded commands, oldest first
+func (ssd *SSD1322) GetCommandHistory() []CommandRecord {
+	history := make([]CommandRecord, 0, len(ssd.history))
+	if len(ssd.history) == ssd.historySize {
+		history = append(history, ssd.history[ssd.historyNext:]...)
+		return append(history, ssd.history[:ssd.historyNext]...)
+	}
+
+	return append(history, ssd.history...)
+}
+
+// recordCommand appends a command to the history ring buffer, copying its data
+func (ssd *SSD1322) recordCommand(cmd byte, data []byte) {
+	record := CommandRecord{Code: cmd, Data: append([]byte(nil), data...)}
+
+	if len(ssd.history) < ssd.historySize {
+		ssd.history = append(ssd.history, record)
+		return
+	}
+
+	ssd.history[ssd.historyNext] = record
+	ssd.historyNext = (ssd.historyNext + 1) % ssd.historySize
+}
+
+// SetGrayscaleTable loads a custom grayscale table. The table holds the
+// brightness of levels 1 through 15; level 0 is always off.
+func (ssd *SSD1322) SetGrayscaleTable(table []byte) error {
+	if len(table) != GrayscaleTableSize {
+		return fmt.Errorf("grayscale table needs %d entries, got %d", GrayscaleTableSize, len(table))
+	}
+	for i, v := range table {
+		if v > GrayscaleMax {
+			return fmt.Errorf("grayscale table entry %d out of range: %d", i+1, v)
+		}
+	}
+
+	copy(ssd.grayscaleTable[:], table)
+	ssd.grayscaleTableMode = 1
+	ssd.MarkDirty(0, 0, ssd.Width()-1, ssd.Height()-1)
+	return nil
+}
+
+// GetGrayscaleTable returns a copy of the active grayscale table
+func (ssd *SSD1322) GetGrayscaleTable() []byte {
+	table := make([]byte, GrayscaleTableSize)
+	copy(table, ssd.grayscaleTable[:])
+	return table
+}
+
 // IsDisplayOn returns whether the display is powered on
 func (ssd *SSD1322) IsDisplayOn() bool {
 	return ssd.displayOn
@@ -377,7 +801,97 @@ func (ssd *SSD1322) GetContrastLevel() byte {
 	return ssd.contrastLevel
 }
 
+// GetMasterCurrent returns the master current level (0-15)
+func (ssd *SSD1322) GetMasterCurrent() byte {
+	return ssd.masterCurrentLevel
+}
+
+// SetPixelCurrent sets the per-pixel coefficient used by EstimatePowerMilliamps, in mA at full brightness
+// Calibrate it against a measurement of the real panel for better estimates
+func (ssd *SSD1322) SetPixelCurrent(milliamps float64) error {
+	if milliamps < 0 {
+		return fmt.Errorf("invalid pixel current: %f", milliamps)
+	}
+
+	ssd.pixelCurrent = milliamps
+	return nil
+}
+
+// GetPixelCurrent returns the per-pixel coefficient used by EstimatePowerMilliamps
+func (ssd *SSD1322) GetPixelCurrent() float64 {
+	return ssd.pixelCurrent
+}
+
+// EstimatePowerMilliamps returns a rough estimate of the panel current for the displayed image
+// Each lit pixel draws the pixel current scaled by its grayscale table value, the contrast and the master current
+// Inversion, entire-display-on and row mapping are applied; the display off draws nothing
+// This is an estimate for comparing UI designs, not a substitute for measuring the hardware
+func (ssd *SSD1322) EstimatePowerMilliamps() float64 {
+	if !ssd.displayOn {
+		return 0
+	}
+
+	// Rows beyond the multiplex ratio are not driven, even when inverted
+	rows := min(ssd.Height(), int(ssd.multiplexRatio)+1)
+
+	var lit float64
+	for y := 0; y < rows; y++ {
+		for x := 0; x < ssd.Width(); x++ {
+			level, err := ssd.GetDisplayPixel(x, y)
+			if err != nil {
+				continue
+			}
+
+			switch {
+			case ssd.displayAllOn:
+				level = 0x0F
+			case ssd.invertDisplay:
+				level = 0x0F - level
+			}
+
+			if level > 0 {
+				lit += float64(ssd.grayscaleTable[level-1]) / GrayscaleMax
+			}
+		}
+	}
+
+	scale := float64(ssd.contrastLevel) / 255 * float64(ssd.masterCurrentLevel&0x0F) / 15
+	return lit * scale * ssd.pixelCurrent
+}
+
+// State implements the Inspectable interface
+func (ssd *SSD1322) State() DeviceState {
+	return DeviceState{
+		DisplayOn:     ssd.displayOn,
+		Contrast:      ssd.contrastLevel,
+		MasterCurrent: ssd.masterCurrentLevel,
+		Inverted:      ssd.invertDisplay,
+		AllOn:         ssd.displayAllOn,
+		CommandLocked: ssd.commandLocked,
+		Scrolling:     ssd.scrollEnabled,
+		StartLine:     ssd.startLine,
+		DisplayOffset: ssd.displayOffset,
+		Remap:         ssd.remapSettings,
+		ColumnStart:   ssd.columnStart,
+		ColumnEnd:     ssd.columnEnd,
+		RowStart:      ssd.rowStart,
+		RowEnd:        ssd.rowEnd,
+		Column:        ssd.currentColumn,
+		Row:           ssd.currentRow,
+	}
+}
+
+// IsCommandLocked returns whether the command lock (0xFD) is blocking most commands
+func (ssd *SSD1322) IsCommandLocked() bool {
+	return ssd.commandLocked
+}
+
 // IsInverted returns whether display is inverted
 func (ssd *SSD1322) IsInverted() bool {
 	return ssd.invertDisplay
 }
+
+// IsAllOn returns whether the entire display is forced on (0xA5)
+func (ssd *SSD1322) IsAllOn() bool {
+	return ssd.displayAllOn
+}
@@ -0,0 +1,326 @@
+package device
+
+import (
+	"fmt"
+)
+
+// SSD1351 command codes
+const (
+	SSD1351CmdSetColumnAddress  = 0x15 // Set column address
+	SSD1351CmdSetRowAddress     = 0x75 // Set row address
+	SSD1351CmdWriteRAM          = 0x5C // Write RAM
+	SSD1351CmdReadRAM           = 0x5D // Read RAM
+	SSD1351CmdSetRemap          = 0xA0 // Set remap and color depth
+	SSD1351CmdSetStartLine      = 0xA1 // Set display start line
+	SSD1351CmdDisplayOffset     = 0xA2 // Set display offset
+	SSD1351CmdEntireDisplayOn   = 0xA5 // Entire display ON
+	SSD1351CmdNormalDisplay     = 0xA6 // Normal display
+	SSD1351CmdInverseDisplay    = 0xA7 // Inverse display
+	SSD1351CmdSleepMode         = 0xAE // Sleep mode (display OFF)
+	SSD1351CmdNormalMode        = 0xAF // Normal mode (display ON)
+	SSD1351CmdSetContrastABC    = 0xC1 // Set contrast for color A, B, C
+	SSD1351CmdMasterContrast    = 0xC7 // Master contrast current control
+	SSD1351CmdSetMultiplexRatio = 0xCA // Set MUX ratio
+	SSD1351CmdCommandLock       = 0xFD // Set command lock
+)
+
+// RGBDevice is implemented by devices that store full color pixels
+type RGBDevice interface {
+	Device
+
+	// SetPixelRGB sets a pixel to a 24-bit color
+	SetPixelRGB(x, y int, r, g, b byte) error
+
+	// GetPixelRGB reads a pixel as a 24-bit color
+	GetPixelRGB(x, y int) (byte, byte, byte, error)
+}
+
+// SSD1351 display controller emulation (RGB color, stored as RGB888)
+type SSD1351 struct {
+	*BaseDevice
+	memory             *MemoryHelper
+	commandLocked      bool
+	displayOn          bool
+	dataMode           bool
+	contrast           [3]byte // Per-channel contrast for color A, B, C
+	masterCurrentLevel byte
+	invertDisplay      bool
+	displayAllOn       bool
+	columnStart        int
+	columnEnd          int
+	rowStart           int
+	rowEnd             int
+	currentColumn      int
+	currentRow         int
+	startLine          int
+	displayOffset      int
+	multiplexRatio     byte
+	remapSettings      byte
+	pendingByte        int // First byte of an RGB565 pixel, -1 if none
+}
+
+// NewSSD1351 creates a new SSD1351 device
+func NewSSD1351(width, height int) *SSD1351 {
+	config := Config{
+		Width:       width,
+		Height:      height,
+		ColorDepth:  24,
+		PixelFormat: RGB888,
+	}
+
+	ssd1351 := &SSD1351{
+		BaseDevice: NewBaseDevice(config),
+		memory:     NewMemoryHelper(width, height, RGB888, 0),
+	}
+	ssd1351.resetState()
+
+	return ssd1351
+}
+
+// ProcessCommand handles SSD1351 commands
+func (ssd *SSD1351) ProcessCommand(cmd byte, data []byte) error {
+	// Any command ends a pending RAM write
+	if cmd != SSD1351CmdWriteRAM {
+		ssd.dataMode = false
+		ssd.pendingByte = -1
+	}
+
+	switch cmd {
+	case SSD1351CmdCommandLock:
+		if len(data) > 0 {
+			if data[0] == 0x12 {
+				ssd.commandLocked = false
+			} else if data[0] == 0x16 {
+				ssd.commandLocked = true
+			}
+		}
+		return nil
+
+	case SSD1351CmdNormalMode:
+		ssd.displayOn = true
+		return nil
+
+	case SSD1351CmdSleepMode:
+		ssd.displayOn = false
+		return nil
+
+	case SSD1351CmdWriteRAM:
+		ssd.dataMode = true
+		ssd.pendingByte = -1
+		return nil
+
+	case SSD1351CmdSetColumnAddress:
+		if len(data) >= 2 {
+			ssd.columnStart = int(data[0])
+			ssd.columnEnd = int(data[1])
+			ssd.currentColumn = ssd.columnStart
+		}
+		return nil
+
+	case SSD1351CmdSetRowAddress:
+		if len(data) >= 2 {
+			ssd.rowStart = int(data[0])
+			ssd.rowEnd = int(data[1])
+			ssd.currentRow = ssd.rowStart
+		}
+		return nil
+
+	case SSD1351CmdSetContrastABC:
+		for i := 0; i < len(data) && i < 3; i++ {
+			ssd.contrast[i] = data[i]
+		}
+		return nil
+
+	case SSD1351CmdMasterContrast:
+		if len(data) > 0 {
+			ssd.masterCurrentLevel = data[0] & 0x0F
+		}
+		return nil
+
+	case SSD1351CmdEntireDisplayOn:
+		ssd.displayAllOn = true
+		return nil
+
+	case SSD1351CmdNormalDisplay:
+		ssd.displayAllOn = false
+		ssd.invertDisplay = false
+		return nil
+
+	case SSD1351CmdInverseDisplay:
+		ssd.displayAllOn = false
+		ssd.invertDisplay = true
+		return nil
+
+	case SSD1351CmdSetRemap:
+		if len(data) > 0 {
+			ssd.remapSettings = data[0]
+		}
+		return nil
+
+	case SSD1351CmdSetStartLine:
+		if len(data) > 0 {
+			ssd.startLine = int(data[0] & 0x7F)
+		}
+		return nil
+
+	case SSD1351CmdDisplayOffset:
+		if len(data) > 0 {
+			ssd.displayOffset = int(data[0] & 0x7F)
+		}
+		return nil
+
+	case SSD1351CmdSetMultiplexRatio:
+		if len(data) > 0 {
+			ssd.multiplexRatio = data[0]
+		}
+		return nil
+
+	default:
+		// Unknown command - silently ignore
+		return nil
+	}
+}
+
+// WriteData writes RGB565 pixel data (2 bytes per pixel, big-endian) at the current address
+func (ssd *SSD1351) WriteData(data []byte) error {
+	if !ssd.dataMode {
+		return fmt.Errorf("not in data write mode")
+	}
+
+	for _, byteVal := range data {
+		if ssd.pendingByte < 0 {
+			ssd.pendingByte = int(byteVal)
+			continue
+		}
+
+		pixel := uint16(ssd.pendingByte)<<8 | uint16(byteVal)
+		ssd.pendingByte = -1
+
+		// Expand 5-6-5 bits to 8 bits per channel
+		r := byte(pixel>>11) & 0x1F
+		g := byte(pixel>>5) & 0x3F
+		b := byte(pixel) & 0x1F
+
+		col := ssd.currentColumn
+		row := ssd.currentRow
+		if col >= 0 && col < ssd.Width() && row >= 0 && row < ssd.Height() {
+			ssd.memory.SetPixelRGB888(ssd.vram, col, row, r<<3|r>>2, g<<2|g>>4, b<<3|b>>2)
+			ssd.MarkDirty(col, row, col, row)
+		}
+
+		ssd.currentColumn++
+		if ssd.currentColumn > ssd.columnEnd {
+			ssd.currentColumn = ssd.columnStart
+			ssd.currentRow++
+			if ssd.currentRow > ssd.rowEnd {
+				ssd.currentRow = ssd.rowStart
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetPixel implements the Device interface
+// The 4-bit level is expanded to an equivalent gray color
+func (ssd *SSD1351) SetPixel(x, y int, color byte) error {
+	level := (color & 0x0F) * 17
+	return ssd.SetPixelRGB(x, y, level, level, level)
+}
+
+// GetPixel implements the Device interface
+// Returns the 4-bit grayscale level of the stored color
+func (ssd *SSD1351) GetPixel(x, y int) (byte, error) {
+	r, g, b, err := ssd.GetPixelRGB(x, y)
+	if err != nil {
+		return 0, err
+	}
+
+	gray := (uint32(r)*77 + uint32(g)*150 + uint32(b)*29) / 256
+	return byte(gray >> 4), nil
+}
+
+// SetPixelRGB sets a pixel to a 24-bit color
+func (ssd *SSD1351) SetPixelRGB(x, y int, r, g, b byte) error {
+	if err := ssd.memory.SetPixelRGB888(ssd.vram, x, y, r, g, b); err != nil {
+		return err
+	}
+
+	ssd.MarkDirty(x, y, x, y)
+	return nil
+}
+
+// GetPixelRGB reads a pixel as a 24-bit color
+func (ssd *SSD1351) GetPixelRGB(x, y int) (byte, byte, byte, error) {
+	return ssd.memory.GetPixelRGB888(ssd.vram, x, y)
+}
+
+// Reset performs a hardware reset
+func (ssd *SSD1351) Reset() error {
+	// Clear VRAM
+	for i := range ssd.vram {
+		ssd.vram[i] = 0
+	}
+
+	ssd.resetState()
+
+	ssd.MarkDirty(0, 0, ssd.Width()-1, ssd.Height()-1)
+	return nil
+}
+
+// resetState restores all settings to their power-on defaults
+func (ssd *SSD1351) resetState() {
+	ssd.commandLocked = true
+	ssd.displayOn = false
+	ssd.dataMode = false
+	ssd.contrast = [3]byte{0xFF, 0xFF, 0xFF}
+	ssd.masterCurrentLevel = 0x0F
+	ssd.invertDisplay = false
+	ssd.displayAllOn = false
+	ssd.columnStart = 0
+	ssd.columnEnd = ssd.Width() - 1
+	ssd.rowStart = 0
+	ssd.rowEnd = ssd.Height() - 1
+	ssd.currentColumn = 0
+	ssd.currentRow = 0
+	ssd.startLine = 0
+	ssd.displayOffset = 0
+	ssd.multiplexRatio = 0x7F
+	ssd.remapSettings = 0x74
+	ssd.pendingByte = -1
+}
+
+// IsDisplayOn returns whether the display is powered on
+func (ssd *SSD1351) IsDisplayOn() bool {
+	return ssd.displayOn
+}
+
+// GetContrastLevel returns the contrast of color A
+func (ssd *SSD1351) GetContrastLevel() byte {
+	return ssd.contrast[0]
+}
+
+// GetContrastABC returns the per-channel contrast for color A, B and C
+func (ssd *SSD1351) GetContrastABC() (byte, byte, byte) {
+	return ssd.contrast[0], ssd.contrast[1], ssd.contrast[2]
+}
+
+// GetMasterCurrent returns the master current level (0-15)
+func (ssd *SSD1351) GetMasterCurrent() byte {
+	return ssd.masterCurrentLevel
+}
+
+// IsCommandLocked returns whether the command lock (0xFD) is blocking most commands
+func (ssd *SSD1351) IsCommandLocked() bool {
+	return ssd.commandLocked
+}
+
+// IsInverted returns whether display is inverted
+func (ssd *SSD1351) IsInverted() bool {
+	return ssd.invertDisplay
+}
+
+// IsAllOn returns whether the entire display is forced on (0xA5)
+func (ssd *SSD1351) IsAllOn() bool {
+	return ssd.displayAllOn
+}
@@ -0,0 +1,92 @@
+package emulator
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// ButtonState holds the virtual device buttons that fired during an update
+// Arrow keys map to the directions, Enter to Select and Escape to Back
+type ButtonState struct {
+	Up     bool
+	Down   bool
+	Left   bool
+	Right  bool
+	Select bool
+	Back   bool
+}
+
+// Any returns whether at least one button fired
+func (bs ButtonState) Any() bool {
+	return bs.Up || bs.Down || bs.Left || bs.Right || bs.Select || bs.Back
+}
+
+// OnKeyPress registers fn to be called when key is pressed
+// Registering a nil fn removes the callback for key
+func (e *Emulator) OnKeyPress(key ebiten.Key, fn func()) {
+	if fn == nil {
+		delete(e.keyHandlers, key)
+		return
+	}
+
+	if e.keyHandlers == nil {
+		e.keyHandlers = make(map[ebiten.Key]func())
+	}
+	e.keyHandlers[key] = fn
+}
+
+// SetButtonHandler registers fn to receive virtual button presses
+func (e *Emulator) SetButtonHandler(fn func(buttons ButtonState)) {
+	e.buttonHandler = fn
+}
+
+// SetKeyRepeat makes held keys fire again after delay ticks, then every interval ticks
+// A delay or interval of 0 disables repeat, so a held key fires once per press (the default)
+func (e *Emulator) SetKeyRepeat(delay, interval int) {
+	e.keyRepeatDelay = delay
+	e.keyRepeatInterval = interval
+}
+
+// handleInput polls the keyboard and invokes the registered callbacks
+func (e *Emulator) handleInput() {
+	for key, fn := range e.keyHandlers {
+		if e.keyFired(key) {
+			fn()
+		}
+	}
+
+	if e.buttonHandler == nil {
+		return
+	}
+
+	buttons := ButtonState{
+		Up:     e.keyFired(ebiten.KeyArrowUp),
+		Down:   e.keyFired(ebiten.KeyArrowDown),
+		Left:   e.keyFired(ebiten.KeyArrowLeft),
+		Right:  e.keyFired(ebiten.KeyArrowRight),
+		Select: e.keyFired(ebiten.KeyEnter),
+		Back:   e.keyFired(ebiten.KeyEscape),
+	}
+	if buttons.Any() {
+		e.buttonHandler(buttons)
+	}
+}
+
+// keyFired returns whether key triggers during this update
+func (e *Emulator) keyFired(key ebiten.Key) bool {
+	return keyTriggers(inpututil.KeyPressDuration(key), e.keyRepeatDelay, e.keyRepeatInterval)
+}
+
+// keyTriggers decides whether a key held for duration ticks fires
+// It fires on the first tick of a press and, with repeat enabled, every interval ticks after delay
+func keyTriggers(duration, delay, interval int) bool {
+	if duration == 1 {
+		return true
+	}
+
+	if delay <= 0 || interval <= 0 || duration <= delay {
+		return false
+	}
+
+	return (duration-delay)%interval == 0
+}
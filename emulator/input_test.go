@@ -0,0 +1,36 @@
+package emulator
+
+import "testing"
+
+func TestKeyTriggers(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration int
+		delay    int
+		interval int
+		expected bool
+	}{
+		{"not pressed", 0, 0, 0, false},
+		{"first tick", 1, 0, 0, true},
+		{"held without repeat", 30, 0, 0, false},
+		{"held before repeat delay", 20, 20, 5, false},
+		{"first repeat", 25, 20, 5, true},
+		{"between repeats", 27, 20, 5, false},
+		{"second repeat", 30, 20, 5, true},
+	}
+
+	for _, test := range tests {
+		if got := keyTriggers(test.duration, test.delay, test.interval); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestButtonStateAny(t *testing.T) {
+	if (ButtonState{}).Any() {
+		t.Error("empty button state should report no buttons")
+	}
+	if !(ButtonState{Select: true}).Any() {
+		t.Error("button state with Select should report a button")
+	}
+}
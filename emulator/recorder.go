@@ -0,0 +1,115 @@
+package emulator
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"os"
+)
+
+// gifRecorder collects rendered frames and writes them as a looping GIF
+type gifRecorder struct {
+	path      string
+	delay     int // Frame delay in 1/100s
+	interval  int // Emulator ticks between captured frames
+	maxFrames int // 0 = unlimited
+	frames    []*image.Paletted
+}
+
+// StartRecording begins capturing the display to an animated GIF at path
+// Frames are captured at fps (bounded by the emulator update rate) and written by StopRecording
+func (e *Emulator) StartRecording(path string, fps int) error {
+	if e.recorder != nil {
+		return fmt.Errorf("recording already in progress")
+	}
+
+	if fps <= 0 {
+		return fmt.Errorf("invalid recording frame rate: %d", fps)
+	}
+
+	interval := e.updateRate / fps
+	if interval < 1 {
+		interval = 1
+	}
+
+	e.recorder = &gifRecorder{
+		path:      path,
+		delay:     (100*interval + e.updateRate/2) / e.updateRate,
+		interval:  interval,
+		maxFrames: e.maxRecordingFrames,
+	}
+
+	return nil
+}
+
+// StopRecording ends the current recording and writes the GIF file
+func (e *Emulator) StopRecording() error {
+	if e.recorder == nil {
+		return fmt.Errorf("no recording in progress")
+	}
+
+	rec := e.recorder
+	e.recorder = nil
+
+	return rec.save()
+}
+
+// IsRecording returns whether a GIF recording is in progress
+func (e *Emulator) IsRecording() bool {
+	return e.recorder != nil
+}
+
+// SetMaxRecordingFrames bounds the number of frames kept in memory while recording
+// Frames past the limit are dropped; 0 means unlimited
+func (e *Emulator) SetMaxRecordingFrames(n int) {
+	if n < 0 {
+		n = 0
+	}
+
+	e.maxRecordingFrames = n
+	if e.recorder != nil {
+		e.recorder.maxFrames = n
+	}
+}
+
+// captureFrame adds the current display to the recording if a frame is due
+func (e *Emulator) captureFrame() {
+	rec := e.recorder
+	if rec == nil || e.frameCount%rec.interval != 0 {
+		return
+	}
+
+	if rec.maxFrames > 0 && len(rec.frames) >= rec.maxFrames {
+		return
+	}
+
+	rec.frames = append(rec.frames, e.renderer.RenderToPaletted())
+}
+
+// save encodes the captured frames as a looping GIF
+func (rec *gifRecorder) save() error {
+	if len(rec.frames) == 0 {
+		return fmt.Errorf("no frames recorded")
+	}
+
+	anim := &gif.GIF{
+		Image:     rec.frames,
+		Delay:     make([]int, len(rec.frames)),
+		LoopCount: 0, // Loop forever
+	}
+	for i := range anim.Delay {
+		anim.Delay[i] = rec.delay
+	}
+
+	file, err := os.Create(rec.path)
+	if err != nil {
+		return fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	if err := gif.EncodeAll(file, anim); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+
+	return file.Close()
+}
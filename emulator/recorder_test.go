@@ -0,0 +1,57 @@
+package emulator
+
+import (
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flavioheleno/oled-emulator/device"
+)
+
+func TestGIFRecording(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	emu := NewEmulator(dev, 2)
+	emu.SetMaxRecordingFrames(3)
+
+	path := filepath.Join(t.TempDir(), "anim.gif")
+
+	// 60 ticks per second recorded at 30 fps captures every other tick
+	if err := emu.StartRecording(path, 30); err != nil {
+		t.Fatalf("StartRecording failed: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		dev.SetPixel(i, 0, 0x0F)
+		emu.frameCount++
+		emu.captureFrame()
+	}
+
+	if err := emu.StopRecording(); err != nil {
+		t.Fatalf("StopRecording failed: %v", err)
+	}
+	if emu.IsRecording() {
+		t.Error("recording should have stopped")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer file.Close()
+
+	anim, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	if len(anim.Image) != 3 {
+		t.Errorf("expected 3 frames (max frame limit), got %d", len(anim.Image))
+	}
+	if anim.Delay[0] != 3 {
+		t.Errorf("expected 3/100s frame delay, got %d", anim.Delay[0])
+	}
+	if anim.Image[0].Bounds().Dx() != 512 {
+		t.Errorf("frames should respect the scale, got width %d", anim.Image[0].Bounds().Dx())
+	}
+}
@@ -0,0 +1,61 @@
+package emulator
+
+import (
+	"testing"
+
+	"github.com/flavioheleno/oled-emulator/device"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestRenderDirtyClearsRegion(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	vr := NewVRAMRenderer(dev, 1)
+	dev.ClearDirtyRegion()
+
+	dev.SetPixel(5, 6, 0x0F)
+
+	img := ebiten.NewImage(256, 64)
+	x0, y0, x1, y1 := vr.RenderDirty(img)
+	if x0 != 5 || y0 != 6 || x1 != 5 || y1 != 6 {
+		t.Errorf("expected rendered region (5,6)-(5,6), got (%d,%d)-(%d,%d)", x0, y0, x1, y1)
+	}
+
+	if dx0, _, _, _ := dev.GetDirtyRegion(); dx0 != -1 {
+		t.Error("device dirty region should be cleared after rendering")
+	}
+
+	if x0, _, _, _ := vr.RenderDirty(img); x0 != -1 {
+		t.Error("second render should have nothing to draw")
+	}
+}
+
+func benchmarkRender(b *testing.B, dirty bool) {
+	dev := device.NewSSD1322(256, 64)
+	vr := NewVRAMRenderer(dev, 4)
+	img := ebiten.NewImage(256*4, 64*4)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// A small rectangle changes each frame
+		for y := 10; y < 18; y++ {
+			for x := 100; x < 116; x++ {
+				dev.SetPixel(x, y, byte(i)&0x0F)
+			}
+		}
+
+		if dirty {
+			vr.RenderDirty(img)
+		} else {
+			img.WritePixels(vr.RenderToRGBA().Pix)
+			dev.ClearDirtyRegion()
+		}
+	}
+}
+
+func BenchmarkRenderFull(b *testing.B) {
+	benchmarkRender(b, false)
+}
+
+func BenchmarkRenderDirty(b *testing.B) {
+	benchmarkRender(b, true)
+}
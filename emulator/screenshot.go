@@ -0,0 +1,100 @@
+package emulator
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SaveScreenshot renders the current display and writes it to path
+// The format is chosen from the file extension (.png or .bmp)
+func (e *Emulator) SaveScreenshot(path string) error {
+	img := e.renderer.RenderToRGBA()
+
+	var encode func(io.Writer, image.Image) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		encode = png.Encode
+	case ".bmp":
+		encode = encodeBMP
+	default:
+		return fmt.Errorf("unsupported screenshot format: %s", path)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create screenshot: %w", err)
+	}
+
+	if err := encode(file, img); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to encode screenshot: %w", err)
+	}
+
+	return file.Close()
+}
+
+// takeScreenshot saves a screenshot to path for the F12 key, reporting a failure in a toast
+func (e *Emulator) takeScreenshot(path string) {
+	if err := e.SaveScreenshot(path); err != nil {
+		e.showToast(fmt.Sprintf("Screenshot failed: %v", err))
+	}
+}
+
+// screenshotName returns a timestamped screenshot file name
+func screenshotName(t time.Time) string {
+	return fmt.Sprintf("screenshot-%s.png", t.Format("20060102-150405.000"))
+}
+
+// encodeBMP writes img as an uncompressed 24-bit BMP
+func encodeBMP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+
+	// Rows are padded to a multiple of 4 bytes
+	rowSize := (width*3 + 3) &^ 3
+	imageSize := rowSize * height
+
+	const headerSize = 14 + 40
+
+	header := make([]byte, headerSize)
+	copy(header[0:2], "BM")
+	binary.LittleEndian.PutUint32(header[2:6], uint32(headerSize+imageSize))
+	binary.LittleEndian.PutUint32(header[10:14], headerSize)
+	binary.LittleEndian.PutUint32(header[14:18], 40)
+	binary.LittleEndian.PutUint32(header[18:22], uint32(width))
+	binary.LittleEndian.PutUint32(header[22:26], uint32(height))
+	binary.LittleEndian.PutUint16(header[26:28], 1)  // Planes
+	binary.LittleEndian.PutUint16(header[28:30], 24) // Bits per pixel
+	binary.LittleEndian.PutUint32(header[34:38], uint32(imageSize))
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+
+	// Pixel rows are stored bottom-up in BGR order
+	row := make([]byte, rowSize)
+	for y := bounds.Max.Y - 1; y >= bounds.Min.Y; y-- {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, y).RGBA()
+			row[x*3] = byte(b >> 8)
+			row[x*3+1] = byte(g >> 8)
+			row[x*3+2] = byte(r >> 8)
+		}
+
+		if _, err := bw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,59 @@
+package emulator
+
+import (
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flavioheleno/oled-emulator/device"
+)
+
+func TestSaveScreenshot(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	emu := NewEmulator(dev, 2)
+	dir := t.TempDir()
+
+	pngPath := filepath.Join(dir, "shot.png")
+	if err := emu.SaveScreenshot(pngPath); err != nil {
+		t.Fatalf("SaveScreenshot failed: %v", err)
+	}
+
+	file, err := os.Open(pngPath)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if img.Bounds().Dx() != 512 || img.Bounds().Dy() != 128 {
+		t.Errorf("expected 512x128 screenshot, got %v", img.Bounds())
+	}
+
+	bmpPath := filepath.Join(dir, "shot.bmp")
+	if err := emu.SaveScreenshot(bmpPath); err != nil {
+		t.Fatalf("SaveScreenshot BMP failed: %v", err)
+	}
+	data, _ := os.ReadFile(bmpPath)
+	// 54-byte header plus 512*3 byte rows for 128 rows
+	if len(data) != 54+512*3*128 || string(data[:2]) != "BM" {
+		t.Errorf("unexpected BMP file (%d bytes)", len(data))
+	}
+
+	if err := emu.SaveScreenshot(filepath.Join(dir, "shot.gif")); err == nil {
+		t.Error("expected error for unsupported extension")
+	}
+}
+
+func TestTakeScreenshotReportsFailure(t *testing.T) {
+	emu := NewEmulator(device.NewSSD1322(256, 64), 1)
+
+	emu.takeScreenshot(filepath.Join(t.TempDir(), "missing", "shot.png"))
+	if !strings.HasPrefix(emu.toast, "Screenshot failed:") || emu.toastTicks == 0 {
+		t.Errorf("expected a toast reporting the failure, got %q", emu.toast)
+	}
+}
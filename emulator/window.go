@@ -3,24 +3,81 @@ package emulator
 import (
 	"fmt"
 	"image/color"
+	"strings"
+	"time"
 
 	"github.com/flavioheleno/oled-emulator/device"
+	"github.com/flavioheleno/oled-emulator/graphics"
+	"github.com/flavioheleno/oled-emulator/render"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
+// scroller is implemented by devices that emulate hardware scrolling
+type scroller interface {
+	StepScroll()
+	IsScrolling() bool
+	ScrollInterval() int
+}
+
+// powerReporter is implemented by devices that can be switched off
+type powerReporter interface {
+	IsDisplayOn() bool
+}
+
+// lockReporter is implemented by devices with a command lock
+type lockReporter interface {
+	IsCommandLocked() bool
+}
+
 // Emulator represents the display emulator window
 type Emulator struct {
-	device          device.Device
-	renderer        *VRAMRenderer
-	screenImage     *ebiten.Image
-	scale           int
-	frameRate       int
-	windowTitle     string
-	backgroundColor color.Color
-	showDebugInfo   bool
-	frameCount      int
-	lastFPS         float64
+	device             device.Device
+	renderer           *VRAMRenderer
+	screenImage        *ebiten.Image
+	scale              int
+	updateRate         int // Update ticks per second
+	renderRate         int // Maximum renders per second, 0 = every display refresh
+	vsync              bool
+	nextRender         time.Time
+	renderCount        int
+	renderWindowStart  time.Time
+	windowTitle        string
+	backgroundColor    color.Color
+	showDebugInfo      bool
+	debugVerbose       bool
+	lastDirty          [4]int // Device dirty region at the start of the last render
+	frameCount         int
+	lastFPS            float64
+	recorder           *gifRecorder
+	maxRecordingFrames int // 0 = unlimited
+	keyHandlers        map[ebiten.Key]func()
+	buttonHandler      func(buttons ButtonState)
+	keyRepeatDelay     int // Ticks before a held key repeats, 0 = no repeat
+	keyRepeatInterval  int // Ticks between repeats
+	quitOnEscape       bool
+	onClose            func()
+	frameBuffer        *graphics.FrameBuffer
+	drawCallback       func(fb *graphics.FrameBuffer)
+	palettes           []namedPalette // Cycled with P, seeded with the presets on first use
+	paletteName        string         // Name of the current palette, "" for an unregistered one
+	toast              string
+	toastTicks         int // Updates left before the toast disappears
+	interactiveZoom    bool
+	zoom               int // Interactive zoom factor on top of scale, 0 or 1 = none
+	panX, panY         int // Top-left of the zoomed display in window pixels
+	dragging           bool
+	dragX, dragY       int // Cursor position at the previous drag update
+	showInspector      bool
+	inspectX, inspectY int  // Device pixel under the cursor
+	inspectOK          bool // Whether the cursor is over the display
+}
+
+// namedPalette is an entry in the palette cycle
+type namedPalette struct {
+	name    string
+	palette *Palette
 }
 
 // NewEmulator creates a new emulator window
@@ -29,11 +86,15 @@ func NewEmulator(dev device.Device, scale int) *Emulator {
 		device:          dev,
 		renderer:        NewVRAMRenderer(dev, scale),
 		scale:           scale,
-		frameRate:       60,
+		updateRate:      60,
+		vsync:           true,
 		windowTitle:     "OLED Display Emulator",
 		backgroundColor: color.RGBA{R: 20, G: 20, B: 20, A: 255},
 		showDebugInfo:   false,
 		frameCount:      0,
+		quitOnEscape:    true,
+		paletteName:     "grayscale",
+		lastDirty:       [4]int{-1, -1, -1, -1},
 	}
 }
 
@@ -42,10 +103,94 @@ func (e *Emulator) SetWindowTitle(title string) {
 	e.windowTitle = title
 }
 
-// SetFrameRate sets the target frame rate
-func (e *Emulator) SetFrameRate(fps int) {
-	e.frameRate = fps
-	ebiten.SetMaxTPS(fps)
+// SetFrameRate sets both the update rate and the render rate to fps
+// It returns the first error, leaving both rates unchanged when fps is invalid
+func (e *Emulator) SetFrameRate(fps int) error {
+	if err := e.SetUpdateRate(fps); err != nil {
+		return err
+	}
+
+	return e.SetRenderRate(fps)
+}
+
+// SetUpdateRate sets how many times per second Update runs, including the draw callback, input and recording
+func (e *Emulator) SetUpdateRate(tps int) error {
+	if tps <= 0 {
+		return fmt.Errorf("invalid update rate: %d", tps)
+	}
+
+	e.updateRate = tps
+	ebiten.SetTPS(tps)
+	return nil
+}
+
+// GetUpdateRate returns the target update rate in ticks per second
+func (e *Emulator) GetUpdateRate() int {
+	return e.updateRate
+}
+
+// SetRenderRate caps how many times per second the window is redrawn, 0 renders on every display refresh
+// Frames in between keep the previous image, which saves CPU and GPU in long-running demos
+func (e *Emulator) SetRenderRate(fps int) error {
+	if fps < 0 {
+		return fmt.Errorf("invalid render rate: %d", fps)
+	}
+
+	e.renderRate = fps
+	e.nextRender = time.Time{}
+	return nil
+}
+
+// GetRenderRate returns the render rate cap, 0 when uncapped
+func (e *Emulator) GetRenderRate() int {
+	return e.renderRate
+}
+
+// SetVsyncEnabled syncs rendering with the display refresh (enabled by default)
+// Disable it to render as fast as the render rate allows
+func (e *Emulator) SetVsyncEnabled(enabled bool) {
+	e.vsync = enabled
+	ebiten.SetVsyncEnabled(enabled)
+}
+
+// IsVsyncEnabled returns whether rendering is synced with the display refresh
+func (e *Emulator) IsVsyncEnabled() bool {
+	return e.vsync
+}
+
+// renderDue reports whether a frame should be rendered at now and schedules the next one
+func (e *Emulator) renderDue(now time.Time) bool {
+	if e.renderRate <= 0 {
+		return true
+	}
+
+	// Allow a quarter interval of jitter, so a refresh landing just early isn't skipped
+	interval := time.Second / time.Duration(e.renderRate)
+	if now.Add(interval / 4).Before(e.nextRender) {
+		return false
+	}
+
+	// Keep a steady cadence, but don't try to catch up after a stall
+	if e.nextRender.IsZero() || now.Sub(e.nextRender) > interval {
+		e.nextRender = now
+	}
+	e.nextRender = e.nextRender.Add(interval)
+
+	return true
+}
+
+// countRender updates the measured render rate once per second
+func (e *Emulator) countRender(now time.Time) {
+	if e.renderWindowStart.IsZero() {
+		e.renderWindowStart = now
+	}
+
+	e.renderCount++
+	if elapsed := now.Sub(e.renderWindowStart); elapsed >= time.Second {
+		e.lastFPS = float64(e.renderCount) / elapsed.Seconds()
+		e.renderCount = 0
+		e.renderWindowStart = now
+	}
 }
 
 // ShowDebugInfo enables/disables debug information display
@@ -53,6 +198,17 @@ func (e *Emulator) ShowDebugInfo(show bool) {
 	e.showDebugInfo = show
 }
 
+// SetDebugVerbose adds the device state (power, contrast, inversion, scrolling, command lock and dirty region) to the debug info
+// Only the state the device reports is shown
+func (e *Emulator) SetDebugVerbose(verbose bool) {
+	e.debugVerbose = verbose
+}
+
+// SetShowInspector enables/disables showing the position and level of the pixel under the mouse
+func (e *Emulator) SetShowInspector(show bool) {
+	e.showInspector = show
+}
+
 // SetBackgroundColor sets the background color
 func (e *Emulator) SetBackgroundColor(c color.Color) {
 	e.backgroundColor = c
@@ -62,36 +218,234 @@ func (e *Emulator) SetBackgroundColor(c color.Color) {
 // SetPalette sets a custom color palette
 func (e *Emulator) SetPalette(p *Palette) {
 	e.renderer.SetPalette(p)
+	e.paletteName = ""
+}
+
+// SetPalettePreset switches to a built-in palette by name (see render.PalettePresets)
+func (e *Emulator) SetPalettePreset(name string) error {
+	p, err := render.NewPalettePreset(name)
+	if err != nil {
+		return err
+	}
+
+	e.renderer.SetPalette(p)
+	e.paletteName = name
+	return nil
+}
+
+// RegisterPalette adds p to the palettes cycled with the P key, replacing any palette with the same name
+// The built-in presets are registered by default
+func (e *Emulator) RegisterPalette(name string, p *Palette) {
+	e.seedPalettes()
+
+	for i, entry := range e.palettes {
+		if entry.name == name {
+			e.palettes[i].palette = p
+			return
+		}
+	}
+
+	e.palettes = append(e.palettes, namedPalette{name: name, palette: p})
+}
+
+// CyclePalette switches to the next registered palette, shows its name briefly and returns it
+func (e *Emulator) CyclePalette() string {
+	e.seedPalettes()
+
+	next := 0
+	for i, entry := range e.palettes {
+		if entry.name == e.paletteName {
+			next = (i + 1) % len(e.palettes)
+			break
+		}
+	}
+
+	entry := e.palettes[next]
+	e.renderer.SetPalette(entry.palette)
+	e.paletteName = entry.name
+	e.showToast("Palette: " + entry.name)
+
+	return entry.name
+}
+
+// seedPalettes registers the built-in presets the first time the palette list is used
+func (e *Emulator) seedPalettes() {
+	if e.palettes != nil {
+		return
+	}
+
+	names := render.PalettePresets()
+	e.palettes = make([]namedPalette, 0, len(names))
+	for _, name := range names {
+		p, _ := render.NewPalettePreset(name)
+		e.palettes = append(e.palettes, namedPalette{name: name, palette: p})
+	}
+}
+
+// showToast displays message over the display for two seconds
+func (e *Emulator) showToast(message string) {
+	e.toast = message
+	e.toastTicks = 2 * e.updateRate
+}
+
+// SetPixelGap sets the background-colored gap drawn between pixels (must be smaller than the scale)
+func (e *Emulator) SetPixelGap(gap int) error {
+	return e.renderer.SetPixelGap(gap)
+}
+
+// SetDrawCallback registers fn to draw each frame while the window is running
+// fn is called once per Update tick on the game loop with a FrameBuffer bound to the device,
+// which is flushed afterwards, so per-frame drawing never races the renderer
+func (e *Emulator) SetDrawCallback(fn func(fb *graphics.FrameBuffer)) {
+	e.drawCallback = fn
+}
+
+// GetFrameBuffer returns the FrameBuffer passed to the draw callback
+func (e *Emulator) GetFrameBuffer() *graphics.FrameBuffer {
+	if e.frameBuffer == nil {
+		e.frameBuffer = graphics.NewFrameBuffer(e.device)
+	}
+
+	return e.frameBuffer
+}
+
+// SetQuitOnEscape enables or disables closing the emulator with ESC (enabled by default)
+// Disable it for kiosk-style apps or to use ESC as the Back button
+func (e *Emulator) SetQuitOnEscape(enabled bool) {
+	e.quitOnEscape = enabled
+}
+
+// SetOnClose registers fn to run once before the emulator shuts down (ESC or window close)
+func (e *Emulator) SetOnClose(fn func()) {
+	e.onClose = fn
+}
+
+// shutdown runs the close hook and tells ebiten to terminate the game loop
+func (e *Emulator) shutdown() error {
+	if e.onClose != nil {
+		e.onClose()
+	}
+
+	return ebiten.Termination
 }
 
 // Update implements the ebiten.Game Update method
 func (e *Emulator) Update() error {
+	if ebiten.IsWindowBeingClosed() {
+		return e.shutdown()
+	}
+
+	if e.quitOnEscape && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return e.shutdown()
+	}
+
 	e.frameCount++
 
-	// Update FPS calculation every 30 frames
-	if e.frameCount%30 == 0 {
-		e.lastFPS = ebiten.ActualFPS()
+	e.handleInput()
+
+	// F12 saves a timestamped screenshot to the working directory
+	if inpututil.IsKeyJustPressed(ebiten.KeyF12) {
+		e.takeScreenshot(screenshotName(time.Now()))
+	}
+
+	if e.interactiveZoom {
+		e.handleZoom()
+	}
+
+	if e.showInspector {
+		e.inspectX, e.inspectY, e.inspectOK = e.screenToDevice(ebiten.CursorPosition())
+	}
+
+	// P cycles through the registered palettes, unless the app handles P itself
+	if _, handled := e.keyHandlers[ebiten.KeyP]; !handled && inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		e.CyclePalette()
+	}
+
+	if e.toastTicks > 0 {
+		e.toastTicks--
+	}
+
+	e.stepScroll()
+
+	// Let the app draw this frame before it is captured and rendered
+	if e.drawCallback != nil {
+		fb := e.GetFrameBuffer()
+		e.drawCallback(fb)
+		if err := fb.Flush(); err != nil {
+			return err
+		}
 	}
 
+	e.captureFrame()
+
 	return nil
 }
 
+// stepScroll advances hardware scrolling at the configured interval
+// Scrolling rewrites VRAM, so it holds the framebuffer lock in case another goroutine draws
+func (e *Emulator) stepScroll() {
+	s, ok := e.device.(scroller)
+	if !ok || !s.IsScrolling() {
+		return
+	}
+
+	if interval := s.ScrollInterval(); interval <= 0 || e.frameCount%interval != 0 {
+		return
+	}
+
+	if e.frameBuffer != nil {
+		e.frameBuffer.Lock()
+		defer e.frameBuffer.Unlock()
+	}
+
+	s.StepScroll()
+}
+
 // Draw implements the ebiten.Game Draw method
 func (e *Emulator) Draw(screen *ebiten.Image) {
+	// Skip frames above the render rate; the screen keeps the last image
+	now := time.Now()
+	if !e.renderDue(now) {
+		return
+	}
+	e.countRender(now)
+
 	// Clear screen with background color
 	screen.Fill(e.backgroundColor)
 
-	// Render VRAM to image
-	e.screenImage = e.renderer.RenderFullScreen()
+	// Hold the framebuffer lock while reading VRAM, in case another goroutine draws
+	if e.frameBuffer != nil {
+		e.frameBuffer.Lock()
+		defer e.frameBuffer.Unlock()
+	}
+
+	// Rendering clears the dirty region, so remember it for the debug info
+	// Read it under the lock so it matches the VRAM being rendered
+	x0, y0, x1, y1 := e.device.GetDirtyRegion()
+	e.lastDirty = [4]int{x0, y0, x1, y1}
 
-	// Draw the display at (0, 0)
+	// Keep the rendered display between frames and repaint only what changed
+	// Fall back to a full render on the first frame or when display-wide settings change
+	if e.renderer.NeedsFullRender() || e.screenImage == nil {
+		e.screenImage = e.renderer.RenderFullScreen()
+		e.device.ClearDirtyRegion()
+	} else {
+		e.renderer.RenderDirty(e.screenImage)
+	}
+
+	// Draw the display at (0, 0), zoomed and panned when interactive zoom is on
 	op := &ebiten.DrawImageOptions{}
+	e.viewTransform(op)
 	screen.DrawImage(e.screenImage, op)
 
-	// Draw debug info if enabled
-	if e.showDebugInfo {
+	// Draw debug info and the inspector if enabled
+	if e.showDebugInfo || e.showInspector {
 		e.drawDebugInfo(screen)
 	}
+
+	if e.toastTicks > 0 {
+		ebitenutil.DebugPrintAt(screen, e.toast, 5, screen.Bounds().Dy()-20)
+	}
 }
 
 // Layout implements the ebiten.Game Layout method
@@ -103,23 +457,106 @@ func (e *Emulator) Layout(outsideWidth, outsideHeight int) (int, int) {
 
 // drawDebugInfo draws debug information on screen
 func (e *Emulator) drawDebugInfo(screen *ebiten.Image) {
-	debugText := fmt.Sprintf(
-		"FPS: %.1f\nFrame: %d\nDevice: %dx%d\nScale: %dx",
+	debugText := ""
+	if e.showDebugInfo {
+		debugText = e.debugStats() + "\n"
+		if e.debugVerbose {
+			debugText += e.deviceStateText()
+		}
+	}
+	if e.showInspector {
+		debugText += e.inspectorText()
+	}
+
+	// Draw debug text
+	ebitenutil.DebugPrintAt(screen, debugText, 5, 5)
+}
+
+// deviceStateText formats the state reported by the device, one setting per line
+// Inspectable devices are described by their full state snapshot, others through the small state interfaces they implement
+func (e *Emulator) deviceStateText() string {
+	onOff := map[bool]string{true: "on", false: "off"}
+	var sb strings.Builder
+
+	if r, ok := e.device.(device.Inspectable); ok {
+		state := r.State()
+		fmt.Fprintf(&sb, "Display: %s\n", onOff[state.DisplayOn])
+		fmt.Fprintf(&sb, "Contrast: 0x%02X Current: %d\n", state.Contrast, state.MasterCurrent)
+		fmt.Fprintf(&sb, "Inverted: %s All on: %s\n", onOff[state.Inverted], onOff[state.AllOn])
+		fmt.Fprintf(&sb, "Scroll: %s\n", onOff[state.Scrolling])
+		fmt.Fprintf(&sb, "Command lock: %s\n", onOff[state.CommandLocked])
+		fmt.Fprintf(&sb, "Start line: %d Offset: %d Remap: 0x%02X\n", state.StartLine, state.DisplayOffset, state.Remap)
+		fmt.Fprintf(&sb, "Window: col 0x%02X-0x%02X, row %d-%d\n", state.ColumnStart, state.ColumnEnd, state.RowStart, state.RowEnd)
+		fmt.Fprintf(&sb, "Address: col 0x%02X, row %d\n", state.Column, state.Row)
+	} else {
+		e.reportedStateText(&sb, onOff)
+	}
+
+	if d := e.lastDirty; d[0] == -1 {
+		sb.WriteString("Dirty: none\n")
+	} else {
+		fmt.Fprintf(&sb, "Dirty: (%d, %d)-(%d, %d)\n", d[0], d[1], d[2], d[3])
+	}
+
+	return sb.String()
+}
+
+// reportedStateText writes the settings a device without a state snapshot exposes through optional getters
+func (e *Emulator) reportedStateText(sb *strings.Builder, onOff map[bool]string) {
+	if r, ok := e.device.(powerReporter); ok {
+		fmt.Fprintf(sb, "Display: %s\n", onOff[r.IsDisplayOn()])
+	}
+	if r, ok := e.device.(device.BrightnessReporter); ok {
+		fmt.Fprintf(sb, "Contrast: 0x%02X Current: %d\n", r.GetContrastLevel(), r.GetMasterCurrent())
+	}
+	if r, ok := e.device.(device.InvertReporter); ok {
+		fmt.Fprintf(sb, "Inverted: %s\n", onOff[r.IsInverted()])
+	}
+	if r, ok := e.device.(scroller); ok {
+		fmt.Fprintf(sb, "Scroll: %s\n", onOff[r.IsScrolling()])
+	}
+	if r, ok := e.device.(lockReporter); ok {
+		fmt.Fprintf(sb, "Command lock: %s\n", onOff[r.IsCommandLocked()])
+	}
+}
+
+// inspectorText describes the pixel under the mouse, or notes that the mouse is off the display
+func (e *Emulator) inspectorText() string {
+	if !e.inspectOK {
+		return "Pixel: -"
+	}
+
+	// Read the pixel the renderer shows there, after start line and offset remapping
+	level, err := render.DisplayPixel(e.device, e.inspectX, e.inspectY)
+	if err != nil {
+		return "Pixel: -"
+	}
+
+	return fmt.Sprintf("Pixel (%d, %d): 0x%X", e.inspectX, e.inspectY, level)
+}
+
+// debugStats formats the frame rate, frame count and display size
+func (e *Emulator) debugStats() string {
+	return fmt.Sprintf(
+		"FPS: %.1f\nTPS: %.1f\nFrame: %d\nDevice: %dx%d\nScale: %dx",
 		e.lastFPS,
+		ebiten.ActualTPS(),
 		e.frameCount,
 		e.device.Width(),
 		e.device.Height(),
 		e.scale,
 	)
-
-	// Draw debug text
-	ebitenutil.DebugPrintAt(screen, debugText, 5, 5)
 }
 
 // Run starts the emulator window
 func (e *Emulator) Run() error {
 	ebiten.SetWindowTitle(e.windowTitle)
-	ebiten.SetMaxTPS(e.frameRate)
+	ebiten.SetTPS(e.updateRate)
+	ebiten.SetVsyncEnabled(e.vsync)
+	// Draw fills the whole screen, and skipped renders must leave the previous frame on screen
+	ebiten.SetScreenClearedEveryFrame(false)
+	// Handle window close in Update so the close hook runs first
+	ebiten.SetWindowClosingHandled(true)
 
 	return ebiten.RunGame(e)
 }
@@ -134,7 +571,12 @@ func (e *Emulator) GetFrameCount() int {
 	return e.frameCount
 }
 
-// GetFPS returns the current FPS
+// GetFPS returns the measured render rate, updated once per second
 func (e *Emulator) GetFPS() float64 {
 	return e.lastFPS
 }
+
+// GetTPS returns the measured update rate
+func (e *Emulator) GetTPS() float64 {
+	return ebiten.ActualTPS()
+}
@@ -0,0 +1,157 @@
+package emulator
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flavioheleno/oled-emulator/device"
+	"github.com/flavioheleno/oled-emulator/render"
+)
+
+func TestEmulatorFrameBuffer(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	emu := NewEmulator(dev, 1)
+
+	fb := emu.GetFrameBuffer()
+	if fb != emu.GetFrameBuffer() {
+		t.Error("the draw callback framebuffer should be reused across frames")
+	}
+	if fb.GetDevice() != dev {
+		t.Error("the framebuffer should be bound to the emulated device")
+	}
+}
+
+func TestEmulatorScrollThreadSafe(t *testing.T) {
+	dev := device.NewSSD1322(64, 16)
+	emu := NewEmulator(dev, 1)
+	fb := emu.GetFrameBuffer()
+	fb.SetThreadSafe(true)
+
+	dev.ProcessCommand(device.CmdHorizontalScroll, []byte{0x00, 0x00, 0x01, 0x0F, 0x01})
+	dev.ProcessCommand(device.CmdActivateScroll, nil)
+
+	// One goroutine draws while this one steps the scroll, like an animator next to the game loop
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			fb.DrawRect(0, 0, 64, 16, byte(i)&0x0F, true)
+			fb.Flush()
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		emu.frameCount++
+		emu.stepScroll()
+	}
+	<-done
+}
+
+func TestEmulatorRenderRate(t *testing.T) {
+	emu := NewEmulator(device.NewSSD1322(256, 64), 1)
+
+	if err := emu.SetUpdateRate(10); err != nil {
+		t.Fatalf("failed to set update rate: %v", err)
+	}
+	if err := emu.SetRenderRate(30); err != nil {
+		t.Fatalf("failed to set render rate: %v", err)
+	}
+	if emu.GetUpdateRate() != 10 || emu.GetRenderRate() != 30 {
+		t.Errorf("expected 10 TPS and 30 FPS, got %d and %d", emu.GetUpdateRate(), emu.GetRenderRate())
+	}
+
+	// Simulate one second of 60 Hz display refreshes
+	start := time.Now()
+	renders := 0
+	for i := 0; i < 60; i++ {
+		if emu.renderDue(start.Add(time.Duration(i) * time.Second / 60)) {
+			renders++
+		}
+	}
+	if renders != 30 {
+		t.Errorf("expected 30 renders in one second, got %d", renders)
+	}
+
+	emu.SetRenderRate(0)
+	if !emu.renderDue(start) || !emu.renderDue(start) {
+		t.Error("expected every refresh to render when uncapped")
+	}
+
+	if err := emu.SetUpdateRate(0); err == nil {
+		t.Error("expected error for zero update rate")
+	}
+	if err := emu.SetRenderRate(-1); err == nil {
+		t.Error("expected error for negative render rate")
+	}
+
+	if err := emu.SetFrameRate(0); err == nil {
+		t.Error("expected error for zero frame rate")
+	}
+	if emu.GetUpdateRate() != 10 || emu.GetRenderRate() != 0 {
+		t.Errorf("expected an invalid frame rate to leave both rates unchanged, got %d and %d", emu.GetUpdateRate(), emu.GetRenderRate())
+	}
+	if err := emu.SetFrameRate(20); err != nil {
+		t.Fatalf("failed to set frame rate: %v", err)
+	}
+	if emu.GetUpdateRate() != 20 || emu.GetRenderRate() != 20 {
+		t.Errorf("expected 20 TPS and 20 FPS, got %d and %d", emu.GetUpdateRate(), emu.GetRenderRate())
+	}
+}
+
+func TestEmulatorCyclePalette(t *testing.T) {
+	emu := NewEmulator(device.NewSSD1322(256, 64), 1)
+
+	// Presets cycle in sorted order, starting after the default grayscale palette
+	expected := []string{"white", "amber", "blue", "grayscale"}
+	for _, name := range expected {
+		if got := emu.CyclePalette(); got != name {
+			t.Errorf("expected palette %q, got %q", name, got)
+		}
+	}
+
+	green := render.NewMonochromePalette(color.RGBA{G: 255, A: 255})
+	emu.RegisterPalette("green", green)
+	emu.SetPalettePreset("white")
+	if got := emu.CyclePalette(); got != "green" {
+		t.Errorf("expected registered palette after the presets, got %q", got)
+	}
+	if emu.renderer.Palette() != green {
+		t.Error("expected the renderer to use the registered palette")
+	}
+	if emu.toast != "Palette: green" || emu.toastTicks == 0 {
+		t.Errorf("expected a toast naming the palette, got %q", emu.toast)
+	}
+}
+
+func TestEmulatorDeviceStateText(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	dev.ProcessCommand(device.CmdCommandLock, []byte{0xB1})
+	dev.ProcessCommand(device.CmdSetContrast, []byte{0x80})
+	dev.ProcessCommand(device.CmdNormalDisplay, nil)
+
+	emu := NewEmulator(dev, 1)
+	text := emu.deviceStateText()
+	for _, line := range []string{"Display: on", "Contrast: 0x80", "Inverted: off All on: off", "Scroll: off", "Command lock: off", "Start line: 0 Offset: 0", "Window: col 0x1C-0x5B, row 0-63", "Address: col 0x1C, row 0", "Dirty: none"} {
+		if !strings.Contains(text, line) {
+			t.Errorf("expected %q in device state:\n%s", line, text)
+		}
+	}
+
+	emu.lastDirty = [4]int{1, 2, 30, 40}
+	if text := emu.deviceStateText(); !strings.Contains(text, "Dirty: (1, 2)-(30, 40)") {
+		t.Errorf("expected dirty region in device state:\n%s", text)
+	}
+
+	// Devices without a state snapshot list what their getters report
+	text = NewEmulator(device.NewSSD1306(128, 64), 1).deviceStateText()
+	if !strings.Contains(text, "Display: ") || strings.Contains(text, "Window:") {
+		t.Errorf("expected getter-based state without a window for an SSD1306:\n%s", text)
+	}
+
+	// Devices without state getters only report the dirty region
+	if text := NewEmulator(device.NewNullDevice(8, 8), 1).deviceStateText(); text != "Dirty: none\n" {
+		t.Errorf("unexpected state for a null device: %q", text)
+	}
+}
@@ -0,0 +1,114 @@
+package emulator
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// maxZoom is the largest interactive zoom factor
+const maxZoom = 16
+
+// SetInteractiveZoom enables zooming with the mouse wheel and panning by dragging with the left button
+// Zoom is an integer factor on top of the base scale, so pixels stay sharp; the 0 key resets the view
+// Disabling it resets the view
+func (e *Emulator) SetInteractiveZoom(enabled bool) {
+	e.interactiveZoom = enabled
+	if !enabled {
+		e.ResetView()
+	}
+}
+
+// IsInteractiveZoom returns whether wheel zoom and drag-to-pan are enabled
+func (e *Emulator) IsInteractiveZoom() bool {
+	return e.interactiveZoom
+}
+
+// ResetView returns to the unzoomed, unpanned view
+func (e *Emulator) ResetView() {
+	e.zoom = 1
+	e.panX, e.panY = 0, 0
+	e.dragging = false
+}
+
+// GetZoom returns the interactive zoom factor, 1 when not zoomed
+func (e *Emulator) GetZoom() int {
+	return max(e.zoom, 1)
+}
+
+// handleZoom applies wheel zoom, drag-to-pan and the reset key
+func (e *Emulator) handleZoom() {
+	if _, handled := e.keyHandlers[ebiten.Key0]; !handled && inpututil.IsKeyJustPressed(ebiten.Key0) {
+		e.ResetView()
+		return
+	}
+
+	cx, cy := ebiten.CursorPosition()
+
+	if _, wheel := ebiten.Wheel(); wheel > 0 {
+		e.zoomAt(cx, cy, e.GetZoom()+1)
+	} else if wheel < 0 {
+		e.zoomAt(cx, cy, e.GetZoom()-1)
+	}
+
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		e.dragging = false
+		return
+	}
+
+	if e.dragging {
+		e.panX += cx - e.dragX
+		e.panY += cy - e.dragY
+		e.clampPan()
+	}
+	e.dragging = true
+	e.dragX, e.dragY = cx, cy
+}
+
+// zoomAt changes the zoom factor, keeping the display pixel under (cx, cy) in place
+func (e *Emulator) zoomAt(cx, cy, zoom int) {
+	zoom = min(max(zoom, 1), maxZoom)
+	current := e.GetZoom()
+	if zoom == current {
+		return
+	}
+
+	// Screen position s maps to display image position (s - pan) / zoom
+	e.panX = cx - (cx-e.panX)*zoom/current
+	e.panY = cy - (cy-e.panY)*zoom/current
+	e.zoom = zoom
+	e.clampPan()
+}
+
+// clampPan keeps the zoomed display covering the whole window
+func (e *Emulator) clampPan() {
+	width, height := e.Layout(0, 0)
+	zoom := e.GetZoom()
+
+	e.panX = min(max(e.panX, width-width*zoom), 0)
+	e.panY = min(max(e.panY, height-height*zoom), 0)
+}
+
+// viewTransform applies the zoom and pan to op
+func (e *Emulator) viewTransform(op *ebiten.DrawImageOptions) {
+	zoom := float64(e.GetZoom())
+	op.GeoM.Scale(zoom, zoom)
+	op.GeoM.Translate(float64(e.panX), float64(e.panY))
+	op.Filter = ebiten.FilterNearest
+}
+
+// screenToDevice maps a window position through the zoom, pan and scale to device pixel coordinates
+// ok is false when the position is outside the display
+func (e *Emulator) screenToDevice(sx, sy int) (x, y int, ok bool) {
+	size := e.GetZoom() * e.scale
+	dx, dy := sx-e.panX, sy-e.panY
+	if dx < 0 || dy < 0 {
+		return 0, 0, false
+	}
+
+	x, y = dx/size, dy/size
+	if x >= e.device.Width() || y >= e.device.Height() {
+		return 0, 0, false
+	}
+
+	return x, y, true
+}
@@ -0,0 +1,97 @@
+package emulator
+
+import (
+	"testing"
+
+	"github.com/flavioheleno/oled-emulator/device"
+)
+
+func TestEmulatorZoomAt(t *testing.T) {
+	emu := NewEmulator(device.NewSSD1322(256, 64), 2)
+	emu.SetInteractiveZoom(true)
+
+	// Zooming keeps the pixel under the cursor in place
+	emu.zoomAt(100, 40, 2)
+	if emu.GetZoom() != 2 || emu.panX != -100 || emu.panY != -40 {
+		t.Errorf("expected zoom 2 with pan (-100, -40), got %d with (%d, %d)", emu.GetZoom(), emu.panX, emu.panY)
+	}
+
+	emu.zoomAt(100, 40, 4)
+	if emu.panX != -300 || emu.panY != -120 {
+		t.Errorf("expected pan (-300, -120), got (%d, %d)", emu.panX, emu.panY)
+	}
+
+	// Pan is clamped so the display always covers the window
+	emu.zoomAt(0, 0, 1)
+	if emu.panX != 0 || emu.panY != 0 {
+		t.Errorf("expected unzoomed view at the origin, got (%d, %d)", emu.panX, emu.panY)
+	}
+
+	emu.zoomAt(512, 128, 2)
+	emu.panX, emu.panY = -5000, 50
+	emu.clampPan()
+	if emu.panX != -512 || emu.panY != 0 {
+		t.Errorf("expected pan clamped to (-512, 0), got (%d, %d)", emu.panX, emu.panY)
+	}
+
+	emu.zoomAt(0, 0, 100)
+	if emu.GetZoom() != maxZoom {
+		t.Errorf("expected zoom capped at %d, got %d", maxZoom, emu.GetZoom())
+	}
+
+	emu.SetInteractiveZoom(false)
+	if emu.GetZoom() != 1 || emu.panX != 0 || emu.panY != 0 {
+		t.Error("expected disabling interactive zoom to reset the view")
+	}
+}
+
+func TestEmulatorInspector(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	dev.SetPixel(10, 5, 0x0A)
+
+	emu := NewEmulator(dev, 2)
+	emu.SetShowInspector(true)
+
+	tests := []struct {
+		sx, sy int
+		x, y   int
+		ok     bool
+	}{
+		{21, 11, 10, 5, true},
+		{0, 0, 0, 0, true},
+		{511, 127, 255, 63, true},
+		{-1, 10, 0, 0, false},
+		{512, 10, 0, 0, false},
+		{10, 128, 0, 0, false},
+	}
+	for _, test := range tests {
+		x, y, ok := emu.screenToDevice(test.sx, test.sy)
+		if ok != test.ok || (ok && (x != test.x || y != test.y)) {
+			t.Errorf("(%d, %d): expected (%d, %d, %v), got (%d, %d, %v)", test.sx, test.sy, test.x, test.y, test.ok, x, y, ok)
+		}
+	}
+
+	// Zoom and pan are taken into account
+	emu.SetInteractiveZoom(true)
+	emu.zoomAt(0, 0, 2)
+	if x, y, _ := emu.screenToDevice(42, 22); x != 10 || y != 5 {
+		t.Errorf("expected (10, 5) when zoomed, got (%d, %d)", x, y)
+	}
+
+	emu.inspectX, emu.inspectY, emu.inspectOK = emu.screenToDevice(42, 22)
+	if text := emu.inspectorText(); text != "Pixel (10, 5): 0xA" {
+		t.Errorf("unexpected inspector text: %q", text)
+	}
+
+	// The inspector reports the pixel shown on screen, which a start line moves away from its RAM row
+	dev.ProcessCommand(device.CmdSetStartLine, []byte{5})
+	emu.inspectX, emu.inspectY = 10, 0
+	if text := emu.inspectorText(); text != "Pixel (10, 0): 0xA" {
+		t.Errorf("unexpected inspector text with a start line: %q", text)
+	}
+
+	emu.inspectOK = false
+	if text := emu.inspectorText(); text != "Pixel: -" {
+		t.Errorf("unexpected inspector text off the display: %q", text)
+	}
+}
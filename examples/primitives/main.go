@@ -49,10 +49,7 @@ func main() {
 	fb.DrawTriangle(220, 35, 240, 35, 230, 55, 0x0F, false)
 
 	// Draw gradient effect
-	for i := 0; i < 16; i++ {
-		shade := byte(i)
-		fb.SetPixel(10+i, 55, shade)
-	}
+	fb.FillGradient(10, 55, 16, 1, 0x00, 0x0F, true)
 
 	fb.Flush()
 
@@ -27,13 +27,19 @@ func main() {
 		log.Fatalf("init error: %v", err)
 	}
 
-	// Set contrast using command builder
+	// Set contrast: command byte with DC low, then its data byte with DC high
 	log.Println("Setting contrast...")
 	bridge.SetDC(false)
-	contrastCmd := protocol.ContrastCommand(0x80)
-	if err := bridge.Write(contrastCmd); err != nil {
+	if err := bridge.Write([]byte{device.CmdSetContrast}); err != nil {
 		log.Fatalf("contrast command error: %v", err)
 	}
+	bridge.SetDC(true)
+	if err := bridge.Write([]byte{0x80}); err != nil {
+		log.Fatalf("contrast data error: %v", err)
+	}
+	if err := bridge.Flush(); err != nil {
+		log.Fatalf("contrast flush error: %v", err)
+	}
 
 	// Create emulator to visualize
 	emu := emulator.NewEmulator(dev, 2)
@@ -32,6 +32,36 @@ func (bf *BitmapFont) AddGlyph(ch rune, data GlyphData) {
 	bf.glyphs[ch] = data
 }
 
+// RemoveGlyph removes a glyph from the font
+func (bf *BitmapFont) RemoveGlyph(ch rune) {
+	delete(bf.glyphs, ch)
+}
+
+// ClearGlyphs removes all glyphs from the font
+func (bf *BitmapFont) ClearGlyphs() {
+	bf.glyphs = make(map[rune]GlyphData)
+}
+
+// GlyphCount returns the number of glyphs in the font
+func (bf *BitmapFont) GlyphCount() int {
+	return len(bf.glyphs)
+}
+
+// Clone returns an independent copy of the font
+// Glyphs added, removed or modified on the clone don't affect the original
+func (bf *BitmapFont) Clone() *BitmapFont {
+	clone := NewBitmapFont(bf.width, bf.height, bf.advance)
+
+	for ch, glyph := range bf.glyphs {
+		data := make([]byte, len(glyph.Data))
+		copy(data, glyph.Data)
+		glyph.Data = data
+		clone.glyphs[ch] = glyph
+	}
+
+	return clone
+}
+
 // DrawString draws text at the specified position
 func (bf *BitmapFont) DrawString(fb *FrameBuffer, x, y int, text string, color byte) (int, error) {
 	currentX := x
@@ -80,6 +110,11 @@ func (bf *BitmapFont) GetGlyph(ch rune) (GlyphData, error) {
 
 // drawGlyph draws a single glyph to the framebuffer
 func (bf *BitmapFont) drawGlyph(fb *FrameBuffer, x, y int, glyph GlyphData, color byte) error {
+	return drawGlyphData(fb, x, y, glyph, color)
+}
+
+// drawGlyphData draws 1-bit packed glyph data to the framebuffer
+func drawGlyphData(fb *FrameBuffer, x, y int, glyph GlyphData, color byte) error {
 	if glyph.Width <= 0 || glyph.Height <= 0 || len(glyph.Data) == 0 {
 		return nil // Empty glyph
 	}
@@ -138,99 +173,25 @@ func DefaultBitmapFont() *BitmapFont {
 	return bf
 }
 
-// createASCIIGlyph creates a simple ASCII glyph
+// createASCIIGlyph converts a printable ASCII character from the 5x7 font table
+// into row-packed glyph data; characters outside the table render as a solid block
 func createASCIIGlyph(ch rune) GlyphData {
-	// This is a simplified implementation
-	// In a real system, you would have pre-rendered glyphs
 	width := 5
 	height := 7
-	bytesPerRow := (width + 7) / 8
-
-	// Create basic glyphs for common characters
-	var data []byte
-
-	switch ch {
-	case ' ':
-		// Space - empty
-		data = make([]byte, bytesPerRow*height)
-
-	case 'A':
-		// Letter A (5 bits wide, 7 bits tall)
-		data = []byte{
-			0b01110000,
-			0b10001000,
-			0b10001000,
-			0b11111000,
-			0b10001000,
-			0b10001000,
-			0b10001000,
-		}
-
-	case 'B':
-		// Letter B
-		data = []byte{
-			0b11110000,
-			0b10001000,
-			0b10001000,
-			0b11100000,
-			0b10001000,
-			0b10001000,
-			0b11110000,
-		}
-
-	case 'H':
-		// Letter H
-		data = []byte{
-			0b10001000,
-			0b10001000,
-			0b10001000,
-			0b11111000,
-			0b10001000,
-			0b10001000,
-			0b10001000,
-		}
-
-	case 'O':
-		// Letter O
-		data = []byte{
-			0b01110000,
-			0b10001000,
-			0b10001000,
-			0b10001000,
-			0b10001000,
-			0b10001000,
-			0b01110000,
-		}
-
-	case '0':
-		// Digit 0
-		data = []byte{
-			0b01110000,
-			0b10001000,
-			0b10001000,
-			0b10001000,
-			0b10001000,
-			0b10001000,
-			0b01110000,
-		}
-
-	case '1':
-		// Digit 1
-		data = []byte{
-			0b00100000,
-			0b01100000,
-			0b00100000,
-			0b00100000,
-			0b00100000,
-			0b00100000,
-			0b01110000,
+	data := make([]byte, height)
+
+	if ch >= 0x20 && ch <= 0x7E {
+		columns := font5x7[ch-0x20]
+		for row := 0; row < height; row++ {
+			for col := 0; col < width; col++ {
+				if columns[col]&(1<<row) != 0 {
+					data[row] |= 0x80 >> col
+				}
+			}
 		}
-
-	default:
-		// Default character - simple block
-		data = make([]byte, bytesPerRow*height)
-		for i := 0; i < len(data); i++ {
-			data[i] = 0x78 // 0b01111000 (5 bits set)
+	} else {
+		for i := range data {
+			data[i] = 0xF8 // 0b11111000 (5 bits set)
 		}
 	}
 
@@ -0,0 +1,298 @@
+package graphics
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadXBM parses an XBM bitmap (the C header format) into a grayscale image
+// Width and height come from the _width and _height defines and are available from the image bounds
+// Set bits are white so they light up when drawn with DrawImage, clear bits are black
+func LoadXBM(r io.Reader) (image.Image, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XBM: %w", err)
+	}
+	text := string(src)
+
+	width, height := -1, -1
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "#define" {
+			continue
+		}
+
+		value, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid XBM define %q: %w", fields[1], err)
+		}
+
+		switch {
+		case strings.HasSuffix(fields[1], "_width"):
+			width = value
+		case strings.HasSuffix(fields[1], "_height"):
+			height = value
+		}
+	}
+
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("XBM missing width or height")
+	}
+
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("XBM missing bitmap data")
+	}
+
+	data := make([]byte, 0, (width+7)/8*height)
+	for _, token := range strings.Split(text[start+1:end], ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		value, err := strconv.ParseUint(token, 0, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XBM byte %q: %w", token, err)
+		}
+		data = append(data, byte(value))
+	}
+
+	// Each row is padded to whole bytes, least significant bit first
+	rowBytes := (width + 7) / 8
+	if len(data) < rowBytes*height {
+		return nil, fmt.Errorf("XBM data too short: expected %d bytes, got %d", rowBytes*height, len(data))
+	}
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if data[y*rowBytes+x/8]&(1<<(x%8)) != 0 {
+				img.Pix[y*img.Stride+x] = 0xFF
+			}
+		}
+	}
+
+	return img, nil
+}
+
+// LoadPBM parses a plain (P1) or raw (P4) PBM bitmap into a grayscale image
+// Width and height come from the header and are available from the image bounds
+// Ink (1) pixels are white so they light up when drawn with DrawImage, background pixels are black
+func LoadPBM(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := pbmToken(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PBM header: %w", err)
+	}
+	if magic != "P1" && magic != "P4" {
+		return nil, fmt.Errorf("unsupported PBM format: %q", magic)
+	}
+
+	var dims [2]int
+	for i := range dims {
+		token, err := pbmToken(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PBM header: %w", err)
+		}
+
+		dims[i], err = strconv.Atoi(token)
+		if err != nil || dims[i] <= 0 {
+			return nil, fmt.Errorf("invalid PBM dimension: %q", token)
+		}
+	}
+	width, height := dims[0], dims[1]
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+
+	if magic == "P4" {
+		// Raw rows are padded to whole bytes, most significant bit first
+		rowBytes := (width + 7) / 8
+		row := make([]byte, rowBytes)
+		for y := 0; y < height; y++ {
+			if _, err := io.ReadFull(br, row); err != nil {
+				return nil, fmt.Errorf("PBM data too short at row %d: %w", y, err)
+			}
+
+			for x := 0; x < width; x++ {
+				if row[x/8]&(0x80>>(x%8)) != 0 {
+					img.Pix[y*img.Stride+x] = 0xFF
+				}
+			}
+		}
+
+		return img, nil
+	}
+
+	// Plain pixels are '0' or '1', optionally separated by whitespace
+	for i := 0; i < width*height; {
+		ch, err := br.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("PBM data too short: expected %d pixels, got %d", width*height, i)
+		}
+
+		switch ch {
+		case '0', '1':
+			if ch == '1' {
+				img.Pix[(i/width)*img.Stride+i%width] = 0xFF
+			}
+			i++
+		case '#':
+			if _, err := br.ReadString('\n'); err != nil && err != io.EOF {
+				return nil, err
+			}
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+		default:
+			return nil, fmt.Errorf("invalid PBM pixel: %q", ch)
+		}
+	}
+
+	return img, nil
+}
+
+// pbmToken reads the next whitespace-separated header token, skipping comments
+// The single whitespace byte after the token is consumed, as raw data starts right after it
+func pbmToken(br *bufio.Reader) (string, error) {
+	var token []byte
+
+	for {
+		ch, err := br.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(token) > 0 {
+				return string(token), nil
+			}
+			return "", err
+		}
+
+		switch ch {
+		case '#':
+			if _, err := br.ReadString('\n'); err != nil && err != io.EOF {
+				return "", err
+			}
+			if len(token) > 0 {
+				return string(token), nil
+			}
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+			if len(token) > 0 {
+				return string(token), nil
+			}
+		default:
+			token = append(token, ch)
+		}
+	}
+}
+
+// DefaultExportThreshold is the lowest level exported as a set bit by ExportXBM
+const DefaultExportThreshold = 8
+
+// ExportXBM writes the framebuffer as an XBM C header named name
+// Pixels at level DefaultExportThreshold or above become set bits
+func ExportXBM(fb *FrameBuffer, w io.Writer, name string) error {
+	return ExportXBMThreshold(fb, w, name, DefaultExportThreshold)
+}
+
+// ExportXBMThreshold writes the framebuffer as an XBM C header, setting bits for pixels at threshold or above
+// The output can be read back with LoadXBM
+func ExportXBMThreshold(fb *FrameBuffer, w io.Writer, name string, threshold byte) error {
+	if !isCIdentifier(name) {
+		return fmt.Errorf("invalid C identifier: %q", name)
+	}
+
+	width, height := fb.Width(), fb.Height()
+	rowBytes := (width + 7) / 8
+	data := make([]byte, rowBytes*height)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			level, err := fb.GetPixel(x, y)
+			if err != nil {
+				return err
+			}
+
+			if level >= threshold {
+				data[y*rowBytes+x/8] |= 1 << (x % 8)
+			}
+		}
+	}
+
+	return writeCArray(w, name, width, height, "static unsigned char "+name+"_bits[]", data)
+}
+
+// ExportGrayArray writes the framebuffer as a C header with 4-bit pixels, two per byte
+// The left pixel of each pair is in the low nibble, the order SSD1322 WriteData expects, and odd widths pad each row with a zero nibble
+func ExportGrayArray(fb *FrameBuffer, w io.Writer, name string) error {
+	if !isCIdentifier(name) {
+		return fmt.Errorf("invalid C identifier: %q", name)
+	}
+
+	width, height := fb.Width(), fb.Height()
+	rowBytes := (width + 1) / 2
+	data := make([]byte, rowBytes*height)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			level, err := fb.GetPixel(x, y)
+			if err != nil {
+				return err
+			}
+
+			shift := 4 * (x % 2)
+			data[y*rowBytes+x/2] |= (level & 0x0F) << shift
+		}
+	}
+
+	return writeCArray(w, name, width, height, "static const unsigned char "+name+"_data[]", data)
+}
+
+// writeCArray writes width and height defines followed by data as a hex byte array, 12 bytes per line
+func writeCArray(w io.Writer, name string, width, height int, decl string, data []byte) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "#define %s_width %d\n", name, width)
+	fmt.Fprintf(&sb, "#define %s_height %d\n", name, height)
+	fmt.Fprintf(&sb, "%s = {", decl)
+
+	for i, b := range data {
+		if i%12 == 0 {
+			sb.WriteString("\n   ")
+		} else {
+			sb.WriteString(" ")
+		}
+
+		fmt.Fprintf(&sb, "0x%02x", b)
+		if i < len(data)-1 {
+			sb.WriteString(",")
+		}
+	}
+	sb.WriteString(" };\n")
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write C array: %w", err)
+	}
+
+	return nil
+}
+
+// isCIdentifier reports whether name is a valid C identifier
+func isCIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, ch := range name {
+		switch {
+		case ch == '_', ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z':
+		case ch >= '0' && ch <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,242 @@
+package graphics
+
+import (
+	"fmt"
+	"math"
+)
+
+// series holds the samples and value range shared by the charts
+type series struct {
+	values   []float64
+	capacity int // Samples kept; appending past it drops the oldest
+	fixed    bool
+	min      float64
+	max      float64
+}
+
+// SetValues replaces the samples, keeping only the newest that fit the capacity
+func (s *series) SetValues(values []float64) {
+	if len(values) > s.capacity {
+		values = values[len(values)-s.capacity:]
+	}
+
+	s.values = append(s.values[:0], values...)
+}
+
+// Append adds a sample, dropping the oldest once the chart is full so it scrolls like a scope
+func (s *series) Append(value float64) {
+	if len(s.values) == s.capacity {
+		copy(s.values, s.values[1:])
+		s.values = s.values[:len(s.values)-1]
+	}
+
+	s.values = append(s.values, value)
+}
+
+// Values returns a copy of the samples, oldest first
+func (s *series) Values() []float64 {
+	values := make([]float64, len(s.values))
+	copy(values, s.values)
+	return values
+}
+
+// SetCapacity sets how many samples the chart shows
+func (s *series) SetCapacity(capacity int) error {
+	if capacity < 1 {
+		return fmt.Errorf("invalid chart capacity: %d", capacity)
+	}
+
+	s.capacity = capacity
+	s.SetValues(s.values)
+	return nil
+}
+
+// SetRange fixes the value range mapped to the chart height
+// Samples outside it are clamped to the edges
+func (s *series) SetRange(min, max float64) error {
+	if max <= min {
+		return fmt.Errorf("invalid chart range: %v to %v", min, max)
+	}
+
+	s.fixed = true
+	s.min = min
+	s.max = max
+	return nil
+}
+
+// AutoRange scales the chart to the minimum and maximum of the samples
+func (s *series) AutoRange() {
+	s.fixed = false
+}
+
+// valueRange returns the range to scale to, widening a flat series so it has a span
+func (s *series) valueRange(includeZero bool) (float64, float64) {
+	if s.fixed {
+		return s.min, s.max
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range s.values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	if includeZero {
+		lo = math.Min(lo, 0)
+		hi = math.Max(hi, 0)
+	}
+	if hi <= lo {
+		return lo - 0.5, hi + 0.5
+	}
+
+	return lo, hi
+}
+
+// scale maps a value to a fraction of the range, clamped to [0, 1]
+func scale(value, lo, hi float64) float64 {
+	return math.Max(0, math.Min(1, (value-lo)/(hi-lo)))
+}
+
+// LineChart plots samples as connected line segments inside a box
+type LineChart struct {
+	series
+	x     int
+	y     int
+	w     int
+	h     int
+	color byte
+}
+
+// NewLineChart creates a line chart in the w x h box at (x, y)
+// It holds one sample per horizontal pixel and scales to the samples until SetRange is used
+func NewLineChart(x, y, w, h int) *LineChart {
+	return &LineChart{
+		series: series{capacity: max(w, 1)},
+		x:      x,
+		y:      y,
+		w:      w,
+		h:      h,
+		color:  0x0F,
+	}
+}
+
+// SetColor sets the line color
+func (lc *LineChart) SetColor(color byte) {
+	lc.color = color & 0x0F
+}
+
+// Draw clears the box and plots the samples, oldest on the left
+// Samples are spread over the capacity, so a chart that is not yet full grows to the right
+// The box is shrunk to the framebuffer's safe area
+func (lc *LineChart) Draw(fb *FrameBuffer) error {
+	x, y, w, h := fb.safeRect(lc.x, lc.y, lc.w, lc.h)
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	if err := fb.FillRegion(x, y, w, h, 0); err != nil {
+		return err
+	}
+	if len(lc.values) == 0 {
+		return nil
+	}
+
+	lo, hi := lc.valueRange(false)
+	point := func(i int) (int, int) {
+		px := x
+		if lc.capacity > 1 {
+			px += int(math.Round(float64(i*(w-1)) / float64(lc.capacity-1)))
+		}
+		py := y + h - 1 - int(math.Round(scale(lc.values[i], lo, hi)*float64(h-1)))
+		return px, py
+	}
+
+	prevX, prevY := point(0)
+	if len(lc.values) == 1 {
+		return fb.SetPixel(prevX, prevY, lc.color)
+	}
+
+	for i := 1; i < len(lc.values); i++ {
+		px, py := point(i)
+		if err := fb.DrawLine(prevX, prevY, px, py, lc.color); err != nil {
+			return err
+		}
+		prevX, prevY = px, py
+	}
+
+	return nil
+}
+
+// BarChart draws samples as vertical bars rising from the bottom of a box
+type BarChart struct {
+	series
+	x     int
+	y     int
+	w     int
+	h     int
+	gap   int
+	color byte
+}
+
+// NewBarChart creates a bar chart in the w x h box at (x, y)
+// It holds one 3-pixel bar with a 1-pixel gap per 4 pixels of width, and auto-scales including zero
+func NewBarChart(x, y, w, h int) *BarChart {
+	return &BarChart{
+		series: series{capacity: max(w/4, 1)},
+		x:      x,
+		y:      y,
+		w:      w,
+		h:      h,
+		gap:    1,
+		color:  0x0F,
+	}
+}
+
+// SetColor sets the bar color
+func (bc *BarChart) SetColor(color byte) {
+	bc.color = color & 0x0F
+}
+
+// SetGap sets the spacing in pixels between bars
+func (bc *BarChart) SetGap(gap int) error {
+	if gap < 0 {
+		return fmt.Errorf("invalid bar gap: %d", gap)
+	}
+
+	bc.gap = gap
+	return nil
+}
+
+// Draw clears the box and draws one bar per sample, oldest on the left
+// Bars share the width evenly across the capacity, and their height is the value above the range minimum
+// The box is shrunk to the framebuffer's safe area
+func (bc *BarChart) Draw(fb *FrameBuffer) error {
+	x, y, w, h := fb.safeRect(bc.x, bc.y, bc.w, bc.h)
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	if err := fb.FillRegion(x, y, w, h, 0); err != nil {
+		return err
+	}
+	if len(bc.values) == 0 {
+		return nil
+	}
+
+	slot := float64(w+bc.gap) / float64(bc.capacity)
+	barW := max(int(slot)-bc.gap, 1)
+	lo, hi := bc.valueRange(true)
+
+	for i, v := range bc.values {
+		barH := int(math.Round(scale(v, lo, hi) * float64(h)))
+		if barH == 0 {
+			continue
+		}
+
+		bx := x + int(float64(i)*slot)
+		if err := fb.FillRegion(bx, y+h-barH, barW, barH, bc.color); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
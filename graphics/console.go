@@ -0,0 +1,202 @@
+package graphics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// consoleTabWidth is the spacing of tab stops in columns
+const consoleTabWidth = 4
+
+// Console is a character grid for scrolling text output, like a terminal
+// The grid is sized from the font: each cell is one advance wide and one font height tall
+type Console struct {
+	font          Font
+	x             int
+	y             int
+	cols          int
+	rows          int
+	cellW         int
+	cellH         int
+	cells         [][]rune
+	cursorX       int
+	cursorY       int
+	cursorVisible bool
+	color         byte
+	background    byte
+}
+
+// NewConsole creates a console filling a width x height pixel area
+// Columns and rows are as many whole cells as fit, at least one each
+func NewConsole(font Font, width, height int) *Console {
+	cellW, _, err := font.MeasureString("M")
+	if err != nil || cellW <= 0 {
+		cellW = 1
+	}
+	cellH := max(font.Height(), 1)
+
+	c := &Console{
+		font:  font,
+		cols:  max(width/cellW, 1),
+		rows:  max(height/cellH, 1),
+		cellW: cellW,
+		cellH: cellH,
+		color: 0x0F,
+	}
+	c.Clear()
+
+	return c
+}
+
+// SetPosition sets the top-left corner of the console on the framebuffer
+func (c *Console) SetPosition(x, y int) {
+	c.x = x
+	c.y = y
+}
+
+// Columns returns the number of character columns
+func (c *Console) Columns() int {
+	return c.cols
+}
+
+// Rows returns the number of character rows
+func (c *Console) Rows() int {
+	return c.rows
+}
+
+// SetColor sets the text color
+func (c *Console) SetColor(color byte) {
+	c.color = color & 0x0F
+}
+
+// SetBackground sets the color the console area is cleared to when rendering
+func (c *Console) SetBackground(color byte) {
+	c.background = color & 0x0F
+}
+
+// SetCursorVisible shows or hides the cursor
+func (c *Console) SetCursorVisible(visible bool) {
+	c.cursorVisible = visible
+}
+
+// SetCursor moves the cursor to a column and row
+func (c *Console) SetCursor(col, row int) error {
+	if col < 0 || col >= c.cols || row < 0 || row >= c.rows {
+		return fmt.Errorf("cursor out of bounds: (%d, %d)", col, row)
+	}
+
+	c.cursorX = col
+	c.cursorY = row
+	return nil
+}
+
+// Cursor returns the cursor column and row
+// The column equals Columns() after a full line, until the next character wraps
+func (c *Console) Cursor() (col, row int) {
+	return c.cursorX, c.cursorY
+}
+
+// Clear blanks the grid and moves the cursor home
+func (c *Console) Clear() {
+	c.cells = make([][]rune, c.rows)
+	for row := range c.cells {
+		c.cells[row] = blankConsoleRow(c.cols)
+	}
+
+	c.cursorX = 0
+	c.cursorY = 0
+}
+
+// Print writes text at the cursor
+// Lines wrap at the right edge and the grid scrolls up when the cursor passes the last row
+// '\n' starts a new line, '\r' returns to the first column and '\t' moves to the next tab stop
+func (c *Console) Print(s string) {
+	for _, ch := range s {
+		switch ch {
+		case '\n':
+			c.newline()
+		case '\r':
+			c.cursorX = 0
+		case '\t':
+			next := (c.cursorX/consoleTabWidth + 1) * consoleTabWidth
+			for c.cursorX < next && c.cursorX < c.cols {
+				c.put(' ')
+			}
+		default:
+			c.put(ch)
+		}
+	}
+}
+
+// Println writes text at the cursor followed by a new line
+func (c *Console) Println(s string) {
+	c.Print(s + "\n")
+}
+
+// Lines returns the text of each row without trailing spaces
+func (c *Console) Lines() []string {
+	lines := make([]string, c.rows)
+	for row, cells := range c.cells {
+		lines[row] = strings.TrimRight(string(cells), " ")
+	}
+
+	return lines
+}
+
+// Render clears the console area and draws the grid and cursor
+func (c *Console) Render(fb *FrameBuffer) error {
+	if err := fb.FillRegion(c.x, c.y, c.cols*c.cellW, c.rows*c.cellH, c.background); err != nil {
+		return err
+	}
+
+	for row, cells := range c.cells {
+		for col, ch := range cells {
+			if ch == ' ' {
+				continue
+			}
+
+			if _, err := c.font.DrawString(fb, c.x+col*c.cellW, c.y+row*c.cellH, string(ch), c.color); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The cursor is an underline in its cell, hidden while a wrap is pending
+	if c.cursorVisible && c.cursorX < c.cols {
+		return fb.FillRegion(c.x+c.cursorX*c.cellW, c.y+(c.cursorY+1)*c.cellH-1, c.cellW, 1, c.color)
+	}
+
+	return nil
+}
+
+// put writes a character at the cursor, wrapping first if the line is full
+func (c *Console) put(ch rune) {
+	if c.cursorX >= c.cols {
+		c.newline()
+	}
+
+	c.cells[c.cursorY][c.cursorX] = ch
+	c.cursorX++
+}
+
+// newline moves the cursor to the start of the next row, scrolling when at the bottom
+func (c *Console) newline() {
+	c.cursorX = 0
+	if c.cursorY < c.rows-1 {
+		c.cursorY++
+		return
+	}
+
+	copy(c.cells, c.cells[1:])
+	c.cells[c.rows-1] = blankConsoleRow(c.cols)
+}
+
+// blankConsoleRow returns a row of spaces
+func blankConsoleRow(cols int) []rune {
+	row := make([]rune, cols)
+	for i := range row {
+		row[i] = ' '
+	}
+
+	return row
+}
@@ -2,23 +2,94 @@ package graphics
 
 import (
 	"fmt"
+	"image"
+	"math"
+	"math/rand"
+	"sync"
 
 	"github.com/flavioheleno/oled-emulator/device"
 )
 
 // FrameBuffer provides a high-level drawing API on top of a device
 type FrameBuffer struct {
-	device device.Device
-	buffer []byte
-	dirty  bool
+	device   device.Device
+	buffer   []byte
+	dirty    bool
+	safeArea Insets
+	back     []byte // Back buffer (one level per pixel), nil in immediate mode
+	dirtyX0  int    // Region modified since the last Flush, -1 if clean
+	dirtyY0  int
+	dirtyX1  int
+	dirtyY1  int
+	clip     *image.Rectangle  // Pixels outside are dropped, nil when unclipped
+	clips    []image.Rectangle // Clip stack maintained by PushClip/PopClip
+	mu       sync.Mutex        // Guards pixel access when safe is set
+	safe     bool
+	mode     DrawMode
+}
+
+// DrawMode selects how drawn pixels combine with the pixels already there
+type DrawMode int
+
+const (
+	DrawModeReplace DrawMode = iota // Overwrite the existing pixel (default)
+	DrawModeXor                     // XOR with the existing level; drawing twice restores it
+	DrawModeOr                      // Bitwise OR with the existing level
+	DrawModeAnd                     // Bitwise AND with the existing level
+	DrawModeMax                     // Keep the brighter level (lighten), like overlapping light
+	DrawModeMin                     // Keep the darker level (darken)
+	DrawModeAverage                 // Mean of both levels, rounded down
+)
+
+// applyDrawMode combines a drawn level src with the existing level dst
+func applyDrawMode(mode DrawMode, dst, src byte) byte {
+	dst &= 0x0F
+	src &= 0x0F
+
+	switch mode {
+	case DrawModeXor:
+		return dst ^ src
+	case DrawModeOr:
+		return dst | src
+	case DrawModeAnd:
+		return dst & src
+	case DrawModeMax:
+		if dst > src {
+			return dst
+		}
+		return src
+	case DrawModeMin:
+		if dst < src {
+			return dst
+		}
+		return src
+	case DrawModeAverage:
+		return (dst + src) / 2
+	default:
+		return src
+	}
+}
+
+// idempotent reports whether drawing a pixel twice in the mode gives the same level as drawing it once
+func (mode DrawMode) idempotent() bool {
+	return mode != DrawModeXor && mode != DrawModeAverage
+}
+
+// Insets describes a margin in pixels on each side of the display
+type Insets struct {
+	Left   int
+	Top    int
+	Right  int
+	Bottom int
 }
 
 // NewFrameBuffer creates a new framebuffer for a device
 func NewFrameBuffer(dev device.Device) *FrameBuffer {
 	fb := &FrameBuffer{
-		device: dev,
-		buffer: make([]byte, len(dev.GetFrameBuffer())),
-		dirty:  false,
+		device:  dev,
+		buffer:  make([]byte, len(dev.GetFrameBuffer())),
+		dirty:   false,
+		dirtyX0: -1,
 	}
 
 	// Copy initial buffer
@@ -28,47 +99,262 @@ func NewFrameBuffer(dev device.Device) *FrameBuffer {
 }
 
 // Clear fills the entire framebuffer with a color
+// The draw mode does not apply: cleared pixels are always replaced
 func (fb *FrameBuffer) Clear(color byte) error {
-	width := fb.device.Width()
-	height := fb.device.Height()
+	return fb.fillRegion(0, 0, fb.device.Width(), fb.device.Height(), color, DrawModeReplace)
+}
 
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			if err := fb.SetPixel(x, y, color); err != nil {
-				return err
-			}
+// SetPixel sets a pixel at the given coordinates
+func (fb *FrameBuffer) SetPixel(x, y int, color byte) error {
+	if !fb.inClip(x, y) {
+		return nil
+	}
+
+	if x < 0 || x >= fb.device.Width() || y < 0 || y >= fb.device.Height() {
+		return fmt.Errorf("pixel out of bounds: (%d, %d)", x, y)
+	}
+
+	fb.plot(x, y, color)
+	return nil
+}
+
+// plot writes a pixel, silently clipping coordinates outside the display
+// It is the setPixel callback used by the drawing primitives
+func (fb *FrameBuffer) plot(x, y int, color byte) {
+	fb.plotMode(x, y, color, fb.mode)
+}
+
+// plotMode is plot with an explicit draw mode
+func (fb *FrameBuffer) plotMode(x, y int, color byte, mode DrawMode) {
+	if x < 0 || x >= fb.device.Width() || y < 0 || y >= fb.device.Height() || !fb.inClip(x, y) {
+		return
+	}
+
+	fb.lock()
+	defer fb.unlock()
+
+	if mode != DrawModeReplace {
+		var current byte
+		if fb.back != nil {
+			current = fb.back[y*fb.device.Width()+x]
+		} else {
+			current, _ = fb.device.GetPixel(x, y)
+		}
+		color = applyDrawMode(mode, current, color)
+	}
+
+	if fb.back != nil {
+		fb.back[y*fb.device.Width()+x] = color & 0x0F
+	} else {
+		fb.device.SetPixel(x, y, color)
+	}
+
+	fb.markDirtyPixel(x, y)
+	fb.dirty = true
+}
+
+// shapePlot returns the pixel setter for a single shape draw call
+// Shapes may visit a pixel more than once, e.g. where rectangle sides or circle spans meet.
+// When the draw mode is not idempotent, repeats are dropped so each pixel is drawn once per call
+func (fb *FrameBuffer) shapePlot() func(int, int, byte) {
+	if fb.mode.idempotent() {
+		return fb.plot
+	}
+
+	width, height := fb.device.Width(), fb.device.Height()
+	seen := make(map[int]bool)
+
+	return func(x, y int, color byte) {
+		if x < 0 || x >= width || y < 0 || y >= height {
+			return
+		}
+
+		key := y*width + x
+		if seen[key] {
+			return
 		}
+		seen[key] = true
+
+		fb.plot(x, y, color)
+	}
+}
+
+// inClip reports whether (x, y) lies inside the active clip rectangle
+func (fb *FrameBuffer) inClip(x, y int) bool {
+	return fb.clip == nil || image.Pt(x, y).In(*fb.clip)
+}
+
+// PushClip restricts drawing to the w x h rectangle at (x, y), intersected with the current clip
+// Every drawing method drops pixels outside the clip until the matching PopClip
+func (fb *FrameBuffer) PushClip(x, y, w, h int) error {
+	if w < 0 || h < 0 {
+		return fmt.Errorf("invalid clip dimensions: %dx%d", w, h)
 	}
 
+	clip := image.Rect(x, y, x+w, y+h).Intersect(fb.currentClip())
+	fb.clips = append(fb.clips, clip)
+	fb.clip = &fb.clips[len(fb.clips)-1]
 	return nil
 }
 
-// SetPixel sets a pixel at the given coordinates
-func (fb *FrameBuffer) SetPixel(x, y int, color byte) error {
-	if err := fb.device.SetPixel(x, y, color); err != nil {
+// PopClip restores the clip that was active before the last PushClip
+func (fb *FrameBuffer) PopClip() error {
+	if len(fb.clips) == 0 {
+		return fmt.Errorf("clip stack is empty")
+	}
+
+	fb.clips = fb.clips[:len(fb.clips)-1]
+	fb.clip = nil
+	if len(fb.clips) > 0 {
+		fb.clip = &fb.clips[len(fb.clips)-1]
+	}
+	return nil
+}
+
+// ClipRect returns the active clip rectangle, the full screen when no clip is set
+func (fb *FrameBuffer) ClipRect() (x, y, w, h int) {
+	clip := fb.currentClip()
+	return clip.Min.X, clip.Min.Y, clip.Dx(), clip.Dy()
+}
+
+// currentClip returns the active clip, or the full screen when unclipped
+func (fb *FrameBuffer) currentClip() image.Rectangle {
+	if fb.clip != nil {
+		return *fb.clip
+	}
+	return image.Rect(0, 0, fb.device.Width(), fb.device.Height())
+}
+
+// readPixel reads a pixel from the back buffer when double buffered, otherwise from the device
+func (fb *FrameBuffer) readPixel(x, y int) (byte, error) {
+	fb.lock()
+	defer fb.unlock()
+
+	if fb.back == nil {
+		return fb.device.GetPixel(x, y)
+	}
+
+	if x < 0 || x >= fb.device.Width() || y < 0 || y >= fb.device.Height() {
+		return 0, fmt.Errorf("pixel out of bounds: (%d, %d)", x, y)
+	}
+
+	return fb.back[y*fb.device.Width()+x], nil
+}
+
+// markDirtyPixel extends the region modified since the last Flush to include (x, y)
+func (fb *FrameBuffer) markDirtyPixel(x, y int) {
+	if fb.dirtyX0 < 0 {
+		fb.dirtyX0, fb.dirtyY0, fb.dirtyX1, fb.dirtyY1 = x, y, x, y
+		return
+	}
+
+	fb.dirtyX0 = min(fb.dirtyX0, x)
+	fb.dirtyY0 = min(fb.dirtyY0, y)
+	fb.dirtyX1 = max(fb.dirtyX1, x)
+	fb.dirtyY1 = max(fb.dirtyY1, y)
+}
+
+// BlendPixel blends color over the existing pixel with the given alpha (0-1)
+// The result is rounded to the nearest 4-bit level
+func (fb *FrameBuffer) BlendPixel(x, y int, color byte, alpha float64) error {
+	current, err := fb.readPixel(x, y)
+	if err != nil {
+		return err
+	}
+
+	return fb.SetPixel(x, y, blendLevel(current, color, alpha))
+}
+
+// blendLevel mixes src over dst with the given alpha, clamped to a 4-bit level
+func blendLevel(dst, src byte, alpha float64) byte {
+	alpha = math.Max(0, math.Min(1, alpha))
+
+	level := math.Round(float64(dst&0x0F)*(1-alpha) + float64(src&0x0F)*alpha)
+	return byte(Clamp(int(level), 0, 0x0F))
+}
+
+// SetPixelRGB sets a pixel to a 24-bit color
+// On grayscale devices the color is converted to its 4-bit luminance level
+func (fb *FrameBuffer) SetPixelRGB(x, y int, r, g, b byte) error {
+	rgbDev, ok := fb.device.(device.RGBDevice)
+	if !ok {
+		return fb.SetPixel(x, y, rgbToLevel(r, g, b))
+	}
+
+	if !fb.inClip(x, y) {
+		return nil
+	}
+
+	fb.lock()
+	defer fb.unlock()
+
+	if err := rgbDev.SetPixelRGB(x, y, r, g, b); err != nil {
 		return err
 	}
 
+	fb.markDirtyPixel(x, y)
 	fb.dirty = true
 	return nil
 }
 
+// IsRGB returns whether the underlying device stores full color pixels
+func (fb *FrameBuffer) IsRGB() bool {
+	_, ok := fb.device.(device.RGBDevice)
+	return ok
+}
+
 // GetPixel reads a pixel at the given coordinates
+// When double buffered this reads the back buffer, which may differ from the device until Flush
 func (fb *FrameBuffer) GetPixel(x, y int) (byte, error) {
-	return fb.device.GetPixel(x, y)
+	return fb.readPixel(x, y)
 }
 
 // DrawLine draws a line from (x0, y0) to (x1, y1)
 func (fb *FrameBuffer) DrawLine(x0, y0, x1, y1 int, color byte) error {
 	color = color & 0x0F // Ensure 4-bit color for SSD1322
 
-	DrawLineBresenham(fb, x0, y0, x1, y1, color, func(x, y int, c byte) {
-		// Clamp coordinates
-		if x >= 0 && x < fb.device.Width() && y >= 0 && y < fb.device.Height() {
-			fb.device.SetPixel(x, y, c)
-			fb.dirty = true
-		}
-	})
+	DrawLineBresenham(fb, x0, y0, x1, y1, color, fb.shapePlot())
+
+	return nil
+}
+
+// DrawLineAA draws an anti-aliased line from (x0, y0) to (x1, y1)
+func (fb *FrameBuffer) DrawLineAA(x0, y0, x1, y1 int, color byte) error {
+	color = color & 0x0F
+
+	DrawLineAA(fb, x0, y0, x1, y1, color, fb.shapePlot())
+
+	return nil
+}
+
+// DrawLineThick draws a line of the given pixel width from (x0, y0) to (x1, y1)
+// Thickness values below 1 are clamped to 1
+func (fb *FrameBuffer) DrawLineThick(x0, y0, x1, y1 int, thickness int, color byte) error {
+	if thickness < 1 {
+		thickness = 1
+	}
+
+	color = color & 0x0F
+
+	DrawThickLine(fb, x0, y0, x1, y1, thickness, color, fb.shapePlot())
+
+	return nil
+}
+
+// DrawQuadBezier draws a quadratic Bezier curve with control point (cx, cy)
+func (fb *FrameBuffer) DrawQuadBezier(x0, y0, cx, cy, x1, y1 int, color byte) error {
+	color = color & 0x0F
+
+	DrawQuadBezier(fb, x0, y0, cx, cy, x1, y1, color, fb.shapePlot())
+
+	return nil
+}
+
+// DrawCubicBezier draws a cubic Bezier curve with control points (cx0, cy0) and (cx1, cy1)
+func (fb *FrameBuffer) DrawCubicBezier(x0, y0, cx0, cy0, cx1, cy1, x1, y1 int, color byte) error {
+	color = color & 0x0F
+
+	DrawCubicBezier(fb, x0, y0, cx0, cy0, cx1, cy1, x1, y1, color, fb.shapePlot())
 
 	return nil
 }
@@ -81,12 +367,20 @@ func (fb *FrameBuffer) DrawRect(x, y, w, h int, color byte, filled bool) error {
 
 	color = color & 0x0F
 
-	DrawRect(fb, x, y, w, h, color, filled, func(px, py int, c byte) {
-		if px >= 0 && px < fb.device.Width() && py >= 0 && py < fb.device.Height() {
-			fb.device.SetPixel(px, py, c)
-			fb.dirty = true
-		}
-	})
+	DrawRect(fb, x, y, w, h, color, filled, fb.shapePlot())
+
+	return nil
+}
+
+// DrawRoundedRect draws a rectangle with rounded corners
+func (fb *FrameBuffer) DrawRoundedRect(x, y, w, h, radius int, color byte, filled bool) error {
+	if w < 0 || h < 0 {
+		return fmt.Errorf("invalid rectangle dimensions: %dx%d", w, h)
+	}
+
+	color = color & 0x0F
+
+	DrawRoundedRect(fb, x, y, w, h, radius, color, filled, fb.shapePlot())
 
 	return nil
 }
@@ -99,12 +393,33 @@ func (fb *FrameBuffer) DrawCircle(x, y, r int, color byte, filled bool) error {
 
 	color = color & 0x0F
 
-	DrawCircle(fb, x, y, r, color, filled, func(px, py int, c byte) {
-		if px >= 0 && px < fb.device.Width() && py >= 0 && py < fb.device.Height() {
-			fb.device.SetPixel(px, py, c)
-			fb.dirty = true
-		}
-	})
+	DrawCircle(fb, x, y, r, color, filled, fb.shapePlot())
+
+	return nil
+}
+
+// DrawArc draws a circle outline between startDeg and endDeg (clockwise from 3 o'clock)
+func (fb *FrameBuffer) DrawArc(cx, cy, r int, startDeg, endDeg float64, color byte) error {
+	if r < 0 {
+		return fmt.Errorf("invalid arc radius: %d", r)
+	}
+
+	color = color & 0x0F
+
+	DrawArc(fb, cx, cy, r, startDeg, endDeg, color, fb.shapePlot())
+
+	return nil
+}
+
+// DrawPie draws a filled wedge between startDeg and endDeg (clockwise from 3 o'clock)
+func (fb *FrameBuffer) DrawPie(cx, cy, r int, startDeg, endDeg float64, color byte) error {
+	if r < 0 {
+		return fmt.Errorf("invalid pie radius: %d", r)
+	}
+
+	color = color & 0x0F
+
+	DrawPie(fb, cx, cy, r, startDeg, endDeg, color, fb.shapePlot())
 
 	return nil
 }
@@ -117,12 +432,7 @@ func (fb *FrameBuffer) DrawEllipse(x, y, rx, ry int, color byte, filled bool) er
 
 	color = color & 0x0F
 
-	DrawEllipse(fb, x, y, rx, ry, color, filled, func(px, py int, c byte) {
-		if px >= 0 && px < fb.device.Width() && py >= 0 && py < fb.device.Height() {
-			fb.device.SetPixel(px, py, c)
-			fb.dirty = true
-		}
-	})
+	DrawEllipse(fb, x, y, rx, ry, color, filled, fb.shapePlot())
 
 	return nil
 }
@@ -131,12 +441,7 @@ func (fb *FrameBuffer) DrawEllipse(x, y, rx, ry int, color byte, filled bool) er
 func (fb *FrameBuffer) DrawTriangle(x1, y1, x2, y2, x3, y3 int, color byte, filled bool) error {
 	color = color & 0x0F
 
-	DrawTriangle(fb, x1, y1, x2, y2, x3, y3, color, filled, func(px, py int, c byte) {
-		if px >= 0 && px < fb.device.Width() && py >= 0 && py < fb.device.Height() {
-			fb.device.SetPixel(px, py, c)
-			fb.dirty = true
-		}
-	})
+	DrawTriangle(fb, x1, y1, x2, y2, x3, y3, color, filled, fb.shapePlot())
 
 	return nil
 }
@@ -147,35 +452,551 @@ func (fb *FrameBuffer) FillRegion(x, y, w, h int, color byte) error {
 		return fmt.Errorf("invalid fill region dimensions: %dx%d", w, h)
 	}
 
-	color = color & 0x0F
+	return fb.fillRegion(x, y, w, h, color&0x0F, fb.mode)
+}
 
+// fillRegion is FillRegion with an explicit draw mode
+func (fb *FrameBuffer) fillRegion(x, y, w, h int, color byte, mode DrawMode) error {
 	for py := y; py < y+h; py++ {
 		for px := x; px < x+w; px++ {
-			if px >= 0 && px < fb.device.Width() && py >= 0 && py < fb.device.Height() {
-				fb.device.SetPixel(px, py, color)
-				fb.dirty = true
+			fb.plotMode(px, py, color, mode)
+		}
+	}
+
+	return nil
+}
+
+// FillGradient fills a region with levels interpolated from fromColor to toColor
+// Horizontal gradients run left to right, vertical ones top to bottom; the first
+// column (or row) is fromColor and the last is toColor
+func (fb *FrameBuffer) FillGradient(x, y, w, h int, fromColor, toColor byte, horizontal bool) error {
+	if w < 0 || h < 0 {
+		return fmt.Errorf("invalid gradient region dimensions: %dx%d", w, h)
+	}
+
+	steps := h
+	if horizontal {
+		steps = w
+	}
+
+	from := float64(fromColor & 0x0F)
+	to := float64(toColor & 0x0F)
+
+	for i := 0; i < steps; i++ {
+		t := 0.0
+		if steps > 1 {
+			t = float64(i) / float64(steps-1)
+		}
+		level := byte(math.Round(Lerp(from, to, t)))
+
+		if horizontal {
+			fb.FillRegion(x+i, y, 1, h, level)
+		} else {
+			fb.FillRegion(x, y+i, w, 1, level)
+		}
+	}
+
+	return nil
+}
+
+// CopyRegion copies a w x h block of pixels from (srcX, srcY) to (dstX, dstY)
+// Overlapping regions are copied in the direction that avoids overwriting unread source pixels.
+// Copied pixels replace the destination regardless of the draw mode
+func (fb *FrameBuffer) CopyRegion(srcX, srcY, w, h, dstX, dstY int) error {
+	if w < 0 || h < 0 {
+		return fmt.Errorf("invalid copy region dimensions: %dx%d", w, h)
+	}
+
+	// Walk backwards along an axis when the destination lies further along it
+	rowStart, rowEnd, rowStep := 0, h, 1
+	if dstY > srcY {
+		rowStart, rowEnd, rowStep = h-1, -1, -1
+	}
+	colStart, colEnd, colStep := 0, w, 1
+	if dstX > srcX {
+		colStart, colEnd, colStep = w-1, -1, -1
+	}
+
+	for row := rowStart; row != rowEnd; row += rowStep {
+		for col := colStart; col != colEnd; col += colStep {
+			pixel, err := fb.readPixel(srcX+col, srcY+row)
+			if err != nil {
+				continue
 			}
+
+			px := dstX + col
+			py := dstY + row
+			fb.plotMode(px, py, pixel, DrawModeReplace)
 		}
 	}
 
 	return nil
 }
 
+// MoveRegion moves a w x h block of pixels and clears the vacated area to 0
+func (fb *FrameBuffer) MoveRegion(srcX, srcY, w, h, dstX, dstY int) error {
+	if err := fb.CopyRegion(srcX, srcY, w, h, dstX, dstY); err != nil {
+		return err
+	}
+
+	for py := srcY; py < srcY+h; py++ {
+		for px := srcX; px < srcX+w; px++ {
+			// Keep pixels covered by the destination
+			if px >= dstX && px < dstX+w && py >= dstY && py < dstY+h {
+				continue
+			}
+
+			fb.plotMode(px, py, 0, DrawModeReplace)
+		}
+	}
+
+	return nil
+}
+
+// ScrollVertical shifts the whole framebuffer by dy pixels (positive moves content down)
+// Vacated rows are filled with fill; shifting by the full height or more clears the screen.
+// The draw mode does not apply, so scrolling always moves the content unchanged
+func (fb *FrameBuffer) ScrollVertical(dy int, fill byte) error {
+	w, h := fb.device.Width(), fb.device.Height()
+	if dy <= -h || dy >= h {
+		return fb.fillRegion(0, 0, w, h, fill&0x0F, DrawModeReplace)
+	}
+
+	switch {
+	case dy > 0:
+		if err := fb.CopyRegion(0, 0, w, h-dy, 0, dy); err != nil {
+			return err
+		}
+		return fb.fillRegion(0, 0, w, dy, fill&0x0F, DrawModeReplace)
+	case dy < 0:
+		if err := fb.CopyRegion(0, -dy, w, h+dy, 0, 0); err != nil {
+			return err
+		}
+		return fb.fillRegion(0, h+dy, w, -dy, fill&0x0F, DrawModeReplace)
+	}
+
+	return nil
+}
+
+// ScrollHorizontal shifts the whole framebuffer by dx pixels (positive moves content right)
+// Vacated columns are filled with fill; shifting by the full width or more clears the screen.
+// The draw mode does not apply, so scrolling always moves the content unchanged
+func (fb *FrameBuffer) ScrollHorizontal(dx int, fill byte) error {
+	w, h := fb.device.Width(), fb.device.Height()
+	if dx <= -w || dx >= w {
+		return fb.fillRegion(0, 0, w, h, fill&0x0F, DrawModeReplace)
+	}
+
+	switch {
+	case dx > 0:
+		if err := fb.CopyRegion(0, 0, w-dx, h, dx, 0); err != nil {
+			return err
+		}
+		return fb.fillRegion(0, 0, dx, h, fill&0x0F, DrawModeReplace)
+	case dx < 0:
+		if err := fb.CopyRegion(-dx, 0, w+dx, h, 0, 0); err != nil {
+			return err
+		}
+		return fb.fillRegion(w+dx, 0, -dx, h, fill&0x0F, DrawModeReplace)
+	}
+
+	return nil
+}
+
+// DrawSprite draws a nibble-packed 4-bit sprite at (x, y)
+// Pixels are stored row by row, two per byte with the first pixel in the low nibble.
+// Pixels equal to the transparent index are skipped
+func (fb *FrameBuffer) DrawSprite(x, y, w, h int, data []byte, transparent byte) error {
+	if w < 0 || h < 0 {
+		return fmt.Errorf("invalid sprite dimensions: %dx%d", w, h)
+	}
+
+	if expected := (w*h + 1) / 2; len(data) != expected {
+		return fmt.Errorf("sprite data size mismatch: expected %d bytes, got %d", expected, len(data))
+	}
+
+	transparent = transparent & 0x0F
+
+	for row := 0; row < h; row++ {
+		py := y + row
+		if py < 0 || py >= fb.device.Height() {
+			continue
+		}
+
+		for col := 0; col < w; col++ {
+			px := x + col
+			if px < 0 || px >= fb.device.Width() {
+				continue
+			}
+
+			index := row*w + col
+			pixel := data[index/2]
+			if index%2 == 0 {
+				pixel &= 0x0F
+			} else {
+				pixel >>= 4
+			}
+
+			if pixel == transparent {
+				continue
+			}
+
+			fb.plot(px, py, pixel)
+		}
+	}
+
+	return nil
+}
+
+// FloodFill replaces the connected region of the starting pixel's color with color
+// Uses an explicit scanline stack, so large regions don't recurse deeply.
+// The region stops at the active clip; a start outside the clip fills nothing.
+// Pixels are written with the draw mode, each one once
+func (fb *FrameBuffer) FloodFill(x, y int, color byte) error {
+	width := fb.device.Width()
+	height := fb.device.Height()
+
+	if x < 0 || x >= width || y < 0 || y >= height {
+		return fmt.Errorf("flood fill start out of bounds: (%d, %d)", x, y)
+	}
+
+	// Pixels outside the clip are never written, so they would keep matching and be queued forever
+	clip := fb.currentClip()
+	if !image.Pt(x, y).In(clip) {
+		return nil
+	}
+
+	color = color & 0x0F
+
+	target, err := fb.readPixel(x, y)
+	if err != nil {
+		return err
+	}
+	if target == color && fb.mode == DrawModeReplace {
+		return nil
+	}
+
+	// Under a draw mode a filled pixel may keep the target level, so the region is
+	// tracked with a visited set rather than by re-reading pixels
+	visited := make([]bool, width*height)
+	matches := func(px, py int) bool {
+		if visited[py*width+px] {
+			return false
+		}
+		pixel, err := fb.readPixel(px, py)
+		return err == nil && pixel == target
+	}
+
+	stack := [][2]int{{x, y}}
+	for len(stack) > 0 {
+		seed := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		sx, sy := seed[0], seed[1]
+		if !matches(sx, sy) {
+			continue
+		}
+
+		// Extend the span left and right from the seed
+		left := sx
+		for left > clip.Min.X && matches(left-1, sy) {
+			left--
+		}
+		right := sx
+		for right < clip.Max.X-1 && matches(right+1, sy) {
+			right++
+		}
+
+		for px := left; px <= right; px++ {
+			visited[sy*width+px] = true
+			fb.plot(px, sy, color)
+		}
+
+		// Queue one seed per matching run in the rows above and below
+		for _, ny := range []int{sy - 1, sy + 1} {
+			if ny < clip.Min.Y || ny >= clip.Max.Y {
+				continue
+			}
+
+			inRun := false
+			for px := left; px <= right; px++ {
+				if matches(px, ny) {
+					if !inRun {
+						stack = append(stack, [2]int{px, ny})
+						inRun = true
+					}
+				} else {
+					inRun = false
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// FillNoise fills a rectangular region with random levels between minLevel and maxLevel
+// The same seed always produces the same pattern
+func (fb *FrameBuffer) FillNoise(x, y, w, h int, seed int64, minLevel, maxLevel byte) error {
+	if w < 0 || h < 0 {
+		return fmt.Errorf("invalid noise region dimensions: %dx%d", w, h)
+	}
+
+	minLevel = minLevel & 0x0F
+	maxLevel = maxLevel & 0x0F
+	if minLevel > maxLevel {
+		return fmt.Errorf("invalid noise levels: min 0x%02X > max 0x%02X", minLevel, maxLevel)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	span := int(maxLevel-minLevel) + 1
+
+	for py := y; py < y+h; py++ {
+		for px := x; px < x+w; px++ {
+			// Always draw a value so the pattern doesn't depend on clipping
+			level := minLevel + byte(rng.Intn(span))
+			fb.plot(px, py, level)
+		}
+	}
+
+	return nil
+}
+
+// FillNoiseFrame fills a region with noise reseeded for the given animation frame
+// Useful inside an AnimationFunc for a reproducible "static TV" effect
+func (fb *FrameBuffer) FillNoiseFrame(x, y, w, h int, seed int64, frame int, minLevel, maxLevel byte) error {
+	return fb.FillNoise(x, y, w, h, noiseFrameSeed(seed, frame), minLevel, maxLevel)
+}
+
+// noiseFrameSeed mixes seed and frame, so different seeds never replay each other's frames shifted in time
+func noiseFrameSeed(seed int64, frame int) int64 {
+	return int64(uint64(seed)*0x9E3779B97F4A7C15 ^ uint64(frame))
+}
+
 // Flush commits any changes to the device's VRAM
+// When double buffered, the modified part of the back buffer is written to the device in one pass
 func (fb *FrameBuffer) Flush() error {
+	fb.lock()
+	defer fb.unlock()
+
+	return fb.flush()
+}
+
+// flush commits pending changes, with the lock held when thread safe
+func (fb *FrameBuffer) flush() error {
 	if !fb.dirty {
 		return nil
 	}
 
+	if fb.back != nil && fb.dirtyX0 >= 0 {
+		width := fb.device.Width()
+		for y := fb.dirtyY0; y <= fb.dirtyY1; y++ {
+			for x := fb.dirtyX0; x <= fb.dirtyX1; x++ {
+				if err := fb.device.SetPixel(x, y, fb.back[y*width+x]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	// Update internal buffer from device
 	copy(fb.buffer, fb.device.GetFrameBuffer())
 	fb.dirty = false
+	fb.dirtyX0 = -1
+
+	return nil
+}
+
+// FlushDirty commits pending changes like Flush and returns the region it committed
+// Returns (-1, -1, -1, -1) when nothing changed since the last flush.
+// The device keeps its own dirty region for the renderer to consume
+func (fb *FrameBuffer) FlushDirty() (x0, y0, x1, y1 int, err error) {
+	fb.lock()
+	defer fb.unlock()
+
+	x0, y0, x1, y1 = fb.dirtyX0, fb.dirtyY0, fb.dirtyX1, fb.dirtyY1
+	if x0 < 0 {
+		return -1, -1, -1, -1, nil
+	}
+
+	if err := fb.flush(); err != nil {
+		return -1, -1, -1, -1, err
+	}
+
+	return x0, y0, x1, y1, nil
+}
+
+// Snapshot is an opaque copy of the framebuffer contents taken by FrameBuffer.Snapshot
+type Snapshot struct {
+	width  int
+	height int
+	vram   []byte // Device VRAM in its native packing
+	back   []byte // Back buffer, nil when taken in immediate mode
+}
+
+// dirtyMarker is implemented by devices that accept an explicit dirty region (e.g. BaseDevice)
+type dirtyMarker interface {
+	MarkDirty(x0, y0, x1, y1 int)
+}
+
+// Snapshot deep-copies the device VRAM, and the back buffer when double buffered
+// Restore puts it back, for undo or for temporary overlays such as tooltips
+func (fb *FrameBuffer) Snapshot() Snapshot {
+	fb.lock()
+	defer fb.unlock()
+
+	snap := Snapshot{
+		width:  fb.device.Width(),
+		height: fb.device.Height(),
+		vram:   append([]byte(nil), fb.device.GetFrameBuffer()...),
+	}
+	if fb.back != nil {
+		snap.back = append([]byte(nil), fb.back...)
+	}
+
+	return snap
+}
 
+// Restore puts back the contents captured by Snapshot and marks the full screen dirty
+// When double buffered the back buffer is restored and Flush commits it; otherwise VRAM is restored directly
+func (fb *FrameBuffer) Restore(snap Snapshot) error {
+	fb.lock()
+	defer fb.unlock()
+
+	width, height := fb.device.Width(), fb.device.Height()
+	vram := fb.device.GetFrameBuffer()
+	if snap.width != width || snap.height != height || len(snap.vram) != len(vram) {
+		return fmt.Errorf("snapshot of %dx%d does not match %dx%d framebuffer", snap.width, snap.height, width, height)
+	}
+
+	if fb.back != nil {
+		if snap.back == nil {
+			return fmt.Errorf("snapshot was taken without double buffering")
+		}
+		copy(fb.back, snap.back)
+	} else {
+		copy(vram, snap.vram)
+		copy(fb.buffer, vram)
+		if marker, ok := fb.device.(dirtyMarker); ok {
+			marker.MarkDirty(0, 0, width-1, height-1)
+		}
+	}
+
+	fb.markDirtyPixel(0, 0)
+	fb.markDirtyPixel(width-1, height-1)
+	fb.dirty = true
+	return nil
+}
+
+// SetDrawMode sets how SetPixel and every primitive combine colors with the existing pixels
+// XOR is useful for cursors and selections that must stay visible over any background
+func (fb *FrameBuffer) SetDrawMode(mode DrawMode) error {
+	if mode < DrawModeReplace || mode > DrawModeAverage {
+		return fmt.Errorf("invalid draw mode: %d", mode)
+	}
+
+	fb.mode = mode
 	return nil
 }
 
+// DrawMode returns the current draw mode
+func (fb *FrameBuffer) DrawMode() DrawMode {
+	return fb.mode
+}
+
+// SetThreadSafe guards pixel reads, writes and flushes with a mutex, so one goroutine can
+// draw while another flushes or renders; set it before sharing the framebuffer
+// Drawing calls are guarded pixel by pixel, so a shape may be seen half drawn.
+// Pixel access, Flush, IsDirty and double-buffer toggling are covered; the draw mode and
+// clip stack are not, so change them only from the goroutine that draws
+func (fb *FrameBuffer) SetThreadSafe(enabled bool) {
+	fb.safe = enabled
+}
+
+// IsThreadSafe returns whether pixel access is guarded by a mutex
+func (fb *FrameBuffer) IsThreadSafe() bool {
+	return fb.safe
+}
+
+// Lock holds the framebuffer mutex so code outside the framebuffer, like a renderer reading
+// device VRAM, can't race with drawing; it does nothing unless thread safe
+// Don't draw through the framebuffer while holding the lock, as the mutex is not reentrant
+func (fb *FrameBuffer) Lock() {
+	fb.lock()
+}
+
+// Unlock releases the mutex held by Lock
+func (fb *FrameBuffer) Unlock() {
+	fb.unlock()
+}
+
+// lock takes the mutex when thread safe
+func (fb *FrameBuffer) lock() {
+	if fb.safe {
+		fb.mu.Lock()
+	}
+}
+
+// unlock releases the mutex when thread safe
+func (fb *FrameBuffer) unlock() {
+	if fb.safe {
+		fb.mu.Unlock()
+	}
+}
+
+// SetDoubleBuffered switches between immediate mode (the default) and double buffering
+// When enabled, drawing modifies an off-screen back buffer that Flush commits to the device.
+// Disabling commits any pending changes first
+func (fb *FrameBuffer) SetDoubleBuffered(enabled bool) error {
+	fb.lock()
+	defer fb.unlock()
+
+	if enabled == (fb.back != nil) {
+		return nil
+	}
+
+	if !enabled {
+		err := fb.flush()
+		fb.back = nil
+		return err
+	}
+
+	if fb.IsRGB() {
+		return fmt.Errorf("double buffering is not supported on RGB devices")
+	}
+
+	width := fb.device.Width()
+	height := fb.device.Height()
+
+	// Start from the current device contents
+	back := make([]byte, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixel, err := fb.device.GetPixel(x, y)
+			if err != nil {
+				return err
+			}
+			back[y*width+x] = pixel & 0x0F
+		}
+	}
+
+	fb.back = back
+	return nil
+}
+
+// IsDoubleBuffered returns whether drawing goes to an off-screen back buffer
+func (fb *FrameBuffer) IsDoubleBuffered() bool {
+	fb.lock()
+	defer fb.unlock()
+
+	return fb.back != nil
+}
+
 // IsDirty returns whether the framebuffer has been modified since last flush
 func (fb *FrameBuffer) IsDirty() bool {
+	fb.lock()
+	defer fb.unlock()
+
 	return fb.dirty
 }
 
@@ -191,6 +1012,43 @@ func (fb *FrameBuffer) GetDevice() device.Device {
 	return fb.device
 }
 
+// SetSafeArea sets the safe-area insets honored by DrawAlignedLine, DrawCenteredLine, DrawInSafeArea and the charts
+func (fb *FrameBuffer) SetSafeArea(insets Insets) error {
+	if insets.Left < 0 || insets.Top < 0 || insets.Right < 0 || insets.Bottom < 0 {
+		return fmt.Errorf("invalid safe area insets: %+v", insets)
+	}
+
+	if insets.Left+insets.Right >= fb.device.Width() || insets.Top+insets.Bottom >= fb.device.Height() {
+		return fmt.Errorf("safe area insets leave no drawable area: %+v", insets)
+	}
+
+	fb.safeArea = insets
+	return nil
+}
+
+// SafeArea returns the current safe-area insets
+func (fb *FrameBuffer) SafeArea() Insets {
+	return fb.safeArea
+}
+
+// SafeBounds returns the drawable area inside the safe-area insets
+func (fb *FrameBuffer) SafeBounds() (x, y, w, h int) {
+	x = fb.safeArea.Left
+	y = fb.safeArea.Top
+	w = fb.device.Width() - fb.safeArea.Left - fb.safeArea.Right
+	h = fb.device.Height() - fb.safeArea.Top - fb.safeArea.Bottom
+	return x, y, w, h
+}
+
+// safeRect clips the box at (x, y) to the safe area; the returned size is 0 or less when nothing remains
+func (fb *FrameBuffer) safeRect(x, y, w, h int) (int, int, int, int) {
+	safeX, safeY, safeW, safeH := fb.SafeBounds()
+
+	x0, y0 := max(x, safeX), max(y, safeY)
+	x1, y1 := min(x+w, safeX+safeW), min(y+h, safeY+safeH)
+	return x0, y0, x1 - x0, y1 - y0
+}
+
 // Width returns the framebuffer width
 func (fb *FrameBuffer) Width() int {
 	return fb.device.Width()
@@ -200,3 +1058,9 @@ func (fb *FrameBuffer) Width() int {
 func (fb *FrameBuffer) Height() int {
 	return fb.device.Height()
 }
+
+// rgbToLevel converts a 24-bit color to a 4-bit grayscale level
+func rgbToLevel(r, g, b byte) byte {
+	gray := (uint32(r)*77 + uint32(g)*150 + uint32(b)*29) / 256
+	return byte(gray >> 4)
+}
@@ -1,6 +1,13 @@
 package graphics
 
 import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/flavioheleno/oled-emulator/device"
@@ -179,3 +186,1519 @@ func TestFrameBufferFlush(t *testing.T) {
 		t.Error("framebuffer should not be dirty after flush")
 	}
 }
+
+func TestDrawIcon(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	fb := NewFrameBuffer(dev)
+
+	if err := DrawIcon(fb, 10, 10, "arrow_up", 0x0F); err != nil {
+		t.Fatalf("draw icon failed: %v", err)
+	}
+
+	if count := countSetPixels(fb); count != 28 {
+		t.Errorf("expected 28 pixels set for arrow_up, got %d", count)
+	}
+
+	fb.Clear(0x00)
+
+	if err := DrawIcon(fb, 10, 10, "arrow_up_16", 0x0F); err != nil {
+		t.Fatalf("draw icon failed: %v", err)
+	}
+
+	if count := countSetPixels(fb); count != 112 {
+		t.Errorf("expected 112 pixels set for arrow_up_16, got %d", count)
+	}
+
+	if err := DrawIcon(fb, 0, 0, "missing", 0x0F); err == nil {
+		t.Error("should return error for unknown icon")
+	}
+}
+
+// countSetPixels counts the non-zero pixels in the framebuffer
+func countSetPixels(fb *FrameBuffer) int {
+	count := 0
+	for y := 0; y < fb.Height(); y++ {
+		for x := 0; x < fb.Width(); x++ {
+			pixel, _ := fb.GetPixel(x, y)
+			if pixel != 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestFillNoiseDeterministic(t *testing.T) {
+	fbA := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fbB := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fbC := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fbA.FillNoise(0, 0, 64, 32, 42, 0x00, 0x0F)
+	fbB.FillNoise(0, 0, 64, 32, 42, 0x00, 0x0F)
+	fbC.FillNoise(0, 0, 64, 32, 43, 0x00, 0x0F)
+
+	same := true
+	differs := false
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			a, _ := fbA.GetPixel(x, y)
+			b, _ := fbB.GetPixel(x, y)
+			c, _ := fbC.GetPixel(x, y)
+			if a != b {
+				same = false
+			}
+			if a != c {
+				differs = true
+			}
+		}
+	}
+
+	if !same {
+		t.Error("fills with the same seed should be identical")
+	}
+	if !differs {
+		t.Error("fills with different seeds should differ")
+	}
+}
+
+func TestFillNoiseFrameSeedsIndependent(t *testing.T) {
+	fbA := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fbB := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	// Seed 1 at frame 1 must not be seed 2 at frame 0
+	fbA.FillNoiseFrame(0, 0, 64, 32, 1, 1, 0x00, 0x0F)
+	fbB.FillNoiseFrame(0, 0, 64, 32, 2, 0, 0x00, 0x0F)
+
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			a, _ := fbA.GetPixel(x, y)
+			b, _ := fbB.GetPixel(x, y)
+			if a != b {
+				return
+			}
+		}
+	}
+	t.Error("different seeds should not produce the same frame")
+}
+
+func TestFrameBufferSetPixelRGB(t *testing.T) {
+	rgb := device.NewSSD1351(128, 128)
+	fb := NewFrameBuffer(rgb)
+
+	if !fb.IsRGB() {
+		t.Fatal("SSD1351 framebuffer should report RGB")
+	}
+
+	fb.SetPixelRGB(3, 4, 0x10, 0x20, 0x30)
+	if r, g, b, _ := rgb.GetPixelRGB(3, 4); r != 0x10 || g != 0x20 || b != 0x30 {
+		t.Errorf("expected true color (16,32,48), got (%d,%d,%d)", r, g, b)
+	}
+
+	// Grayscale devices receive the luminance level
+	gray := NewFrameBuffer(device.NewSSD1322(256, 64))
+	if gray.IsRGB() {
+		t.Error("SSD1322 framebuffer should not report RGB")
+	}
+	gray.SetPixelRGB(0, 0, 0xFF, 0xFF, 0xFF)
+	if level, _ := gray.GetPixel(0, 0); level != 0x0F {
+		t.Errorf("expected level 0x0F, got 0x%X", level)
+	}
+}
+
+func TestFrameBufferDrawLineThick(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	// Horizontal line 3 pixels wide spans rows 9-11
+	fb.DrawLineThick(10, 10, 40, 10, 3, 0x0F)
+	for y := 8; y <= 12; y++ {
+		pixel, _ := fb.GetPixel(25, y)
+		lit := y >= 9 && y <= 11
+		if (pixel != 0) != lit {
+			t.Errorf("row %d: expected lit=%v, got 0x%X", y, lit, pixel)
+		}
+	}
+
+	// Even widths cover exactly that many columns
+	fb.Clear(0)
+	fb.DrawLineThick(20, 5, 20, 30, 4, 0x0F)
+	lit := 0
+	for x := 15; x <= 25; x++ {
+		if pixel, _ := fb.GetPixel(x, 15); pixel != 0 {
+			lit++
+		}
+	}
+	if lit != 4 {
+		t.Errorf("expected 4 columns for thickness 4, got %d", lit)
+	}
+
+	// Diagonals have no gaps along the center line
+	fb.Clear(0)
+	fb.DrawLineThick(0, 0, 40, 30, 2, 0x0F)
+	DrawLineBresenham(fb, 0, 0, 40, 30, 0x0F, func(x, y int, c byte) {
+		if pixel, _ := fb.GetPixel(x, y); pixel == 0 {
+			t.Errorf("gap at (%d, %d)", x, y)
+		}
+	})
+
+	// Thickness below 1 degrades to a 1px line
+	fb.Clear(0)
+	fb.DrawLineThick(0, 50, 10, 50, 0, 0x0F)
+	if countSetPixels(fb) != 11 {
+		t.Errorf("expected 11 pixels, got %d", countSetPixels(fb))
+	}
+}
+
+func TestFrameBufferDrawLineAA(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.DrawLineAA(0, 0, 20, 7, 0x0F)
+
+	if pixel, _ := fb.GetPixel(0, 0); pixel != 0x0F {
+		t.Errorf("start point should be full color, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(20, 7); pixel != 0x0F {
+		t.Errorf("end point should be full color, got 0x%X", pixel)
+	}
+
+	partial := 0
+	for y := 0; y < 8; y++ {
+		for x := 1; x < 20; x++ {
+			if pixel, _ := fb.GetPixel(x, y); pixel > 0 && pixel < 0x0F {
+				partial++
+			}
+		}
+	}
+	if partial == 0 {
+		t.Error("expected anti-aliased pixels between 0 and full color")
+	}
+
+	// Half coverage of 0x0F rounds to 0x08
+	if level := coverageLevel(0x0F, 0.5); level != 0x08 {
+		t.Errorf("expected coverage level 0x08, got 0x%X", level)
+	}
+}
+
+func TestFrameBufferDrawRoundedRect(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.DrawRoundedRect(10, 10, 30, 20, 5, 0x0F, false)
+
+	// Corners are cut, edges are straight
+	if pixel, _ := fb.GetPixel(10, 10); pixel != 0 {
+		t.Error("top-left corner pixel should be empty")
+	}
+	if pixel, _ := fb.GetPixel(25, 10); pixel == 0 {
+		t.Error("top edge should be drawn")
+	}
+	if pixel, _ := fb.GetPixel(10, 20); pixel == 0 {
+		t.Error("left edge should be drawn")
+	}
+	if pixel, _ := fb.GetPixel(25, 20); pixel != 0 {
+		t.Error("outline should not fill the interior")
+	}
+
+	// Filled variant covers the body and corner arcs, but not the corners
+	fb.Clear(0)
+	fb.DrawRoundedRect(10, 10, 30, 20, 5, 0x0F, true)
+	if pixel, _ := fb.GetPixel(25, 20); pixel == 0 {
+		t.Error("filled interior should be drawn")
+	}
+	if pixel, _ := fb.GetPixel(12, 12); pixel == 0 {
+		t.Error("filled corner arc should be drawn")
+	}
+	if pixel, _ := fb.GetPixel(39, 29); pixel != 0 {
+		t.Error("bottom-right corner pixel should be empty")
+	}
+
+	// Radius 0 matches a plain rectangle
+	fb.Clear(0)
+	fb.DrawRoundedRect(0, 0, 10, 10, 0, 0x0F, true)
+	if countSetPixels(fb) != 100 {
+		t.Errorf("expected 100 pixels, got %d", countSetPixels(fb))
+	}
+
+	// Oversized radius is clamped and stays inside the bounds
+	fb.Clear(0)
+	fb.DrawRoundedRect(50, 10, 10, 6, 100, 0x0F, true)
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 256; x++ {
+			if pixel, _ := fb.GetPixel(x, y); pixel != 0 && (x < 50 || x > 59 || y < 10 || y > 15) {
+				t.Fatalf("pixel (%d, %d) drawn outside the rectangle", x, y)
+			}
+		}
+	}
+}
+
+func TestFrameBufferDrawBezier(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.DrawQuadBezier(10, 50, 60, 0, 110, 50, 0x0F)
+	for _, p := range [][2]int{{10, 50}, {110, 50}, {60, 25}} {
+		if pixel, _ := fb.GetPixel(p[0], p[1]); pixel == 0 {
+			t.Errorf("quadratic curve should pass through (%d, %d)", p[0], p[1])
+		}
+	}
+
+	fb.Clear(0)
+	fb.DrawCubicBezier(0, 60, 40, 0, 80, 0, 120, 60, 0x0F)
+	if pixel, _ := fb.GetPixel(0, 60); pixel == 0 {
+		t.Error("cubic curve should start exactly at (0, 60)")
+	}
+	if pixel, _ := fb.GetPixel(120, 60); pixel == 0 {
+		t.Error("cubic curve should end exactly at (120, 60)")
+	}
+
+	// The curve is continuous: every column between the endpoints is touched
+	for x := 0; x <= 120; x++ {
+		found := false
+		for y := 0; y < 64; y++ {
+			if pixel, _ := fb.GetPixel(x, y); pixel != 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("gap in cubic curve at column %d", x)
+		}
+	}
+}
+
+func TestFrameBufferDrawArc(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	// Bottom-right quadrant: from 3 o'clock clockwise to 6 o'clock
+	fb.DrawArc(30, 30, 10, 0, 90, 0x0F)
+	if pixel, _ := fb.GetPixel(40, 30); pixel == 0 {
+		t.Error("arc should include 3 o'clock")
+	}
+	if pixel, _ := fb.GetPixel(30, 40); pixel == 0 {
+		t.Error("arc should include 6 o'clock")
+	}
+	if pixel, _ := fb.GetPixel(20, 30); pixel != 0 {
+		t.Error("arc should not include 9 o'clock")
+	}
+
+	// Wrap-around from 270 (12 o'clock) through 0 to 90
+	fb.Clear(0)
+	fb.DrawArc(30, 30, 10, 270, 90, 0x0F)
+	if pixel, _ := fb.GetPixel(30, 20); pixel == 0 {
+		t.Error("wrapped arc should include 12 o'clock")
+	}
+	if pixel, _ := fb.GetPixel(20, 30); pixel != 0 {
+		t.Error("wrapped arc should not include 9 o'clock")
+	}
+
+	// A full sweep matches the circle outline
+	fb.Clear(0)
+	fb.DrawArc(30, 30, 10, 0, 360, 0x0F)
+	arcPixels := countSetPixels(fb)
+	fb.Clear(0)
+	fb.DrawCircle(30, 30, 10, 0x0F, false)
+	if arcPixels != countSetPixels(fb) {
+		t.Errorf("full arc should match circle: %d vs %d pixels", arcPixels, countSetPixels(fb))
+	}
+}
+
+func TestFrameBufferDrawPie(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.DrawPie(30, 30, 10, 0, 90, 0x0F)
+	if pixel, _ := fb.GetPixel(30, 30); pixel == 0 {
+		t.Error("pie should include the center")
+	}
+	if pixel, _ := fb.GetPixel(35, 35); pixel == 0 {
+		t.Error("pie should fill the wedge")
+	}
+	if pixel, _ := fb.GetPixel(25, 25); pixel != 0 {
+		t.Error("pie should not fill the opposite quadrant")
+	}
+}
+
+func TestFrameBufferFloodFill(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.DrawRect(10, 10, 20, 15, 0x0F, false)
+	if err := fb.FloodFill(15, 15, 0x07); err != nil {
+		t.Fatalf("FloodFill failed: %v", err)
+	}
+
+	for y := 10; y < 25; y++ {
+		for x := 10; x < 30; x++ {
+			pixel, _ := fb.GetPixel(x, y)
+			border := x == 10 || x == 29 || y == 10 || y == 24
+			if border && pixel != 0x0F {
+				t.Fatalf("outline pixel (%d, %d) changed to 0x%X", x, y, pixel)
+			}
+			if !border && pixel != 0x07 {
+				t.Fatalf("interior pixel (%d, %d) not filled, got 0x%X", x, y, pixel)
+			}
+		}
+	}
+
+	// The fill does not leak outside the outline
+	if pixel, _ := fb.GetPixel(5, 5); pixel != 0 {
+		t.Error("fill leaked outside the rectangle")
+	}
+
+	// Filling with the same color is a no-op
+	if err := fb.FloodFill(15, 15, 0x07); err != nil {
+		t.Errorf("same-color fill should succeed, got %v", err)
+	}
+
+	if err := fb.FloodFill(-1, 0, 0x0F); err == nil {
+		t.Error("expected error for out-of-bounds start")
+	}
+}
+
+func TestFrameBufferFloodFillClipped(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.PushClip(0, 0, 10, 10)
+	if err := fb.FloodFill(5, 5, 0x0F); err != nil {
+		t.Fatalf("FloodFill failed: %v", err)
+	}
+
+	if pixel, _ := fb.GetPixel(9, 9); pixel != 0x0F {
+		t.Errorf("pixel inside the clip not filled, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(10, 5); pixel != 0 {
+		t.Errorf("fill leaked outside the clip, got 0x%X", pixel)
+	}
+
+	// A start outside the clip fills nothing
+	if err := fb.FloodFill(20, 20, 0x0F); err != nil {
+		t.Fatalf("FloodFill failed: %v", err)
+	}
+	if pixel, _ := fb.GetPixel(20, 20); pixel != 0 {
+		t.Errorf("start outside the clip should not be filled, got 0x%X", pixel)
+	}
+}
+
+func TestFrameBufferFloodFillDrawModes(t *testing.T) {
+	tests := []struct {
+		mode     DrawMode
+		expected byte
+	}{
+		{DrawModeXor, 0x08 ^ 0x04},
+		{DrawModeOr, 0x08 | 0x04},
+		{DrawModeAnd, 0x08 & 0x04},
+		{DrawModeMax, 0x08},
+		{DrawModeMin, 0x04},
+		{DrawModeAverage, 0x06},
+	}
+
+	for _, tt := range tests {
+		fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+		fb.Clear(0x08)
+		fb.SetDrawMode(tt.mode)
+
+		if err := fb.FloodFill(5, 5, 0x04); err != nil {
+			t.Fatalf("mode %d: FloodFill failed: %v", tt.mode, err)
+		}
+
+		for _, pt := range [][2]int{{5, 5}, {0, 0}, {255, 63}} {
+			if pixel, _ := fb.GetPixel(pt[0], pt[1]); pixel != tt.expected {
+				t.Errorf("mode %d: pixel (%d, %d) expected 0x%X, got 0x%X", tt.mode, pt[0], pt[1], tt.expected, pixel)
+			}
+		}
+	}
+}
+
+func TestFrameBufferBlendPixel(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.BlendPixel(0, 0, 0x0F, 0.5)
+	if pixel, _ := fb.GetPixel(0, 0); pixel != 0x08 {
+		t.Errorf("0x0F over 0x00 at alpha 0.5: expected 0x08, got 0x%X", pixel)
+	}
+
+	fb.SetPixel(1, 0, 0x0A)
+	fb.BlendPixel(1, 0, 0x00, 0)
+	if pixel, _ := fb.GetPixel(1, 0); pixel != 0x0A {
+		t.Errorf("alpha 0 should keep the existing pixel, got 0x%X", pixel)
+	}
+
+	fb.BlendPixel(1, 0, 0x0F, 2)
+	if pixel, _ := fb.GetPixel(1, 0); pixel != 0x0F {
+		t.Errorf("alpha above 1 should clamp to the new color, got 0x%X", pixel)
+	}
+
+	if err := fb.BlendPixel(-1, 0, 0x0F, 0.5); err == nil {
+		t.Error("expected error for out-of-bounds pixel")
+	}
+}
+
+func TestFrameBufferCopyRegion(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	// Gradient row so the copy direction matters for overlapping regions
+	for x := 0; x < 10; x++ {
+		fb.SetPixel(10+x, 5, byte(x+1))
+	}
+
+	fb.CopyRegion(10, 5, 10, 1, 13, 5)
+	for x := 0; x < 10; x++ {
+		if pixel, _ := fb.GetPixel(13+x, 5); pixel != byte(x+1) {
+			t.Errorf("overlapping right copy: pixel %d expected 0x%X, got 0x%X", x, x+1, pixel)
+		}
+	}
+	if pixel, _ := fb.GetPixel(10, 5); pixel != 0x01 {
+		t.Error("source pixels outside the destination should be kept")
+	}
+}
+
+func TestFrameBufferMoveRegion(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.FillRegion(20, 10, 10, 8, 0x0F)
+	fb.MoveRegion(20, 10, 10, 8, 15, 10)
+
+	for y := 10; y < 18; y++ {
+		for x := 10; x < 35; x++ {
+			pixel, _ := fb.GetPixel(x, y)
+			inside := x >= 15 && x < 25
+			if inside && pixel != 0x0F {
+				t.Fatalf("pixel (%d, %d) should be filled after the move", x, y)
+			}
+			if !inside && pixel != 0 {
+				t.Fatalf("pixel (%d, %d) should be cleared after the move, got 0x%X", x, y, pixel)
+			}
+		}
+	}
+}
+
+func TestFrameBufferScrollVertical(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.SetPixel(5, 1, 0x0A)
+	fb.SetPixel(7, 0, 0x0F)
+	fb.Flush()
+
+	if err := fb.ScrollVertical(-1, 0x02); err != nil {
+		t.Fatalf("ScrollVertical failed: %v", err)
+	}
+
+	if pixel, _ := fb.GetPixel(5, 0); pixel != 0x0A {
+		t.Errorf("expected row 1 content on row 0, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(5, 1); pixel != 0 {
+		t.Errorf("expected row 1 to hold row 2 content, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(7, 63); pixel != 0x02 {
+		t.Errorf("expected vacated row filled with 0x2, got 0x%X", pixel)
+	}
+
+	x0, y0, x1, y1, _ := fb.FlushDirty()
+	if x0 != 0 || y0 != 0 || x1 != 255 || y1 != 63 {
+		t.Errorf("expected full dirty region, got (%d, %d)-(%d, %d)", x0, y0, x1, y1)
+	}
+
+	fb.ScrollVertical(64, 0x01)
+	if countSetPixels(fb) != 256*64 {
+		t.Error("scrolling by the full height should clear to the fill color")
+	}
+}
+
+func TestFrameBufferScrollHorizontal(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.SetPixel(10, 3, 0x0F)
+	fb.ScrollHorizontal(4, 0)
+
+	if pixel, _ := fb.GetPixel(14, 3); pixel != 0x0F {
+		t.Errorf("expected content shifted right by 4, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(10, 3); pixel != 0 {
+		t.Errorf("expected original position cleared, got 0x%X", pixel)
+	}
+}
+
+func TestFrameBufferDrawSprite(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fb.Clear(0x03)
+
+	// 3x2 sprite: row 0 = F, 0, A; row 1 = 0, 5, F (0 is transparent)
+	sprite := []byte{0x0F, 0x0A, 0xF5}
+
+	if err := fb.DrawSprite(10, 10, 3, 2, sprite, 0x00); err != nil {
+		t.Fatalf("DrawSprite failed: %v", err)
+	}
+
+	expected := [][]byte{
+		{0x0F, 0x03, 0x0A},
+		{0x03, 0x05, 0x0F},
+	}
+	for row, line := range expected {
+		for col, want := range line {
+			if pixel, _ := fb.GetPixel(10+col, 10+row); pixel != want {
+				t.Errorf("pixel (%d, %d): expected 0x%X, got 0x%X", col, row, want, pixel)
+			}
+		}
+	}
+
+	if err := fb.DrawSprite(0, 0, 3, 2, sprite[:2], 0x00); err == nil {
+		t.Error("expected error for short sprite data")
+	}
+
+	// Sprites partially off screen are clipped
+	if err := fb.DrawSprite(254, 63, 3, 2, sprite, 0x00); err != nil {
+		t.Errorf("clipped sprite should draw, got %v", err)
+	}
+	if pixel, _ := fb.GetPixel(254, 63); pixel != 0x0F {
+		t.Errorf("visible part of clipped sprite should be drawn, got 0x%X", pixel)
+	}
+}
+
+func TestFrameBufferDoubleBuffered(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	fb := NewFrameBuffer(dev)
+
+	dev.SetPixel(0, 0, 0x05)
+	if err := fb.SetDoubleBuffered(true); err != nil {
+		t.Fatalf("SetDoubleBuffered failed: %v", err)
+	}
+
+	// The back buffer starts from the device contents
+	if pixel, _ := fb.GetPixel(0, 0); pixel != 0x05 {
+		t.Errorf("back buffer should start with device contents, got 0x%X", pixel)
+	}
+
+	dev.ClearDirtyRegion()
+	fb.FillRegion(10, 10, 5, 5, 0x0F)
+
+	if pixel, _ := dev.GetPixel(10, 10); pixel != 0 {
+		t.Error("device should not change before Flush")
+	}
+	if pixel, _ := fb.GetPixel(10, 10); pixel != 0x0F {
+		t.Error("framebuffer should read back its own drawing")
+	}
+
+	if err := fb.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if pixel, _ := dev.GetPixel(14, 14); pixel != 0x0F {
+		t.Error("Flush should commit the back buffer to the device")
+	}
+	x0, y0, x1, y1 := dev.GetDirtyRegion()
+	if x0 != 10 || y0 != 10 || x1 != 14 || y1 != 14 {
+		t.Errorf("expected dirty region (10,10)-(14,14), got (%d,%d)-(%d,%d)", x0, y0, x1, y1)
+	}
+
+	// Disabling commits pending changes and returns to immediate mode
+	fb.SetPixel(20, 20, 0x0A)
+	fb.SetDoubleBuffered(false)
+	if pixel, _ := dev.GetPixel(20, 20); pixel != 0x0A {
+		t.Error("disabling double buffering should flush pending changes")
+	}
+	fb.SetPixel(21, 20, 0x0A)
+	if pixel, _ := dev.GetPixel(21, 20); pixel != 0x0A {
+		t.Error("immediate mode should write straight to the device")
+	}
+}
+
+func TestFrameBufferFlushDirty(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	fb := NewFrameBuffer(dev)
+
+	fb.DrawLine(10, 5, 20, 8, 0x0F)
+	fb.SetPixel(3, 30, 0x0F)
+
+	x0, y0, x1, y1, err := fb.FlushDirty()
+	if err != nil {
+		t.Fatalf("FlushDirty failed: %v", err)
+	}
+	if x0 != 3 || y0 != 5 || x1 != 20 || y1 != 30 {
+		t.Errorf("expected region (3,5)-(20,30), got (%d,%d)-(%d,%d)", x0, y0, x1, y1)
+	}
+	if fb.IsDirty() {
+		t.Error("framebuffer should be clean after FlushDirty")
+	}
+
+	// Nothing changed since the last flush
+	if x0, _, _, _, _ := fb.FlushDirty(); x0 != -1 {
+		t.Errorf("expected empty region, got x0=%d", x0)
+	}
+
+	// The device region is left for the renderer to consume
+	if dx0, _, _, _ := dev.GetDirtyRegion(); dx0 == -1 {
+		t.Error("device dirty region should remain until rendered")
+	}
+}
+
+func TestFrameBufferClipStack(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	if x, y, w, h := fb.ClipRect(); x != 0 || y != 0 || w != 256 || h != 64 {
+		t.Errorf("expected full-screen clip by default, got (%d, %d) %dx%d", x, y, w, h)
+	}
+
+	fb.PushClip(10, 10, 40, 20)
+	fb.PushClip(30, 0, 100, 100)
+	if x, y, w, h := fb.ClipRect(); x != 30 || y != 10 || w != 20 || h != 20 {
+		t.Errorf("expected nested clips to intersect, got (%d, %d) %dx%d", x, y, w, h)
+	}
+
+	fb.DrawRect(0, 0, 256, 64, 0x0F, true)
+	fb.DrawLine(0, 15, 255, 15, 0x0F)
+	fb.DrawCircle(40, 20, 30, 0x0F, false)
+	for y := 0; y < fb.Height(); y++ {
+		for x := 0; x < fb.Width(); x++ {
+			pixel, _ := fb.GetPixel(x, y)
+			inside := x >= 30 && x < 50 && y >= 10 && y < 30
+			if inside != (pixel != 0) {
+				t.Fatalf("pixel (%d, %d): inside clip %v, value 0x%X", x, y, inside, pixel)
+			}
+		}
+	}
+
+	fb.PopClip()
+	fb.PopClip()
+	if err := fb.PopClip(); err == nil {
+		t.Error("expected error popping an empty clip stack")
+	}
+
+	fb.SetPixel(200, 50, 0x0F)
+	if pixel, _ := fb.GetPixel(200, 50); pixel != 0x0F {
+		t.Error("expected drawing outside the old clip after popping")
+	}
+}
+
+func TestFrameBufferFillGradient(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	fb.FillGradient(0, 0, 31, 2, 0x00, 0x0F, true)
+	for x := 0; x < 31; x++ {
+		expected := byte(math.Round(float64(x) * 15 / 30))
+		if pixel, _ := fb.GetPixel(x, 1); pixel != expected {
+			t.Errorf("column %d: expected 0x%X, got 0x%X", x, expected, pixel)
+		}
+	}
+
+	// Reverse vertical gradient
+	fb.FillGradient(100, 10, 4, 6, 0x0C, 0x02, false)
+	if pixel, _ := fb.GetPixel(103, 10); pixel != 0x0C {
+		t.Errorf("expected first row 0xC, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(100, 15); pixel != 0x02 {
+		t.Errorf("expected last row 0x2, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(100, 12); pixel != 0x08 {
+		t.Errorf("expected middle row 0x8, got 0x%X", pixel)
+	}
+}
+
+func TestDrawImageOpaqueBlackCoversContent(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fb.Clear(0x0F)
+
+	black := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(black, black.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	black.Set(3, 3, color.Transparent)
+
+	if err := DrawImage(fb, 10, 10, black); err != nil {
+		t.Fatalf("DrawImage failed: %v", err)
+	}
+	if pixel, _ := fb.GetPixel(10, 10); pixel != 0 {
+		t.Errorf("expected opaque black pixel to clear content, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(13, 13); pixel != 0x0F {
+		t.Errorf("expected transparent pixel to keep content, got 0x%X", pixel)
+	}
+
+	DrawImageScaled(fb, 20, 10, 8, 8, black)
+	if pixel, _ := fb.GetPixel(20, 10); pixel != 0 {
+		t.Errorf("expected scaled opaque black pixel to clear content, got 0x%X", pixel)
+	}
+}
+
+func TestDrawImageScaledBilinear(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 2, 1))
+	src.SetGray(0, 0, color.Gray{Y: 0})
+	src.SetGray(1, 0, color.Gray{Y: 255})
+
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	DrawImageScaledMode(fb, 0, 0, 4, 1, src, ScaleNearest)
+	DrawImageScaledMode(fb, 0, 1, 4, 1, src, ScaleBilinear)
+
+	for x := 0; x < 4; x++ {
+		if pixel, _ := fb.GetPixel(x, 0); pixel != 0 && pixel != 0x0F {
+			t.Errorf("nearest pixel %d should be 0 or 0xF, got 0x%X", x, pixel)
+		}
+	}
+
+	expected := []byte{0x00, 0x04, 0x0B, 0x0F}
+	for x, want := range expected {
+		if pixel, _ := fb.GetPixel(x, 1); pixel != want {
+			t.Errorf("bilinear pixel %d: expected 0x%X, got 0x%X", x, want, pixel)
+		}
+	}
+}
+
+func TestDrawImageRotated(t *testing.T) {
+	// A 6x2 horizontal bar becomes a 2x6 vertical bar after a quarter turn
+	bar := image.NewGray(image.Rect(0, 0, 6, 2))
+	draw.Draw(bar, bar.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	if err := DrawImageRotated(fb, 20, 20, bar, 0); err != nil {
+		t.Fatalf("DrawImageRotated failed: %v", err)
+	}
+	if pixel, _ := fb.GetPixel(17, 19); pixel != 0x0F || countSetPixels(fb) != 12 {
+		t.Errorf("unrotated image should cover (17, 19)-(22, 20), got %d pixels", countSetPixels(fb))
+	}
+
+	fb.Clear(0)
+	DrawImageRotated(fb, 20, 20, bar, 90)
+	for y := 0; y < fb.Height(); y++ {
+		for x := 0; x < fb.Width(); x++ {
+			pixel, _ := fb.GetPixel(x, y)
+			inside := x >= 19 && x < 21 && y >= 17 && y < 23
+			if inside != (pixel != 0) {
+				t.Fatalf("pixel (%d, %d): expected inside=%v after 90 degree rotation", x, y, inside)
+			}
+		}
+	}
+
+	// Transparent pixels stay transparent
+	fb.Clear(0x05)
+	clear := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	DrawImageRotated(fb, 20, 20, clear, 45)
+	if pixel, _ := fb.GetPixel(20, 20); pixel != 0x05 {
+		t.Errorf("transparent source should not change the destination, got 0x%X", pixel)
+	}
+}
+
+func TestLineChartAppendScrolls(t *testing.T) {
+	chart := NewLineChart(0, 0, 4, 10)
+	for i := 1; i <= 6; i++ {
+		chart.Append(float64(i))
+	}
+
+	values := chart.Values()
+	if len(values) != 4 || values[0] != 3 || values[3] != 6 {
+		t.Errorf("expected the newest 4 samples, got %v", values)
+	}
+
+	if err := chart.SetCapacity(2); err != nil {
+		t.Fatalf("set capacity failed: %v", err)
+	}
+	if values := chart.Values(); len(values) != 2 || values[0] != 5 {
+		t.Errorf("expected samples trimmed to capacity, got %v", values)
+	}
+
+	if err := chart.SetCapacity(0); err == nil {
+		t.Error("expected error for zero capacity")
+	}
+	if err := chart.SetRange(1, 1); err == nil {
+		t.Error("expected error for empty range")
+	}
+}
+
+func TestLineChartDraw(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fb.FillRegion(0, 0, 256, 64, 0x05)
+
+	chart := NewLineChart(10, 10, 5, 11)
+	chart.SetValues([]float64{0, 10, 0, 10, 5})
+	if err := chart.Draw(fb); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+
+	// Auto range maps 0 to the bottom row and 10 to the top row
+	expected := map[[2]int]bool{{10, 20}: true, {11, 10}: true, {12, 20}: true, {13, 10}: true, {14, 15}: true}
+	for p := range expected {
+		if pixel, _ := fb.GetPixel(p[0], p[1]); pixel != 0x0F {
+			t.Errorf("expected sample at (%d, %d)", p[0], p[1])
+		}
+	}
+
+	// The box is cleared, outside it is untouched
+	if pixel, _ := fb.GetPixel(14, 10); pixel != 0 {
+		t.Errorf("expected cleared box, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(9, 10); pixel != 0x05 {
+		t.Errorf("expected pixel outside the box untouched, got 0x%X", pixel)
+	}
+
+	// A fixed range clamps out-of-range samples to the edges
+	chart.SetRange(0, 100)
+	chart.SetValues([]float64{-50, 500})
+	chart.Draw(fb)
+	if pixel, _ := fb.GetPixel(10, 20); pixel != 0x0F {
+		t.Error("expected low sample clamped to the bottom row")
+	}
+}
+
+func TestBarChartDraw(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	chart := NewBarChart(0, 0, 8, 10)
+	chart.SetValues([]float64{10, 5})
+	if err := chart.Draw(fb); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+
+	// Two 3-pixel bars: full height, then half height from the bottom
+	if count := countSetPixels(fb); count != 3*10+3*5 {
+		t.Errorf("expected 45 set pixels, got %d", count)
+	}
+	if pixel, _ := fb.GetPixel(4, 5); pixel != 0x0F {
+		t.Error("expected second bar top at y=5")
+	}
+	if pixel, _ := fb.GetPixel(3, 9); pixel != 0 {
+		t.Error("expected gap between bars")
+	}
+
+	if err := chart.SetGap(-1); err == nil {
+		t.Error("expected error for negative gap")
+	}
+}
+
+func TestChartsRespectSafeArea(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fb.SetSafeArea(Insets{Left: 10, Top: 4, Right: 0, Bottom: 50})
+
+	// A full-panel bar chart shrinks to the 246x10 safe area
+	bars := NewBarChart(0, 0, 256, 64)
+	bars.SetValues([]float64{1})
+	if err := bars.Draw(fb); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	if x := leftmostPixel(fb, 0, 63); x != 10 {
+		t.Errorf("expected bars to start at the left inset, got x=%d", x)
+	}
+	if top, bottom := rowExtent(fb); top != 4 || bottom != 13 {
+		t.Errorf("expected bars within rows 4..13, got %d..%d", top, bottom)
+	}
+
+	fb.Clear(0)
+	line := NewLineChart(0, 0, 256, 64)
+	line.SetValues([]float64{0, 1})
+	line.Draw(fb)
+	if top, bottom := rowExtent(fb); top != 4 || bottom != 13 {
+		t.Errorf("expected line within rows 4..13, got %d..%d", top, bottom)
+	}
+	if x := leftmostPixel(fb, 0, 63); x != 10 {
+		t.Errorf("expected line to start at the left inset, got x=%d", x)
+	}
+}
+
+func TestDrawSevenSegment(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	// 10x20 box with 2-pixel segments: '8' lights every segment
+	if err := DrawSevenSegment(fb, 0, 0, 10, 20, 8, 0x0F); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	for _, p := range [][2]int{{5, 0}, {9, 5}, {9, 15}, {5, 19}, {0, 15}, {0, 5}, {5, 9}} {
+		if pixel, _ := fb.GetPixel(p[0], p[1]); pixel != 0x0F {
+			t.Errorf("expected lit segment at (%d, %d)", p[0], p[1])
+		}
+	}
+	if pixel, _ := fb.GetPixel(5, 5); pixel != 0 {
+		t.Error("expected unlit interior")
+	}
+
+	fb.Clear(0)
+	DrawSevenSegment(fb, 0, 0, 10, 20, SegmentMinus, 0x0F)
+	if count := countSetPixels(fb); count != 10*2 {
+		t.Errorf("expected only the middle segment, got %d pixels", count)
+	}
+
+	fb.Clear(0)
+	DrawSevenSegment(fb, 0, 0, 10, 20, 1, 0x0F)
+	if left := leftmostPixel(fb, 0, 20); left != 8 {
+		t.Errorf("expected '1' on the right side, got leftmost x=%d", left)
+	}
+
+	if err := DrawSevenSegment(fb, 0, 0, 10, 20, 10, 0x0F); err == nil {
+		t.Error("expected error for invalid digit")
+	}
+	if err := DrawSevenSegmentThickness(fb, 0, 0, 10, 20, 8, 6, 0x0F); err == nil {
+		t.Error("expected error for oversized thickness")
+	}
+}
+
+func TestSevenSegmentDisplayDrawString(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	display := NewSevenSegmentDisplay(10, 20)
+
+	// Four 10-pixel digits, a 2-pixel colon and four 2-pixel gaps
+	width, err := display.DrawString(fb, 0, 0, "12:34", 0x0F)
+	if err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	if width != 4*10+2+4*2 {
+		t.Errorf("expected width 50, got %d", width)
+	}
+
+	// Colon dots sit between the second and third digit
+	for _, y := range []int{4, 14} {
+		if pixel, _ := fb.GetPixel(24, y); pixel != 0x0F {
+			t.Errorf("expected colon dot at (24, %d)", y)
+		}
+	}
+
+	width, err = display.DrawNumber(fb, 0, 30, -7, 0x0F)
+	if err != nil || width != 22 {
+		t.Errorf("expected width 22 for -7, got %d (%v)", width, err)
+	}
+
+	if _, err := display.DrawString(fb, 0, 0, "1a", 0x0F); err == nil {
+		t.Error("expected error for unsupported character")
+	}
+	if err := display.SetThickness(0); err == nil {
+		t.Error("expected error for zero thickness")
+	}
+}
+
+func TestDrawQRCode(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	// A short string fits a version 1 code: 21 modules plus a 4-module quiet zone on each side
+	size, err := QRCodeSize("WIFI:S:oled;;", QRLow)
+	if err != nil || size != 29 {
+		t.Fatalf("expected 29 modules, got %d (%v)", size, err)
+	}
+
+	if err := DrawQRCodeLevel(fb, 10, 2, "WIFI:S:oled;;", 2, QRLow, 0x0F); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+
+	// Quiet zone is lit, the finder pattern corner is dark
+	if pixel, _ := fb.GetPixel(10, 2); pixel != 0x0F {
+		t.Error("expected lit quiet zone")
+	}
+	if pixel, _ := fb.GetPixel(10+4*2, 2+4*2); pixel != 0 {
+		t.Error("expected dark finder pattern corner")
+	}
+	if pixel, _ := fb.GetPixel(10+29*2, 2); pixel != 0 {
+		t.Error("expected nothing drawn past the code")
+	}
+
+	if err := DrawQRCode(fb, 0, 0, "WIFI:S:oled;;", 3, 0x0F); err == nil {
+		t.Error("expected error for code taller than the display")
+	}
+	if err := DrawQRCode(fb, 0, 0, "x", 0, 0x0F); err == nil {
+		t.Error("expected error for zero scale")
+	}
+	if err := DrawQRCodeLevel(fb, 0, 0, "x", 1, QRLevel(9), 0x0F); err == nil {
+		t.Error("expected error for invalid level")
+	}
+}
+
+func TestLoadXBM(t *testing.T) {
+	src := `#define arrow_width 10
+#define arrow_height 2
+static unsigned char arrow_bits[] = {
+   0x01, 0x02, 0x80, 0x00 };
+`
+	img, err := LoadXBM(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if img.Bounds().Dx() != 10 || img.Bounds().Dy() != 2 {
+		t.Fatalf("expected 10x2 image, got %v", img.Bounds())
+	}
+
+	// Bits are least significant first: 0x01 is x=0, 0x02 in the second byte is x=9, 0x80 on row 1 is x=7
+	lit := map[[2]int]bool{{0, 0}: true, {9, 0}: true, {7, 1}: true}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 10; x++ {
+			gray := img.(*image.Gray).GrayAt(x, y).Y
+			if lit[[2]int{x, y}] != (gray == 0xFF) {
+				t.Errorf("unexpected pixel at (%d, %d): 0x%02X", x, y, gray)
+			}
+		}
+	}
+
+	if _, err := LoadXBM(strings.NewReader("#define a_width 8\nstatic char a_bits[] = { 0x01 };")); err == nil {
+		t.Error("expected error for missing height")
+	}
+	if _, err := LoadXBM(strings.NewReader("#define a_width 16\n#define a_height 1\nstatic char a_bits[] = { 0x01 };")); err == nil {
+		t.Error("expected error for short data")
+	}
+}
+
+func TestLoadPBM(t *testing.T) {
+	plain := "P1\n# comment\n3 2\n1 0 0\n001\n"
+	raw := "P4\n3 2\n\x80\x20"
+
+	for name, src := range map[string]string{"plain": plain, "raw": raw} {
+		img, err := LoadPBM(strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("%s: load failed: %v", name, err)
+		}
+		if img.Bounds().Dx() != 3 || img.Bounds().Dy() != 2 {
+			t.Fatalf("%s: expected 3x2 image, got %v", name, img.Bounds())
+		}
+
+		gray := img.(*image.Gray)
+		if gray.GrayAt(0, 0).Y != 0xFF || gray.GrayAt(2, 1).Y != 0xFF || gray.GrayAt(1, 0).Y != 0 {
+			t.Errorf("%s: unexpected pixels %v", name, gray.Pix)
+		}
+	}
+
+	if _, err := LoadPBM(strings.NewReader("P2\n1 1\n0")); err == nil {
+		t.Error("expected error for graymap")
+	}
+	if _, err := LoadPBM(strings.NewReader("P4\n8 2\n\xff")); err == nil {
+		t.Error("expected error for short raw data")
+	}
+}
+
+func TestExportXBMRoundTrip(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fb.SetPixel(0, 0, 0x0F)
+	fb.SetPixel(9, 3, 0x08)
+	fb.SetPixel(255, 63, 0x07)
+
+	var sb strings.Builder
+	if err := ExportXBM(fb, &sb, "screen"); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if !strings.HasPrefix(sb.String(), "#define screen_width 256\n#define screen_height 64\nstatic unsigned char screen_bits[] = {") {
+		t.Errorf("unexpected header: %q", sb.String()[:80])
+	}
+
+	img, err := LoadXBM(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+
+	// Level 7 is below the default threshold
+	gray := img.(*image.Gray)
+	if gray.GrayAt(0, 0).Y != 0xFF || gray.GrayAt(9, 3).Y != 0xFF || gray.GrayAt(255, 63).Y != 0 {
+		t.Error("unexpected pixels after round trip")
+	}
+
+	sb.Reset()
+	ExportXBMThreshold(fb, &sb, "screen", 1)
+	img, _ = LoadXBM(strings.NewReader(sb.String()))
+	if img.(*image.Gray).GrayAt(255, 63).Y != 0xFF {
+		t.Error("expected level 7 set with threshold 1")
+	}
+
+	if err := ExportXBM(fb, &sb, "9screen"); err == nil {
+		t.Error("expected error for invalid identifier")
+	}
+}
+
+func TestExportGrayArray(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fb.SetPixel(0, 0, 0x0A)
+	fb.SetPixel(1, 0, 0x03)
+
+	var sb strings.Builder
+	if err := ExportGrayArray(fb, &sb, "frame"); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	out := sb.String()
+	if !strings.Contains(out, "static const unsigned char frame_data[] = {\n   0x3a, 0x00,") {
+		t.Errorf("expected first byte 0x3a, got %q", out[:120])
+	}
+	if count := strings.Count(out, "0x"); count != 128*64 {
+		t.Errorf("expected %d bytes, got %d", 128*64, count)
+	}
+}
+
+func TestExportGrayArrayReplaysOnSSD1322(t *testing.T) {
+	fb := NewFrameBuffer(device.NewNullDevice(256, 64))
+	fb.DrawLine(0, 0, 255, 63, 0x0F)
+	fb.DrawRect(10, 5, 31, 20, 0x07, true)
+	fb.SetPixel(255, 0, 0x03)
+
+	var sb strings.Builder
+	if err := ExportGrayArray(fb, &sb, "frame"); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	// Parse the array back into bytes and stream them as display RAM
+	out := sb.String()
+	body := out[strings.Index(out, "{")+1 : strings.Index(out, "}")]
+	var data []byte
+	for _, field := range strings.Split(body, ",") {
+		value, err := strconv.ParseUint(strings.TrimSpace(field), 0, 8)
+		if err != nil {
+			t.Fatalf("invalid byte %q: %v", field, err)
+		}
+		data = append(data, byte(value))
+	}
+
+	ssd := device.NewSSD1322(256, 64)
+	ssd.ProcessCommand(device.CmdWriteRAM, nil)
+	if err := ssd.WriteData(data); err != nil {
+		t.Fatalf("WriteData failed: %v", err)
+	}
+
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 256; x++ {
+			want, _ := fb.GetPixel(x, y)
+			if got, _ := ssd.GetPixel(x, y); got != want {
+				t.Fatalf("pixel (%d, %d): expected 0x%X, got 0x%X", x, y, want, got)
+			}
+		}
+	}
+}
+
+func TestFrameBufferThreadSafe(t *testing.T) {
+	dev := device.NewSSD1322(64, 16)
+	fb := NewFrameBuffer(dev)
+	fb.SetThreadSafe(true)
+	if !fb.IsThreadSafe() {
+		t.Fatal("expected thread safe framebuffer")
+	}
+
+	// One goroutine draws and flushes while this one reads, like an animator next to the renderer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			fb.DrawRect(0, 0, 64, 16, byte(i)&0x0F, true)
+			fb.Flush()
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		fb.GetPixel(i%64, i%16)
+		fb.IsDirty()
+
+		fb.Lock()
+		vram := append([]byte(nil), dev.GetFrameBuffer()...)
+		fb.Unlock()
+		if len(vram) == 0 {
+			t.Fatal("expected VRAM contents")
+		}
+	}
+	<-done
+
+	if pixel, _ := fb.GetPixel(10, 10); pixel != byte(199)&0x0F {
+		t.Errorf("expected final fill level, got 0x%X", pixel)
+	}
+}
+
+func TestFrameBufferThreadSafeDoubleBufferToggle(t *testing.T) {
+	dev := device.NewSSD1322(64, 16)
+	fb := NewFrameBuffer(dev)
+	fb.SetThreadSafe(true)
+
+	// Toggling double buffering while another goroutine draws must not race on the back buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			fb.DrawRect(0, 0, 64, 16, byte(i)&0x0F, true)
+			fb.Flush()
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		if err := fb.SetDoubleBuffered(i%2 == 0); err != nil {
+			t.Fatalf("toggle %d failed: %v", i, err)
+		}
+		fb.IsDoubleBuffered()
+	}
+	<-done
+
+	if err := fb.SetDoubleBuffered(false); err != nil {
+		t.Fatalf("disable double buffering failed: %v", err)
+	}
+	if pixel, _ := dev.GetPixel(10, 10); pixel != byte(199)&0x0F {
+		t.Errorf("expected final fill level on the device, got 0x%X", pixel)
+	}
+}
+
+func TestFrameBufferSnapshotRestore(t *testing.T) {
+	for _, double := range []bool{false, true} {
+		dev := device.NewSSD1322(256, 64)
+		fb := NewFrameBuffer(dev)
+		fb.SetDoubleBuffered(double)
+
+		fb.DrawRect(10, 10, 20, 20, 0x08, true)
+		snap := fb.Snapshot()
+
+		// A tooltip drawn over the rectangle and empty space
+		fb.DrawRect(20, 20, 40, 10, 0x0F, true)
+		fb.Flush()
+		dev.ClearDirtyRegion()
+
+		if err := fb.Restore(snap); err != nil {
+			t.Fatalf("double=%v: restore failed: %v", double, err)
+		}
+		fb.Flush()
+
+		if pixel, _ := dev.GetPixel(25, 25); pixel != 0x08 {
+			t.Errorf("double=%v: expected rectangle restored, got 0x%X", double, pixel)
+		}
+		if pixel, _ := dev.GetPixel(50, 25); pixel != 0 {
+			t.Errorf("double=%v: expected tooltip gone, got 0x%X", double, pixel)
+		}
+		if x0, y0, x1, y1 := dev.GetDirtyRegion(); x0 != 0 || y0 != 0 || x1 != 255 || y1 != 63 {
+			t.Errorf("double=%v: expected full dirty region, got (%d, %d)-(%d, %d)", double, x0, y0, x1, y1)
+		}
+	}
+
+	// Snapshots only restore into a framebuffer of the same size and mode
+	small := NewFrameBuffer(device.NewSSD1322(128, 64))
+	if err := small.Restore(NewFrameBuffer(device.NewSSD1322(256, 64)).Snapshot()); err == nil {
+		t.Error("expected error for mismatched size")
+	}
+
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	snap := fb.Snapshot()
+	fb.SetDoubleBuffered(true)
+	if err := fb.Restore(snap); err == nil {
+		t.Error("expected error restoring an immediate-mode snapshot into a back buffer")
+	}
+}
+
+func TestFrameBufferDrawModeXorRoundTrip(t *testing.T) {
+	for _, double := range []bool{false, true} {
+		dev := device.NewSSD1322(256, 64)
+		fb := NewFrameBuffer(dev)
+		fb.SetDoubleBuffered(double)
+
+		// A background with varied levels under the selection
+		for x := 0; x < 64; x++ {
+			fb.DrawLine(x, 0, x, 40, byte(x%16))
+		}
+		fb.Flush()
+		before := append([]byte(nil), dev.DumpVRAM()...)
+
+		if err := fb.SetDrawMode(DrawModeXor); err != nil {
+			t.Fatalf("failed to set XOR mode: %v", err)
+		}
+
+		fb.DrawRect(5, 5, 30, 20, 0x0F, false)
+		fb.Flush()
+		if pixel, _ := dev.GetPixel(5, 10); pixel != 0x0A {
+			t.Errorf("double=%v: expected 0x5 XOR 0xF = 0xA, got 0x%X", double, pixel)
+		}
+
+		fb.DrawRect(5, 5, 30, 20, 0x0F, false)
+		fb.Flush()
+		if !bytes.Equal(dev.DumpVRAM(), before) {
+			t.Errorf("double=%v: expected drawing the XOR rectangle twice to restore the original content", double)
+		}
+	}
+
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fb.SetPixel(0, 0, 0x05)
+	fb.SetDrawMode(DrawModeOr)
+	fb.SetPixel(0, 0, 0x0A)
+	if pixel, _ := fb.GetPixel(0, 0); pixel != 0x0F {
+		t.Errorf("expected 0x5 OR 0xA = 0xF, got 0x%X", pixel)
+	}
+
+	fb.SetDrawMode(DrawModeAnd)
+	fb.SetPixel(0, 0, 0x06)
+	if pixel, _ := fb.GetPixel(0, 0); pixel != 0x06 {
+		t.Errorf("expected 0xF AND 0x6 = 0x6, got 0x%X", pixel)
+	}
+
+	fb.SetDrawMode(DrawModeReplace)
+	fb.DrawRect(0, 0, 4, 4, 0x08, true)
+	fb.SetDrawMode(DrawModeMax)
+	fb.DrawRect(2, 2, 4, 4, 0x04, true)
+	if pixel, _ := fb.GetPixel(3, 3); pixel != 0x08 {
+		t.Errorf("expected Max of 0x4 over 0x8 = 0x8, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(5, 5); pixel != 0x04 {
+		t.Errorf("expected Max of 0x4 over black = 0x4, got 0x%X", pixel)
+	}
+
+	fb.SetDrawMode(DrawModeMin)
+	fb.SetPixel(3, 3, 0x02)
+	if pixel, _ := fb.GetPixel(3, 3); pixel != 0x02 {
+		t.Errorf("expected Min of 0x2 over 0x8 = 0x2, got 0x%X", pixel)
+	}
+
+	fb.SetDrawMode(DrawModeAverage)
+	fb.SetPixel(0, 0, 0x0F)
+	if pixel, _ := fb.GetPixel(0, 0); pixel != 0x0B {
+		t.Errorf("expected average of 0x8 and 0xF = 0xB, got 0x%X", pixel)
+	}
+
+	if err := fb.SetDrawMode(DrawMode(99)); err == nil {
+		t.Error("expected error for invalid draw mode")
+	}
+}
+
+func TestFrameBufferXorDrawsEachPixelOnce(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fb.SetDrawMode(DrawModeXor)
+
+	// Rectangle corners are shared by two sides
+	fb.DrawRect(5, 5, 30, 20, 0x0F, false)
+	for _, corner := range [][2]int{{5, 5}, {34, 5}, {5, 24}, {34, 24}} {
+		if pixel, _ := fb.GetPixel(corner[0], corner[1]); pixel != 0x0F {
+			t.Errorf("expected XOR corner %v to be 0xF, got 0x%X", corner, pixel)
+		}
+	}
+	if count := countSetPixels(fb); count != 2*30+2*18 {
+		t.Errorf("expected %d outline pixels, got %d", 2*30+2*18, count)
+	}
+	fb.DrawRect(5, 5, 30, 20, 0x0F, false)
+	if count := countSetPixels(fb); count != 0 {
+		t.Errorf("expected a second XOR rectangle to clear the outline, %d pixels left", count)
+	}
+
+	// Filled circle spans overlap on the rows the midpoint algorithm visits twice
+	fb.DrawCircle(128, 32, 10, 0x0F, true)
+	reference := NewFrameBuffer(device.NewSSD1322(256, 64))
+	reference.DrawCircle(128, 32, 10, 0x0F, true)
+	for y := 20; y <= 44; y++ {
+		for x := 116; x <= 140; x++ {
+			want, _ := reference.GetPixel(x, y)
+			if got, _ := fb.GetPixel(x, y); got != want {
+				t.Fatalf("pixel (%d, %d): expected 0x%X as drawn in replace mode, got 0x%X", x, y, want, got)
+			}
+		}
+	}
+}
+
+func TestFrameBufferDrawModeSkipsDataMoves(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fb.Clear(0x0F)
+	fb.SetDrawMode(DrawModeXor)
+
+	// Clearing replaces every pixel instead of XORing into it
+	fb.Clear(0x05)
+	if pixel, _ := fb.GetPixel(100, 30); pixel != 0x05 {
+		t.Errorf("expected Clear to set 0x5 under XOR, got 0x%X", pixel)
+	}
+
+	fb.SetDrawMode(DrawModeReplace)
+	fb.SetPixel(0, 0, 0x0A)
+	fb.SetPixel(1, 0, 0x03)
+	fb.SetDrawMode(DrawModeXor)
+
+	fb.CopyRegion(0, 0, 2, 1, 10, 0)
+	for x, want := range map[int]byte{10: 0x0A, 11: 0x03, 12: 0x05} {
+		if pixel, _ := fb.GetPixel(x, 0); pixel != want {
+			t.Errorf("copy: expected 0x%X at (%d, 0), got 0x%X", want, x, pixel)
+		}
+	}
+
+	fb.ScrollVertical(1, 0x00)
+	for x, want := range map[int]byte{0: 0x0A, 1: 0x03, 10: 0x0A, 11: 0x03, 12: 0x05} {
+		if pixel, _ := fb.GetPixel(x, 1); pixel != want {
+			t.Errorf("scroll: expected 0x%X at (%d, 1), got 0x%X", want, x, pixel)
+		}
+	}
+	for x := 0; x < 256; x++ {
+		if pixel, _ := fb.GetPixel(x, 0); pixel != 0 {
+			t.Fatalf("scroll: expected the vacated row to be cleared, got 0x%X at (%d, 0)", pixel, x)
+		}
+	}
+
+	fb.MoveRegion(10, 1, 2, 1, 20, 1)
+	if pixel, _ := fb.GetPixel(10, 1); pixel != 0 {
+		t.Errorf("move: expected the vacated pixel to be cleared, got 0x%X", pixel)
+	}
+	if pixel, _ := fb.GetPixel(20, 1); pixel != 0x0A {
+		t.Errorf("move: expected 0xA at the destination, got 0x%X", pixel)
+	}
+}
+
+func TestFrameBufferAverageDrawsEachPixelOnce(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	fb.Clear(0x08)
+	fb.SetDrawMode(DrawModeAverage)
+
+	// Corners average once like the rest of the outline, not twice
+	fb.DrawRect(5, 5, 30, 20, 0x0F, false)
+	for _, p := range [][2]int{{5, 5}, {34, 24}, {20, 5}, {5, 15}} {
+		if pixel, _ := fb.GetPixel(p[0], p[1]); pixel != 0x0B {
+			t.Errorf("expected average of 0x8 and 0xF = 0xB at %v, got 0x%X", p, pixel)
+		}
+	}
+}
+
+func TestCompositorFlatten(t *testing.T) {
+	background := NewLayer(256, 64)
+	background.Clear(0x05)
+
+	foreground := NewLayer(256, 64)
+	foreground.SetTransparent(0)
+	foreground.DrawRect(10, 10, 20, 20, 0x0F, true)
+
+	dev := device.NewSSD1322(256, 64)
+	fb := NewFrameBuffer(dev)
+	compositor := NewCompositor(background, foreground)
+
+	if err := compositor.Flatten(fb, DrawModeReplace); err != nil {
+		t.Fatalf("flatten failed: %v", err)
+	}
+
+	if pixel, _ := dev.GetPixel(15, 15); pixel != 0x0F {
+		t.Errorf("expected foreground pixel 0xF, got 0x%X", pixel)
+	}
+	if pixel, _ := dev.GetPixel(50, 40); pixel != 0x05 {
+		t.Errorf("expected background through transparent pixel 0x5, got 0x%X", pixel)
+	}
+
+	// Half opacity mixes the foreground with the background
+	foreground.SetOpacity(0.5)
+	fb.Clear(0)
+	compositor.Flatten(fb, DrawModeReplace)
+	if pixel, _ := dev.GetPixel(15, 15); pixel != 0x0A {
+		t.Errorf("expected half-opacity pixel 0xA, got 0x%X", pixel)
+	}
+
+	// Hidden layers are skipped
+	foreground.SetVisible(false)
+	fb.Clear(0)
+	compositor.Flatten(fb, DrawModeReplace)
+	if pixel, _ := dev.GetPixel(15, 15); pixel != 0x05 {
+		t.Errorf("expected hidden layer skipped, got 0x%X", pixel)
+	}
+
+	compositor.AddLayer(NewLayer(128, 64))
+	if err := compositor.Flatten(fb, DrawModeReplace); err == nil {
+		t.Error("expected error for mismatched layer size")
+	}
+}
+
+func TestLayerDrawOffsetted(t *testing.T) {
+	layer := NewLayer(256, 64)
+	layer.SetPixel(10, 10, 0x0C)
+
+	// Half a pixel right splits the brightness between two columns
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	if err := layer.DrawOffsetted(fb, 0.5, 0); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	for _, x := range []int{10, 11} {
+		if pixel, _ := fb.GetPixel(x, 10); pixel != 0x06 {
+			t.Errorf("expected 0x6 at (%d, 10), got 0x%X", x, pixel)
+		}
+	}
+
+	// Both axes: a quarter down and three quarters right
+	fb.Clear(0)
+	layer.DrawOffsetted(fb, 2.75, 0.25)
+	expected := map[[2]int]byte{{12, 10}: 0x02, {13, 10}: 0x07, {12, 11}: 0x01, {13, 11}: 0x02}
+	for pos, level := range expected {
+		if pixel, _ := fb.GetPixel(pos[0], pos[1]); pixel != level {
+			t.Errorf("expected 0x%X at %v, got 0x%X", level, pos, pixel)
+		}
+	}
+
+	// Whole offsets move pixels unchanged
+	fb.Clear(0)
+	layer.DrawOffsetted(fb, -3, 2)
+	if pixel, _ := fb.GetPixel(7, 12); pixel != 0x0C {
+		t.Errorf("expected 0xC at (7, 12), got 0x%X", pixel)
+	}
+
+	// Overlapping contributions are clamped
+	layer.Clear(0x0F)
+	fb.Clear(0)
+	layer.DrawOffsetted(fb, 0.5, 0.5)
+	if pixel, _ := fb.GetPixel(20, 20); pixel != 0x0F {
+		t.Errorf("expected clamped 0xF, got 0x%X", pixel)
+	}
+}
@@ -0,0 +1,177 @@
+package graphics
+
+import (
+	"fmt"
+)
+
+// Icons is a registry of common monochrome status icons
+// Every icon is available as an 8x8 bitmap under its base name and as a
+// 16x16 bitmap under the same name with a "_16" suffix (e.g. "wifi_3_16")
+var Icons = buildIcons()
+
+// DrawIcon draws a named icon at (x, y) using the given grayscale level
+func DrawIcon(fb *FrameBuffer, x, y int, name string, level byte) error {
+	icon, ok := Icons[name]
+	if !ok {
+		return fmt.Errorf("icon not found: %s", name)
+	}
+
+	return drawGlyphData(fb, x, y, icon, level&0x0F)
+}
+
+// buildIcons creates the 8x8 icon set and derives the 16x16 variants
+func buildIcons() map[string]GlyphData {
+	small := map[string][]byte{
+		"arrow_up": {
+			0b00011000,
+			0b00111100,
+			0b01111110,
+			0b11111111,
+			0b00011000,
+			0b00011000,
+			0b00011000,
+			0b00011000,
+		},
+		"arrow_down": {
+			0b00011000,
+			0b00011000,
+			0b00011000,
+			0b00011000,
+			0b11111111,
+			0b01111110,
+			0b00111100,
+			0b00011000,
+		},
+		"arrow_left": {
+			0b00010000,
+			0b00110000,
+			0b01110000,
+			0b11111111,
+			0b11111111,
+			0b01110000,
+			0b00110000,
+			0b00010000,
+		},
+		"arrow_right": {
+			0b00001000,
+			0b00001100,
+			0b00001110,
+			0b11111111,
+			0b11111111,
+			0b00001110,
+			0b00001100,
+			0b00001000,
+		},
+		"play": {
+			0b11000000,
+			0b11110000,
+			0b11111100,
+			0b11111111,
+			0b11111111,
+			0b11111100,
+			0b11110000,
+			0b11000000,
+		},
+		"pause": {
+			0b01100110,
+			0b01100110,
+			0b01100110,
+			0b01100110,
+			0b01100110,
+			0b01100110,
+			0b01100110,
+			0b01100110,
+		},
+	}
+
+	// Battery levels: 0, 25, 50, 75 and 100 percent
+	for bars := 0; bars <= 4; bars++ {
+		small[fmt.Sprintf("battery_%d", bars*25)] = batteryIcon(bars)
+	}
+
+	// Wifi signal strength: 0 to 4 bars
+	for bars := 0; bars <= 4; bars++ {
+		small[fmt.Sprintf("wifi_%d", bars)] = wifiIcon(bars)
+	}
+
+	icons := make(map[string]GlyphData, len(small)*2)
+	for name, data := range small {
+		icons[name] = GlyphData{
+			Width:    8,
+			Height:   8,
+			AdvanceX: 8,
+			Data:     data,
+		}
+		icons[name+"_16"] = GlyphData{
+			Width:    16,
+			Height:   16,
+			AdvanceX: 16,
+			Data:     scaleIcon2x(data),
+		}
+	}
+
+	return icons
+}
+
+// batteryIcon creates a battery outline with the given number of filled bars (0-4)
+func batteryIcon(bars int) []byte {
+	data := []byte{
+		0b00000000,
+		0b11111100,
+		0b10000100,
+		0b10000111,
+		0b10000111,
+		0b10000100,
+		0b11111100,
+		0b00000000,
+	}
+
+	// Interior columns 1-4, rows 2-5
+	for row := 2; row <= 5; row++ {
+		for col := 1; col <= bars; col++ {
+			data[row] |= 1 << (7 - col)
+		}
+	}
+
+	return data
+}
+
+// wifiIcon creates a signal strength icon with the given number of lit bars (0-4)
+// Unlit bars are drawn as a single baseline pixel so the icon keeps its shape
+func wifiIcon(bars int) []byte {
+	data := make([]byte, 8)
+
+	for bar := 0; bar < 4; bar++ {
+		col := bar * 2
+		height := 1
+		if bar < bars {
+			height = (bar + 1) * 2
+		}
+
+		for row := 8 - height; row < 8; row++ {
+			data[row] |= 1 << (7 - col)
+		}
+	}
+
+	return data
+}
+
+// scaleIcon2x doubles an 8x8 icon to 16x16 (2 bytes per row)
+func scaleIcon2x(data []byte) []byte {
+	scaled := make([]byte, 0, len(data)*4)
+
+	for _, row := range data {
+		var wide uint16
+		for bit := 0; bit < 8; bit++ {
+			if row&(1<<(7-bit)) != 0 {
+				wide |= 0b11 << (14 - bit*2)
+			}
+		}
+
+		hi := byte(wide >> 8)
+		lo := byte(wide)
+		scaled = append(scaled, hi, lo, hi, lo)
+	}
+
+	return scaled
+}
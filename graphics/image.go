@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"math"
 )
 
 // DrawImage draws an image to the framebuffer at the specified position
@@ -13,6 +14,7 @@ func DrawImage(fb *FrameBuffer, x, y int, img image.Image) error {
 	}
 
 	bounds := img.Bounds()
+	isRGB := fb.IsRGB()
 
 	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
 		for px := bounds.Min.X; px < bounds.Max.X; px++ {
@@ -23,25 +25,36 @@ func DrawImage(fb *FrameBuffer, x, y int, img image.Image) error {
 				continue
 			}
 
-			// Convert RGB to grayscale
-			gray := byte(((r>>8)*77 + (g>>8)*150 + (b>>8)*29) / 256)
-
-			// Convert to 4-bit grayscale
-			level := gray >> 4
-
-			if level > 0 {
-				screenX := x + px - bounds.Min.X
-				screenY := y + py - bounds.Min.Y
-				fb.SetPixel(screenX, screenY, level)
+			// Color devices get the true color
+			if isRGB {
+				fb.SetPixelRGB(x+px-bounds.Min.X, y+py-bounds.Min.Y, byte(r>>8), byte(g>>8), byte(b>>8))
+				continue
 			}
+
+			// Dark opaque pixels are written as level 0 so they cover existing content
+			level := rgbToLevel(byte(r>>8), byte(g>>8), byte(b>>8))
+			fb.SetPixel(x+px-bounds.Min.X, y+py-bounds.Min.Y, level)
 		}
 	}
 
 	return nil
 }
 
-// DrawImageScaled draws a scaled image to the framebuffer
+// ScaleMode selects how DrawImageScaledMode samples the source image
+type ScaleMode int
+
+const (
+	ScaleNearest  ScaleMode = iota // Nearest-neighbor sampling, fastest
+	ScaleBilinear                  // Average of the four nearest source pixels, smoother edges
+)
+
+// DrawImageScaled draws a scaled image to the framebuffer using nearest-neighbor sampling
 func DrawImageScaled(fb *FrameBuffer, x, y, w, h int, img image.Image) error {
+	return DrawImageScaledMode(fb, x, y, w, h, img, ScaleNearest)
+}
+
+// DrawImageScaledMode draws a scaled image to the framebuffer using the given sampling mode
+func DrawImageScaledMode(fb *FrameBuffer, x, y, w, h int, img image.Image, mode ScaleMode) error {
 	if img == nil {
 		return fmt.Errorf("image is nil")
 	}
@@ -58,38 +71,136 @@ func DrawImageScaled(fb *FrameBuffer, x, y, w, h int, img image.Image) error {
 		return fmt.Errorf("source image has invalid dimensions")
 	}
 
-	// Use nearest-neighbor scaling
+	isRGB := fb.IsRGB()
+
 	for py := 0; py < h; py++ {
 		for px := 0; px < w; px++ {
-			// Calculate source pixel coordinates
-			srcX := (px * srcWidth) / w
-			srcY := (py * srcHeight) / h
+			var r, g, b, a uint32
 
-			// Get pixel from source image
-			r, g, b, a := img.At(bounds.Min.X+srcX, bounds.Min.Y+srcY).RGBA()
+			if mode == ScaleBilinear {
+				// Sample at the destination pixel center mapped into the source
+				srcX := (float64(px)+0.5)*float64(srcWidth)/float64(w) - 0.5
+				srcY := (float64(py)+0.5)*float64(srcHeight)/float64(h) - 0.5
+				r, g, b, a = sampleBilinear(img, srcX, srcY)
+			} else {
+				srcX := (px * srcWidth) / w
+				srcY := (py * srcHeight) / h
+				r, g, b, a = img.At(bounds.Min.X+srcX, bounds.Min.Y+srcY).RGBA()
+			}
 
 			// Skip fully transparent pixels
 			if a == 0 {
 				continue
 			}
 
-			// Convert RGB to grayscale
-			gray := byte(((r>>8)*77 + (g>>8)*150 + (b>>8)*29) / 256)
+			// Color devices get the true color
+			if isRGB {
+				fb.SetPixelRGB(x+px, y+py, byte(r>>8), byte(g>>8), byte(b>>8))
+				continue
+			}
+
+			// Convert to a 4-bit grayscale level, writing level 0 as well
+			level := rgbToLevel(byte(r>>8), byte(g>>8), byte(b>>8))
+			fb.SetPixel(x+px, y+py, level)
+		}
+	}
+
+	return nil
+}
+
+// DrawImageRotated draws an image rotated by angleDeg degrees clockwise around its center,
+// placing the center at (cx, cy). Each destination pixel samples the source by inverse
+// mapping (nearest neighbor); pixels mapping outside the source or onto transparent
+// source pixels are left untouched
+func DrawImageRotated(fb *FrameBuffer, cx, cy int, img image.Image, angleDeg float64) error {
+	if img == nil {
+		return fmt.Errorf("image is nil")
+	}
+
+	bounds := img.Bounds()
+	halfW := float64(bounds.Dx()) / 2
+	halfH := float64(bounds.Dy()) / 2
+
+	sin, cos := math.Sincos(angleDeg * math.Pi / 180)
+
+	// Destination bounding box from the rotated corners
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range [][2]float64{{-halfW, -halfH}, {halfW, -halfH}, {-halfW, halfH}, {halfW, halfH}} {
+		rx := corner[0]*cos - corner[1]*sin
+		ry := corner[0]*sin + corner[1]*cos
+		minX, maxX = math.Min(minX, rx), math.Max(maxX, rx)
+		minY, maxY = math.Min(minY, ry), math.Max(maxY, ry)
+	}
+
+	isRGB := fb.IsRGB()
 
-			// Convert to 4-bit grayscale
-			level := gray >> 4
+	for py := cy + int(math.Floor(minY)); py < cy+int(math.Ceil(maxY)); py++ {
+		for px := cx + int(math.Floor(minX)); px < cx+int(math.Ceil(maxX)); px++ {
+			// Rotate the destination pixel center back into source space
+			dx := float64(px-cx) + 0.5
+			dy := float64(py-cy) + 0.5
+			srcX := int(math.Floor(dx*cos + dy*sin + halfW))
+			srcY := int(math.Floor(-dx*sin + dy*cos + halfH))
+
+			if srcX < 0 || srcX >= bounds.Dx() || srcY < 0 || srcY >= bounds.Dy() {
+				continue
+			}
+
+			r, g, b, a := img.At(bounds.Min.X+srcX, bounds.Min.Y+srcY).RGBA()
+			if a == 0 {
+				continue
+			}
 
-			if level > 0 {
-				screenX := x + px
-				screenY := y + py
-				fb.SetPixel(screenX, screenY, level)
+			if isRGB {
+				fb.SetPixelRGB(px, py, byte(r>>8), byte(g>>8), byte(b>>8))
+				continue
 			}
+
+			fb.SetPixel(px, py, rgbToLevel(byte(r>>8), byte(g>>8), byte(b>>8)))
 		}
 	}
 
 	return nil
 }
 
+// sampleBilinear interpolates the four source pixels around (sx, sy), relative to the image bounds
+// Coordinates are clamped to the image edges
+func sampleBilinear(img image.Image, sx, sy float64) (r, g, b, a uint32) {
+	bounds := img.Bounds()
+	maxX := float64(bounds.Dx() - 1)
+	maxY := float64(bounds.Dy() - 1)
+
+	sx = math.Max(0, math.Min(sx, maxX))
+	sy = math.Max(0, math.Min(sy, maxY))
+
+	x0 := int(sx)
+	y0 := int(sy)
+	x1 := min(x0+1, bounds.Dx()-1)
+	y1 := min(y0+1, bounds.Dy()-1)
+	fx := sx - float64(x0)
+	fy := sy - float64(y0)
+
+	var sum [4]float64
+	for _, s := range []struct {
+		x, y   int
+		weight float64
+	}{
+		{x0, y0, (1 - fx) * (1 - fy)},
+		{x1, y0, fx * (1 - fy)},
+		{x0, y1, (1 - fx) * fy},
+		{x1, y1, fx * fy},
+	} {
+		pr, pg, pb, pa := img.At(bounds.Min.X+s.x, bounds.Min.Y+s.y).RGBA()
+		sum[0] += float64(pr) * s.weight
+		sum[1] += float64(pg) * s.weight
+		sum[2] += float64(pb) * s.weight
+		sum[3] += float64(pa) * s.weight
+	}
+
+	return uint32(math.Round(sum[0])), uint32(math.Round(sum[1])), uint32(math.Round(sum[2])), uint32(math.Round(sum[3]))
+}
+
 // ImageTiler provides tiling/repeating functionality for images
 type ImageTiler struct {
 	img image.Image
@@ -129,17 +240,9 @@ func (it *ImageTiler) DrawTiled(fb *FrameBuffer, x, y, w, h int) error {
 				continue
 			}
 
-			// Convert RGB to grayscale
-			gray := byte(((r>>8)*77 + (g>>8)*150 + (b>>8)*29) / 256)
-
-			// Convert to 4-bit grayscale
-			level := gray >> 4
-
-			if level > 0 {
-				screenX := x + px
-				screenY := y + py
-				fb.SetPixel(screenX, screenY, level)
-			}
+			// Convert to a 4-bit grayscale level, writing level 0 as well
+			level := rgbToLevel(byte(r>>8), byte(g>>8), byte(b>>8))
+			fb.SetPixel(x+px, y+py, level)
 		}
 	}
 
@@ -0,0 +1,207 @@
+package graphics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/flavioheleno/oled-emulator/device"
+)
+
+// NoTransparency disables the transparent index of a layer
+const NoTransparency = -1
+
+// Layer is an off-screen, display-sized buffer composited by a Compositor
+// It embeds a FrameBuffer, so every drawing method works on a layer
+type Layer struct {
+	*FrameBuffer
+	visible     bool
+	opacity     float64
+	transparent int
+}
+
+// NewLayer creates a visible, opaque layer cleared to black with no transparent index
+func NewLayer(width, height int) *Layer {
+	return &Layer{
+		FrameBuffer: NewFrameBuffer(device.NewNullDevice(width, height)),
+		visible:     true,
+		opacity:     1,
+		transparent: NoTransparency,
+	}
+}
+
+// SetVisible shows or hides the layer; hidden layers are skipped by Flatten
+func (l *Layer) SetVisible(visible bool) {
+	l.visible = visible
+}
+
+// IsVisible returns whether the layer is visible
+func (l *Layer) IsVisible() bool {
+	return l.visible
+}
+
+// SetOpacity sets how strongly the layer covers the layers below, from 0 (invisible) to 1 (opaque)
+func (l *Layer) SetOpacity(opacity float64) error {
+	if opacity < 0 || opacity > 1 {
+		return fmt.Errorf("invalid layer opacity: %f", opacity)
+	}
+
+	l.opacity = opacity
+	return nil
+}
+
+// Opacity returns the layer opacity
+func (l *Layer) Opacity() float64 {
+	return l.opacity
+}
+
+// SetTransparent sets the level (0-15) that lets the layers below show through, or NoTransparency
+func (l *Layer) SetTransparent(index int) error {
+	if index < NoTransparency || index > 0x0F {
+		return fmt.Errorf("invalid transparent index: %d", index)
+	}
+
+	l.transparent = index
+	return nil
+}
+
+// Transparent returns the transparent index, or NoTransparency
+func (l *Layer) Transparent() int {
+	return l.transparent
+}
+
+// DrawOffsetted draws the layer onto fb shifted by a fractional offset, for smooth sub-pixel scrolling
+// Each pixel's brightness is split between the up to four target pixels it overlaps, weighted by the fractional parts
+// Contributions are summed and clamped to 0x0F; black and transparent pixels are not drawn, so fb content shows through
+// Pixels are written with the framebuffer's draw mode
+func (l *Layer) DrawOffsetted(fb *FrameBuffer, dx, dy float64) error {
+	width, height := fb.Width(), fb.Height()
+
+	ix, iy := math.Floor(dx), math.Floor(dy)
+	fx, fy := dx-ix, dy-iy
+	weights := [4]float64{(1 - fx) * (1 - fy), fx * (1 - fy), (1 - fx) * fy, fx * fy}
+	offsets := [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}}
+
+	accum := make([]float64, width*height)
+	for y := 0; y < l.Height(); y++ {
+		for x := 0; x < l.Width(); x++ {
+			pixel, err := l.GetPixel(x, y)
+			if err != nil {
+				return err
+			}
+			pixel &= 0x0F
+
+			if pixel == 0 || int(pixel) == l.transparent {
+				continue
+			}
+
+			for i, offset := range offsets {
+				tx := x + int(ix) + offset[0]
+				ty := y + int(iy) + offset[1]
+				if weights[i] == 0 || tx < 0 || tx >= width || ty < 0 || ty >= height {
+					continue
+				}
+
+				accum[ty*width+tx] += float64(pixel) * weights[i]
+			}
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			level := math.Min(math.Round(accum[y*width+x]), 0x0F)
+			if level > 0 {
+				fb.plot(x, y, byte(level))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Compositor merges a stack of layers onto a framebuffer
+type Compositor struct {
+	layers []*Layer
+}
+
+// NewCompositor creates a compositor with layers ordered bottom to top
+func NewCompositor(layers ...*Layer) *Compositor {
+	return &Compositor{
+		layers: append([]*Layer(nil), layers...),
+	}
+}
+
+// AddLayer puts a layer on top of the stack
+func (c *Compositor) AddLayer(layer *Layer) {
+	c.layers = append(c.layers, layer)
+}
+
+// RemoveLayer removes a layer from the stack
+func (c *Compositor) RemoveLayer(layer *Layer) error {
+	for i, l := range c.layers {
+		if l == layer {
+			c.layers = append(c.layers[:i], c.layers[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("layer not found")
+}
+
+// Layers returns the layers, bottom first
+func (c *Compositor) Layers() []*Layer {
+	result := make([]*Layer, len(c.layers))
+	copy(result, c.layers)
+	return result
+}
+
+// Flatten merges the visible layers, bottom first, onto the current content of fb
+// Each layer pixel is combined with the pixel below using mode, then mixed by the layer opacity
+// Pixels at a layer's transparent index are skipped, and the result replaces the fb content regardless of its draw mode
+func (c *Compositor) Flatten(fb *FrameBuffer, mode DrawMode) error {
+	if mode < DrawModeReplace || mode > DrawModeAverage {
+		return fmt.Errorf("invalid draw mode: %d", mode)
+	}
+
+	width, height := fb.Width(), fb.Height()
+	for i, layer := range c.layers {
+		if layer.Width() != width || layer.Height() != height {
+			return fmt.Errorf("layer %d is %dx%d, expected %dx%d", i, layer.Width(), layer.Height(), width, height)
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixel, err := fb.GetPixel(x, y)
+			if err != nil {
+				return err
+			}
+			pixel &= 0x0F
+			original := pixel
+
+			for _, layer := range c.layers {
+				if !layer.visible || layer.opacity == 0 {
+					continue
+				}
+
+				src, err := layer.GetPixel(x, y)
+				if err != nil {
+					return err
+				}
+				src &= 0x0F
+
+				if int(src) == layer.transparent {
+					continue
+				}
+
+				blended := applyDrawMode(mode, pixel, src)
+				pixel = byte(math.Round(float64(pixel) + (float64(blended)-float64(pixel))*layer.opacity))
+			}
+
+			if pixel != original {
+				fb.plotMode(x, y, pixel, DrawModeReplace)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package graphics
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultMarqueeGap is the default spacing in pixels between repetitions of scrolling text
+const DefaultMarqueeGap = 16
+
+// Marquee scrolls a single line of text through a fixed box
+// Text that fits the box is drawn static; longer text scrolls left and loops
+type Marquee struct {
+	font   Font
+	text   string
+	x      int
+	y      int
+	w      int
+	h      int
+	speed  float64 // Pixels per second
+	gap    int
+	color  byte
+	offset float64
+	paused bool
+}
+
+// NewMarquee creates a marquee drawing text inside the w x h box at (x, y)
+// speed is in pixels per second
+func NewMarquee(font Font, text string, x, y, w, h int, speed float64) *Marquee {
+	return &Marquee{
+		font:  font,
+		text:  text,
+		x:     x,
+		y:     y,
+		w:     w,
+		h:     h,
+		speed: speed,
+		gap:   DefaultMarqueeGap,
+		color: 0x0F,
+	}
+}
+
+// SetText replaces the text and restarts scrolling from the beginning
+func (m *Marquee) SetText(text string) {
+	m.text = text
+	m.offset = 0
+}
+
+// Text returns the current text
+func (m *Marquee) Text() string {
+	return m.text
+}
+
+// SetSpeed sets the scroll speed in pixels per second
+func (m *Marquee) SetSpeed(speed float64) {
+	m.speed = speed
+}
+
+// SetGap sets the spacing in pixels between the end of the text and its next repetition
+func (m *Marquee) SetGap(gap int) error {
+	if gap < 0 {
+		return fmt.Errorf("invalid marquee gap: %d", gap)
+	}
+
+	m.gap = gap
+	return nil
+}
+
+// SetColor sets the text color
+func (m *Marquee) SetColor(color byte) {
+	m.color = color & 0x0F
+}
+
+// Pause stops the text from advancing
+func (m *Marquee) Pause() {
+	m.paused = true
+}
+
+// Resume continues scrolling from the current offset
+func (m *Marquee) Resume() {
+	m.paused = false
+}
+
+// IsPaused returns whether scrolling is paused
+func (m *Marquee) IsPaused() bool {
+	return m.paused
+}
+
+// Offset returns the current scroll offset in pixels
+func (m *Marquee) Offset() float64 {
+	return m.offset
+}
+
+// Update advances the scroll offset by dt seconds
+// It has the shape of an animation step, so it can be driven from an Animator
+func (m *Marquee) Update(dt float64) {
+	if m.paused {
+		return
+	}
+
+	width, _, err := m.font.MeasureString(m.text)
+	if err != nil || width <= m.w {
+		m.offset = 0
+		return
+	}
+
+	cycle := float64(width + m.gap)
+	m.offset = math.Mod(m.offset+m.speed*dt, cycle)
+	if m.offset < 0 {
+		m.offset += cycle
+	}
+}
+
+// Draw clears the box and renders the text clipped to it
+func (m *Marquee) Draw(fb *FrameBuffer) error {
+	if err := fb.FillRegion(m.x, m.y, m.w, m.h, 0); err != nil {
+		return err
+	}
+
+	width, _, err := m.font.MeasureString(m.text)
+	if err != nil {
+		return err
+	}
+
+	if width <= m.w {
+		return m.drawAt(fb, m.x)
+	}
+
+	// Draw the text and, once its tail enters the box, the next repetition
+	startX := m.x - int(math.Floor(m.offset))
+	if err := m.drawAt(fb, startX); err != nil {
+		return err
+	}
+	if next := startX + width + m.gap; next < m.x+m.w {
+		return m.drawAt(fb, next)
+	}
+
+	return nil
+}
+
+// drawAt draws the text starting at x, clipping glyphs to the marquee box
+func (m *Marquee) drawAt(fb *FrameBuffer, x int) error {
+	currentX := x
+
+	for _, ch := range m.text {
+		advance, _, err := m.font.MeasureString(string(ch))
+		if err != nil {
+			return err
+		}
+
+		// Skip glyphs entirely outside the box
+		if currentX+advance > m.x && currentX < m.x+m.w {
+			if glyph, err := m.font.GetGlyph(ch); err == nil {
+				m.drawGlyph(fb, currentX, glyph)
+			}
+		}
+
+		currentX += advance
+	}
+
+	return nil
+}
+
+// drawGlyph draws 1-bit glyph data at x, writing only pixels inside the box
+func (m *Marquee) drawGlyph(fb *FrameBuffer, x int, glyph GlyphData) {
+	bytesPerRow := (glyph.Width + 7) / 8
+
+	for row := 0; row < glyph.Height; row++ {
+		py := m.y + row + glyph.BearingY
+		if py < m.y || py >= m.y+m.h {
+			continue
+		}
+
+		for col := 0; col < glyph.Width; col++ {
+			px := x + col + glyph.BearingX
+			if px < m.x || px >= m.x+m.w {
+				continue
+			}
+
+			index := row*bytesPerRow + col/8
+			if index < len(glyph.Data) && glyph.Data[index]&(0x80>>(col%8)) != 0 {
+				fb.plot(px, py, m.color)
+			}
+		}
+	}
+}
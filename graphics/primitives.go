@@ -34,6 +34,187 @@ func DrawLineBresenham(fb *FrameBuffer, x0, y0, x1, y1 int, color byte, setPixel
 	}
 }
 
+// DrawLineAA draws an anti-aliased line using Xiaolin Wu's algorithm
+// Each pixel receives the color scaled by its coverage, so partial pixels use dimmer levels
+func DrawLineAA(fb *FrameBuffer, x0, y0, x1, y1 int, color byte, setPixel func(int, int, byte)) {
+	steep := abs(y1-y0) > abs(x1-x0)
+	if steep {
+		x0, y0 = y0, x0
+		x1, y1 = y1, x1
+	}
+	if x0 > x1 {
+		x0, x1 = x1, x0
+		y0, y1 = y1, y0
+	}
+
+	plot := func(x, y int, coverage float64) {
+		level := coverageLevel(color, coverage)
+		if level == 0 {
+			return
+		}
+		if steep {
+			setPixel(y, x, level)
+		} else {
+			setPixel(x, y, level)
+		}
+	}
+
+	dx := x1 - x0
+	gradient := 1.0
+	if dx != 0 {
+		gradient = float64(y1-y0) / float64(dx)
+	}
+
+	// Endpoints lie exactly on pixel centers
+	plot(x0, y0, 1)
+	plot(x1, y1, 1)
+
+	intery := float64(y0) + gradient
+	for x := x0 + 1; x < x1; x++ {
+		y := math.Floor(intery)
+		frac := intery - y
+
+		plot(x, int(y), 1-frac)
+		plot(x, int(y)+1, frac)
+
+		intery += gradient
+	}
+}
+
+// coverageLevel scales a 4-bit color by a coverage fraction (0-1), rounding to the nearest level
+func coverageLevel(color byte, coverage float64) byte {
+	return byte(math.Round(float64(color&0x0F) * coverage))
+}
+
+// DrawThickLine draws a line of the given pixel width with rounded endpoints
+// Pixels are filled when their center lies within thickness/2 of the segment
+func DrawThickLine(fb *FrameBuffer, x0, y0, x1, y1, thickness int, color byte, setPixel func(int, int, byte)) {
+	if thickness <= 1 {
+		DrawLineBresenham(fb, x0, y0, x1, y1, color, setPixel)
+		return
+	}
+
+	// Even widths straddle pixel boundaries, so shift the segment half a pixel
+	offset := 0.0
+	if thickness%2 == 0 {
+		offset = 0.5
+	}
+
+	ax := float64(x0) + offset
+	ay := float64(y0) + offset
+	bx := float64(x1) + offset
+	by := float64(y1) + offset
+
+	radius := float64(thickness) / 2
+	pad := thickness/2 + 1
+
+	for py := min(y0, y1) - pad; py <= max(y0, y1)+pad; py++ {
+		for px := min(x0, x1) - pad; px <= max(x0, x1)+pad; px++ {
+			if segmentDistance(float64(px), float64(py), ax, ay, bx, by) < radius {
+				setPixel(px, py, color)
+			}
+		}
+	}
+}
+
+// segmentDistance returns the distance from point (px, py) to the segment (ax, ay)-(bx, by)
+func segmentDistance(px, py, ax, ay, bx, by float64) float64 {
+	dx := bx - ax
+	dy := by - ay
+	lengthSq := dx*dx + dy*dy
+
+	t := 0.0
+	if lengthSq > 0 {
+		t = ((px-ax)*dx + (py-ay)*dy) / lengthSq
+		t = math.Max(0, math.Min(1, t))
+	}
+
+	return Distance(px, py, ax+t*dx, ay+t*dy)
+}
+
+// BezierTolerance is the maximum distance in pixels a flattened Bezier segment may deviate from the curve
+const BezierTolerance = 0.25
+
+// maxBezierDepth bounds the recursive subdivision of Bezier curves
+const maxBezierDepth = 16
+
+// DrawQuadBezier draws a quadratic Bezier curve from (x0, y0) to (x1, y1) with control point (cx, cy)
+// The curve is subdivided until flat within BezierTolerance and drawn as Bresenham segments
+func DrawQuadBezier(fb *FrameBuffer, x0, y0, cx, cy, x1, y1 int, color byte, setPixel func(int, int, byte)) {
+	// Elevate to a cubic with equivalent shape
+	c1x := float64(x0) + 2.0/3.0*float64(cx-x0)
+	c1y := float64(y0) + 2.0/3.0*float64(cy-y0)
+	c2x := float64(x1) + 2.0/3.0*float64(cx-x1)
+	c2y := float64(y1) + 2.0/3.0*float64(cy-y1)
+
+	drawBezier(fb, [4][2]float64{
+		{float64(x0), float64(y0)},
+		{c1x, c1y},
+		{c2x, c2y},
+		{float64(x1), float64(y1)},
+	}, color, setPixel)
+}
+
+// DrawCubicBezier draws a cubic Bezier curve from (x0, y0) to (x1, y1) with control points (cx0, cy0) and (cx1, cy1)
+// The curve is subdivided until flat within BezierTolerance and drawn as Bresenham segments
+func DrawCubicBezier(fb *FrameBuffer, x0, y0, cx0, cy0, cx1, cy1, x1, y1 int, color byte, setPixel func(int, int, byte)) {
+	drawBezier(fb, [4][2]float64{
+		{float64(x0), float64(y0)},
+		{float64(cx0), float64(cy0)},
+		{float64(cx1), float64(cy1)},
+		{float64(x1), float64(y1)},
+	}, color, setPixel)
+}
+
+// drawBezier flattens a cubic curve and connects the points with lines
+// The first and last points are the exact integer endpoints
+func drawBezier(fb *FrameBuffer, curve [4][2]float64, color byte, setPixel func(int, int, byte)) {
+	points := [][2]float64{curve[0]}
+	points = flattenCubic(curve, 0, points)
+
+	px := int(curve[0][0])
+	py := int(curve[0][1])
+	setPixel(px, py, color)
+
+	for _, p := range points[1:] {
+		nx := int(math.Round(p[0]))
+		ny := int(math.Round(p[1]))
+		if nx == px && ny == py {
+			continue
+		}
+
+		DrawLineBresenham(fb, px, py, nx, ny, color, setPixel)
+		px, py = nx, ny
+	}
+}
+
+// flattenCubic appends the end points of flat sub-curves using de Casteljau subdivision
+func flattenCubic(c [4][2]float64, depth int, points [][2]float64) [][2]float64 {
+	if depth >= maxBezierDepth || cubicFlat(c) {
+		return append(points, c[3])
+	}
+
+	mid := func(a, b [2]float64) [2]float64 {
+		return [2]float64{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2}
+	}
+
+	p01 := mid(c[0], c[1])
+	p12 := mid(c[1], c[2])
+	p23 := mid(c[2], c[3])
+	p012 := mid(p01, p12)
+	p123 := mid(p12, p23)
+	center := mid(p012, p123)
+
+	points = flattenCubic([4][2]float64{c[0], p01, p012, center}, depth+1, points)
+	return flattenCubic([4][2]float64{center, p123, p23, c[3]}, depth+1, points)
+}
+
+// cubicFlat reports whether both control points lie within BezierTolerance of the chord
+func cubicFlat(c [4][2]float64) bool {
+	return segmentDistance(c[1][0], c[1][1], c[0][0], c[0][1], c[3][0], c[3][1]) <= BezierTolerance &&
+		segmentDistance(c[2][0], c[2][1], c[0][0], c[0][1], c[3][0], c[3][1]) <= BezierTolerance
+}
+
 // DrawCircle draws a circle using midpoint algorithm
 func DrawCircle(fb *FrameBuffer, cx, cy, r int, color byte, filled bool, setPixel func(int, int, byte)) {
 	if filled {
@@ -102,6 +283,99 @@ func DrawFilledCircle(fb *FrameBuffer, cx, cy, r int, color byte, setPixel func(
 	}
 }
 
+// DrawArc draws the part of a circle outline between two angles
+// Angles are in degrees, measured clockwise from 3 o'clock; the arc runs from startDeg to endDeg
+// and wraps through 0 when startDeg > endDeg. A sweep of 360 degrees or more draws the full circle
+func DrawArc(fb *FrameBuffer, cx, cy, r int, startDeg, endDeg float64, color byte, setPixel func(int, int, byte)) {
+	if r <= 0 {
+		return
+	}
+
+	plot := func(dx, dy int) {
+		if angleInSweep(pointAngle(dx, dy), startDeg, endDeg) {
+			setPixel(cx+dx, cy+dy, color)
+		}
+	}
+
+	x := 0
+	y := r
+	d := 3 - 2*r
+
+	for x <= y {
+		// Draw 8 symmetric points, each filtered by angle
+		plot(x, y)
+		plot(-x, y)
+		plot(x, -y)
+		plot(-x, -y)
+		plot(y, x)
+		plot(-y, x)
+		plot(y, -x)
+		plot(-y, -x)
+
+		if d < 0 {
+			d = d + 4*x + 6
+		} else {
+			d = d + 4*(x-y) + 10
+			y--
+		}
+		x++
+	}
+}
+
+// DrawPie draws a filled circle wedge between two angles, using the same convention as DrawArc
+func DrawPie(fb *FrameBuffer, cx, cy, r int, startDeg, endDeg float64, color byte, setPixel func(int, int, byte)) {
+	if r <= 0 {
+		return
+	}
+
+	// r*r + r matches the extent of the midpoint circle outline
+	limit := r*r + r
+
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if dx*dx+dy*dy > limit {
+				continue
+			}
+
+			if (dx == 0 && dy == 0) || angleInSweep(pointAngle(dx, dy), startDeg, endDeg) {
+				setPixel(cx+dx, cy+dy, color)
+			}
+		}
+	}
+}
+
+// pointAngle returns the clockwise angle in degrees (0-360) of offset (dx, dy) from 3 o'clock
+func pointAngle(dx, dy int) float64 {
+	angle := math.Atan2(float64(dy), float64(dx)) * 180 / math.Pi
+	if angle < 0 {
+		angle += 360
+	}
+	return angle
+}
+
+// angleInSweep reports whether angle lies on the sweep from startDeg to endDeg
+func angleInSweep(angle, startDeg, endDeg float64) bool {
+	if math.Abs(endDeg-startDeg) >= 360 {
+		return true
+	}
+
+	start := math.Mod(startDeg, 360)
+	if start < 0 {
+		start += 360
+	}
+	end := math.Mod(endDeg, 360)
+	if end < 0 {
+		end += 360
+	}
+
+	if start <= end {
+		return angle >= start && angle <= end
+	}
+
+	// Sweep wraps through 0 degrees
+	return angle >= start || angle <= end
+}
+
 // drawHorizontalLine draws a horizontal line from x1 to x2 at y
 func drawHorizontalLine(x1, x2, y int, color byte, setPixel func(int, int, byte)) {
 	if x1 > x2 {
@@ -138,6 +412,73 @@ func DrawRect(fb *FrameBuffer, x, y, w, h int, color byte, filled bool, setPixel
 	}
 }
 
+// DrawRoundedRect draws a rectangle with quarter-circle corners
+// The radius is clamped to half the smaller dimension; radius 0 draws a plain rectangle
+func DrawRoundedRect(fb *FrameBuffer, x, y, w, h, radius int, color byte, filled bool, setPixel func(int, int, byte)) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	radius = Clamp(radius, 0, min(w, h)/2)
+	if radius == 0 {
+		DrawRect(fb, x, y, w, h, color, filled, setPixel)
+		return
+	}
+
+	// Corner circle centers
+	left := x + radius
+	right := x + w - 1 - radius
+	top := y + radius
+	bottom := y + h - 1 - radius
+
+	if filled {
+		// Body between the corner rows
+		for py := top; py <= bottom; py++ {
+			drawHorizontalLine(x, x+w-1, py, color, setPixel)
+		}
+	} else {
+		// Straight edges between the corners
+		drawHorizontalLine(left, right, y, color, setPixel)
+		drawHorizontalLine(left, right, y+h-1, color, setPixel)
+		for py := top; py <= bottom; py++ {
+			setPixel(x, py, color)
+			setPixel(x+w-1, py, color)
+		}
+	}
+
+	cx := 0
+	cy := radius
+	d := 3 - 2*radius
+
+	for cx <= cy {
+		if filled {
+			// Spans joining the left and right corner arcs
+			drawHorizontalLine(left-cx, right+cx, top-cy, color, setPixel)
+			drawHorizontalLine(left-cy, right+cy, top-cx, color, setPixel)
+			drawHorizontalLine(left-cx, right+cx, bottom+cy, color, setPixel)
+			drawHorizontalLine(left-cy, right+cy, bottom+cx, color, setPixel)
+		} else {
+			// Draw the 8 corner arc points
+			setPixel(left-cx, top-cy, color)
+			setPixel(left-cy, top-cx, color)
+			setPixel(right+cx, top-cy, color)
+			setPixel(right+cy, top-cx, color)
+			setPixel(left-cx, bottom+cy, color)
+			setPixel(left-cy, bottom+cx, color)
+			setPixel(right+cx, bottom+cy, color)
+			setPixel(right+cy, bottom+cx, color)
+		}
+
+		if d < 0 {
+			d = d + 4*cx + 6
+		} else {
+			d = d + 4*(cx-cy) + 10
+			cy--
+		}
+		cx++
+	}
+}
+
 // DrawEllipse draws an ellipse using midpoint algorithm
 func DrawEllipse(fb *FrameBuffer, cx, cy, rx, ry int, color byte, filled bool, setPixel func(int, int, byte)) {
 	if rx <= 0 || ry <= 0 {
@@ -0,0 +1,90 @@
+package graphics
+
+import (
+	"fmt"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRLevel is a QR code error correction level
+type QRLevel int
+
+const (
+	QRLow     QRLevel = iota // Recovers 7% of data
+	QRMedium                 // Recovers 15% of data
+	QRHigh                   // Recovers 25% of data
+	QRHighest                // Recovers 30% of data
+)
+
+// qrBitmap encodes data and returns its modules, including the 4-module quiet zone
+func qrBitmap(data string, level QRLevel) ([][]bool, error) {
+	var recovery qrcode.RecoveryLevel
+	switch level {
+	case QRLow:
+		recovery = qrcode.Low
+	case QRMedium:
+		recovery = qrcode.Medium
+	case QRHigh:
+		recovery = qrcode.High
+	case QRHighest:
+		recovery = qrcode.Highest
+	default:
+		return nil, fmt.Errorf("invalid QR error correction level: %d", level)
+	}
+
+	code, err := qrcode.New(data, recovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	return code.Bitmap(), nil
+}
+
+// QRCodeSize returns the side of the QR code for data in modules, including the quiet zone
+func QRCodeSize(data string, level QRLevel) (int, error) {
+	bitmap, err := qrBitmap(data, level)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(bitmap), nil
+}
+
+// DrawQRCode draws data as a QR code with medium error correction
+func DrawQRCode(fb *FrameBuffer, x, y int, data string, scale int, color byte) error {
+	return DrawQRCodeLevel(fb, x, y, data, scale, QRMedium, color)
+}
+
+// DrawQRCodeLevel draws data as a QR code with the given error correction level
+// Each module is scale x scale pixels, and the top-left corner of the quiet zone is at (x, y)
+// The quiet zone and light modules are drawn in color and dark modules in black, so the code scans as dark on light
+func DrawQRCodeLevel(fb *FrameBuffer, x, y int, data string, scale int, level QRLevel, color byte) error {
+	if scale < 1 {
+		return fmt.Errorf("invalid QR code scale: %d", scale)
+	}
+
+	bitmap, err := qrBitmap(data, level)
+	if err != nil {
+		return err
+	}
+
+	size := len(bitmap) * scale
+	if x < 0 || y < 0 || x+size > fb.Width() || y+size > fb.Height() {
+		return fmt.Errorf("QR code of %dx%d pixels at (%d, %d) does not fit %dx%d display", size, size, x, y, fb.Width(), fb.Height())
+	}
+
+	for row, modules := range bitmap {
+		for col, dark := range modules {
+			moduleColor := color
+			if dark {
+				moduleColor = 0
+			}
+
+			if err := fb.FillRegion(x+col*scale, y+row*scale, scale, scale, moduleColor); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package graphics
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Non-numeric symbols accepted by DrawSevenSegment
+const (
+	SegmentBlank = -1
+	SegmentMinus = -2
+)
+
+// Segment bits in the usual a-g order: top, upper right, lower right, bottom, lower left, upper left, middle
+var sevenSegmentDigits = [10]byte{0x3F, 0x06, 0x5B, 0x4F, 0x66, 0x6D, 0x7D, 0x07, 0x7F, 0x6F}
+
+// defaultSegmentThickness scales the segment thickness to the digit box
+func defaultSegmentThickness(w, h int) int {
+	return max(min(w, h)/5, 1)
+}
+
+// DrawSevenSegment draws a single digit 0-9, SegmentBlank or SegmentMinus in the w x h box at (x, y)
+// Segment thickness is scaled to the box; use DrawSevenSegmentThickness to set it
+func DrawSevenSegment(fb *FrameBuffer, x, y, w, h, digit int, color byte) error {
+	return DrawSevenSegmentThickness(fb, x, y, w, h, digit, defaultSegmentThickness(w, h), color)
+}
+
+// DrawSevenSegmentThickness draws a single digit with segments thickness pixels wide
+// Only lit segments are drawn, the rest of the box is left untouched
+func DrawSevenSegmentThickness(fb *FrameBuffer, x, y, w, h, digit, thickness int, color byte) error {
+	var segments byte
+	switch {
+	case digit >= 0 && digit <= 9:
+		segments = sevenSegmentDigits[digit]
+	case digit == SegmentMinus:
+		segments = 0x40
+	case digit == SegmentBlank:
+		segments = 0
+	default:
+		return fmt.Errorf("invalid seven-segment digit: %d", digit)
+	}
+
+	if thickness < 1 || 2*thickness > w || 3*thickness > h {
+		return fmt.Errorf("invalid segment thickness %d for %dx%d digit", thickness, w, h)
+	}
+
+	t := thickness
+	mid := y + (h-t)/2
+	upper := mid - y + t
+	lower := y + h - mid
+
+	rects := [7][4]int{
+		{x, y, w, t},               // a
+		{x + w - t, y, t, upper},   // b
+		{x + w - t, mid, t, lower}, // c
+		{x, y + h - t, w, t},       // d
+		{x, mid, t, lower},         // e
+		{x, y, t, upper},           // f
+		{x, mid, w, t},             // g
+	}
+
+	for i, r := range rects {
+		if segments&(1<<i) == 0 {
+			continue
+		}
+
+		if err := fb.FillRegion(r[0], r[1], r[2], r[3], color); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SevenSegmentDisplay draws numbers and clock strings as a row of seven-segment digits
+type SevenSegmentDisplay struct {
+	digitW    int
+	digitH    int
+	thickness int
+	spacing   int
+}
+
+// NewSevenSegmentDisplay creates a display with digitW x digitH digits
+// Thickness is scaled to the digit size and digits are spaced by the thickness
+func NewSevenSegmentDisplay(digitW, digitH int) *SevenSegmentDisplay {
+	thickness := defaultSegmentThickness(digitW, digitH)
+	return &SevenSegmentDisplay{
+		digitW:    digitW,
+		digitH:    digitH,
+		thickness: thickness,
+		spacing:   thickness,
+	}
+}
+
+// SetThickness sets the segment thickness in pixels
+func (ssd *SevenSegmentDisplay) SetThickness(thickness int) error {
+	if thickness < 1 || 2*thickness > ssd.digitW || 3*thickness > ssd.digitH {
+		return fmt.Errorf("invalid segment thickness %d for %dx%d digit", thickness, ssd.digitW, ssd.digitH)
+	}
+
+	ssd.thickness = thickness
+	return nil
+}
+
+// SetSpacing sets the gap in pixels between digits and separators
+func (ssd *SevenSegmentDisplay) SetSpacing(spacing int) error {
+	if spacing < 0 {
+		return fmt.Errorf("invalid digit spacing: %d", spacing)
+	}
+
+	ssd.spacing = spacing
+	return nil
+}
+
+// DrawNumber draws an integer with a leading minus when negative
+// Returns the width drawn in pixels
+func (ssd *SevenSegmentDisplay) DrawNumber(fb *FrameBuffer, x, y, n int, color byte) (int, error) {
+	return ssd.DrawString(fb, x, y, strconv.Itoa(n), color)
+}
+
+// DrawString draws digits, '-', ' ' (blank digit), ':' and '.' for clock and meter layouts
+// Colons and decimal points are one segment thickness wide
+// Returns the width drawn in pixels
+func (ssd *SevenSegmentDisplay) DrawString(fb *FrameBuffer, x, y int, s string, color byte) (int, error) {
+	t := ssd.thickness
+	cursor := x
+
+	for i, ch := range s {
+		if i > 0 {
+			cursor += ssd.spacing
+		}
+
+		switch {
+		case ch >= '0' && ch <= '9', ch == '-', ch == ' ':
+			digit := SegmentBlank
+			switch ch {
+			case '-':
+				digit = SegmentMinus
+			case ' ':
+			default:
+				digit = int(ch - '0')
+			}
+
+			if err := DrawSevenSegmentThickness(fb, cursor, y, ssd.digitW, ssd.digitH, digit, t, color); err != nil {
+				return 0, err
+			}
+			cursor += ssd.digitW
+		case ch == ':':
+			// Two dots centered in the upper and lower halves
+			for _, dy := range []int{ssd.digitH/4 - t/2, ssd.digitH*3/4 - t/2} {
+				if err := fb.FillRegion(cursor, y+dy, t, t, color); err != nil {
+					return 0, err
+				}
+			}
+			cursor += t
+		case ch == '.':
+			if err := fb.FillRegion(cursor, y+ssd.digitH-t, t, t, color); err != nil {
+				return 0, err
+			}
+			cursor += t
+		default:
+			return 0, fmt.Errorf("unsupported seven-segment character: %q", ch)
+		}
+	}
+
+	return cursor - x, nil
+}
@@ -2,6 +2,9 @@ package graphics
 
 import (
 	"fmt"
+	"image"
+	"strings"
+	"unicode/utf8"
 )
 
 // Font defines the interface for text rendering
@@ -39,6 +42,15 @@ const (
 	AlignRight
 )
 
+// VerticalAlignment defines vertical text alignment modes within a box
+type VerticalAlignment int
+
+const (
+	AlignTop VerticalAlignment = iota
+	AlignMiddle
+	AlignBottom
+)
+
 // TextOptions holds text rendering options
 type TextOptions struct {
 	Alignment   TextAlignment
@@ -61,6 +73,7 @@ func DefaultTextOptions() TextOptions {
 type TextRenderer struct {
 	font Font
 	opts TextOptions
+	clip *image.Rectangle // Glyph pixels outside are dropped, nil when unclipped
 }
 
 // NewTextRenderer creates a new text renderer
@@ -76,19 +89,70 @@ func (tr *TextRenderer) SetOptions(opts TextOptions) {
 	tr.opts = opts
 }
 
-// DrawText draws text with current options
+// SetClipRect restricts drawing to the w x h rectangle at (x, y)
+func (tr *TextRenderer) SetClipRect(x, y, w, h int) error {
+	if w < 0 || h < 0 {
+		return fmt.Errorf("invalid clip dimensions: %dx%d", w, h)
+	}
+
+	clip := image.Rect(x, y, x+w, y+h)
+	tr.clip = &clip
+	return nil
+}
+
+// ClearClip removes the clip rectangle
+func (tr *TextRenderer) ClearClip() {
+	tr.clip = nil
+}
+
+// DrawText draws text with current options, inserting CharSpacing between glyphs
 func (tr *TextRenderer) DrawText(fb *FrameBuffer, x, y int, text string) (int, error) {
-	return tr.font.DrawString(fb, x, y, text, tr.opts.Color)
+	return tr.drawLine(fb, x, y, text)
+}
+
+// MeasureText returns the width and height of a single line of text, including CharSpacing
+func (tr *TextRenderer) MeasureText(text string) (width, height int, err error) {
+	width, err = tr.measureLine(text)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return width, tr.font.Height(), nil
 }
 
 // DrawMultilineText draws multiple lines of text
+// Lines are aligned according to TextOptions.Alignment within the width of the widest line
 func (tr *TextRenderer) DrawMultilineText(fb *FrameBuffer, x, y int, text string) error {
+	width, _, err := tr.MeasureMultilineText(text)
+	if err != nil {
+		return err
+	}
+
+	return tr.DrawMultilineTextInWidth(fb, x, y, width, text)
+}
+
+// DrawMultilineTextInWidth draws multiple lines of text inside a box of the given width
+// starting at x, aligning each line according to TextOptions.Alignment
+func (tr *TextRenderer) DrawMultilineTextInWidth(fb *FrameBuffer, x, y, width int, text string) error {
 	// Split text by newlines
 	lines := splitLines(text)
 	currentY := y
 
 	for _, line := range lines {
-		if _, err := tr.font.DrawString(fb, x, currentY, line, tr.opts.Color); err != nil {
+		lineWidth, err := tr.measureLine(line)
+		if err != nil {
+			return err
+		}
+
+		drawX := x
+		switch tr.opts.Alignment {
+		case AlignCenter:
+			drawX = x + (width-lineWidth)/2
+		case AlignRight:
+			drawX = x + width - lineWidth
+		}
+
+		if _, err := tr.drawLine(fb, drawX, currentY, line); err != nil {
 			return fmt.Errorf("failed to draw line: %w", err)
 		}
 
@@ -107,7 +171,7 @@ func (tr *TextRenderer) MeasureMultilineText(text string) (width, height int, er
 
 	maxWidth := 0
 	for _, line := range lines {
-		w, _, err := tr.font.MeasureString(line)
+		w, err := tr.measureLine(line)
 		if err != nil {
 			return 0, 0, err
 		}
@@ -121,6 +185,168 @@ func (tr *TextRenderer) MeasureMultilineText(text string) (width, height int, er
 	return maxWidth, totalHeight, nil
 }
 
+// DrawWrappedText draws text word-wrapped to fit within width pixels, starting at (x, y)
+// Lines break on spaces; words wider than the box are broken mid-word. Each line is
+// aligned according to TextOptions.Alignment. Returns the total height consumed
+func (tr *TextRenderer) DrawWrappedText(fb *FrameBuffer, x, y, width int, text string) (int, error) {
+	lines, err := tr.wrapLines(text, width)
+	if err != nil {
+		return 0, err
+	}
+
+	currentY := y
+	for _, line := range lines {
+		lineWidth, err := tr.measureLine(line)
+		if err != nil {
+			return 0, err
+		}
+
+		drawX := x
+		switch tr.opts.Alignment {
+		case AlignCenter:
+			drawX = x + (width-lineWidth)/2
+		case AlignRight:
+			drawX = x + width - lineWidth
+		}
+
+		if _, err := tr.drawLine(fb, drawX, currentY, line); err != nil {
+			return 0, fmt.Errorf("failed to draw line: %w", err)
+		}
+
+		currentY += tr.font.Height() + tr.opts.LineSpacing
+	}
+
+	return tr.font.Height()*len(lines) + tr.opts.LineSpacing*(len(lines)-1), nil
+}
+
+// wrapLines splits text into lines no wider than width pixels
+func (tr *TextRenderer) wrapLines(text string, width int) ([]string, error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("invalid wrap width: %d", width)
+	}
+
+	var lines []string
+	for _, paragraph := range splitLines(text) {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+
+			fits, err := tr.fits(candidate, width)
+			if err != nil {
+				return nil, err
+			}
+			if fits {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+			}
+
+			// Break words wider than the box at the last rune that fits
+			for {
+				fits, err := tr.fits(word, width)
+				if err != nil {
+					return nil, err
+				}
+				if fits {
+					break
+				}
+
+				split, err := tr.fittingPrefix(word, width)
+				if err != nil {
+					return nil, err
+				}
+				lines = append(lines, word[:split])
+				word = word[split:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// fits reports whether text fits within width pixels
+func (tr *TextRenderer) fits(text string, width int) (bool, error) {
+	w, err := tr.measureLine(text)
+	if err != nil {
+		return false, err
+	}
+	return w <= width, nil
+}
+
+// fittingPrefix returns the byte length of the longest prefix of word that fits
+// within width pixels, always keeping at least one rune so wrapping makes progress
+func (tr *TextRenderer) fittingPrefix(word string, width int) (int, error) {
+	_, size := utf8.DecodeRuneInString(word)
+	end := size
+
+	for end < len(word) {
+		_, size := utf8.DecodeRuneInString(word[end:])
+		fits, err := tr.fits(word[:end+size], width)
+		if err != nil {
+			return 0, err
+		}
+		if !fits {
+			break
+		}
+		end += size
+	}
+
+	return end, nil
+}
+
+// measureLine returns the width of a single line including CharSpacing between glyphs
+func (tr *TextRenderer) measureLine(line string) (int, error) {
+	w, _, err := tr.font.MeasureString(line)
+	if err != nil {
+		return 0, err
+	}
+
+	if count := utf8.RuneCountInString(line); count > 1 {
+		w += tr.opts.CharSpacing * (count - 1)
+	}
+
+	return w, nil
+}
+
+// drawLine draws a single line, inserting CharSpacing between glyphs
+// Returns the width of the drawn line
+func (tr *TextRenderer) drawLine(fb *FrameBuffer, x, y int, line string) (int, error) {
+	if tr.clip != nil {
+		// Narrow the framebuffer clip for the duration of the draw
+		if err := fb.PushClip(tr.clip.Min.X, tr.clip.Min.Y, tr.clip.Dx(), tr.clip.Dy()); err != nil {
+			return 0, err
+		}
+		defer fb.PopClip()
+	}
+
+	if tr.opts.CharSpacing == 0 {
+		return tr.font.DrawString(fb, x, y, line, tr.opts.Color)
+	}
+
+	width := 0
+	for i, ch := range []rune(line) {
+		if i > 0 {
+			width += tr.opts.CharSpacing
+		}
+
+		advance, err := tr.font.DrawString(fb, x+width, y, string(ch), tr.opts.Color)
+		if err != nil {
+			return 0, err
+		}
+		width += advance
+	}
+
+	return width, nil
+}
+
 // Helper function to split text by newlines
 func splitLines(text string) []string {
 	var lines []string
@@ -183,12 +409,91 @@ func (atd *AlignedTextDrawer) DrawAlignedText(fb *FrameBuffer, x, y int, text st
 	return err
 }
 
-// DrawCenteredText is a convenience function for center-aligned text
+// DrawAlignedTextBox draws multiline text aligned within the w x h box at (x, y)
+// Text taller than the box is top-aligned and lines that do not fit are not drawn
+func (atd *AlignedTextDrawer) DrawAlignedTextBox(fb *FrameBuffer, x, y, w, h int, text string, halign TextAlignment, valign VerticalAlignment, color byte) error {
+	opts := atd.renderer.opts
+	opts.Alignment = halign
+	opts.Color = color
+	atd.renderer.SetOptions(opts)
+
+	_, textHeight, err := atd.renderer.MeasureMultilineText(text)
+	if err != nil {
+		return err
+	}
+
+	drawY := y
+	if textHeight <= h {
+		switch valign {
+		case AlignMiddle:
+			drawY = y + (h-textHeight)/2
+		case AlignBottom:
+			drawY = y + h - textHeight
+		}
+	}
+
+	lineHeight := atd.renderer.font.Height()
+	for _, line := range splitLines(text) {
+		if drawY+lineHeight > y+h {
+			break
+		}
+
+		lineWidth, err := atd.renderer.measureLine(line)
+		if err != nil {
+			return err
+		}
+
+		drawX := x
+		switch halign {
+		case AlignCenter:
+			drawX = x + (w-lineWidth)/2
+		case AlignRight:
+			drawX = x + w - lineWidth
+		}
+
+		if _, err := atd.renderer.drawLine(fb, drawX, drawY, line); err != nil {
+			return fmt.Errorf("failed to draw line: %w", err)
+		}
+
+		drawY += lineHeight + opts.LineSpacing
+	}
+
+	return nil
+}
+
+// DrawAlignedLine draws a line of text aligned within the framebuffer's safe area
+func (atd *AlignedTextDrawer) DrawAlignedLine(fb *FrameBuffer, y int, text string, alignment TextAlignment, color byte) error {
+	safeX, _, safeW, _ := fb.SafeBounds()
+
+	anchorX := safeX
+	switch alignment {
+	case AlignCenter:
+		anchorX = safeX + safeW/2
+	case AlignRight:
+		anchorX = safeX + safeW
+	}
+
+	return atd.DrawAlignedText(fb, anchorX, y, text, alignment, color)
+}
+
+// DrawCenteredLine is a convenience function for text centered within the safe area
+func (atd *AlignedTextDrawer) DrawCenteredLine(fb *FrameBuffer, y int, text string, color byte) error {
+	return atd.DrawAlignedLine(fb, y, text, AlignCenter, color)
+}
+
+// DrawInSafeArea draws multiline text aligned within the framebuffer's safe area on both axes
+func (atd *AlignedTextDrawer) DrawInSafeArea(fb *FrameBuffer, text string, halign TextAlignment, valign VerticalAlignment, color byte) error {
+	x, y, w, h := fb.SafeBounds()
+	return atd.DrawAlignedTextBox(fb, x, y, w, h, text, halign, valign, color)
+}
+
+// DrawCenteredText is a convenience function for text centered on x
+// It ignores the safe area; use DrawCenteredLine or DrawInSafeArea to center within it
 func (atd *AlignedTextDrawer) DrawCenteredText(fb *FrameBuffer, x, y int, text string, color byte) error {
 	return atd.DrawAlignedText(fb, x, y, text, AlignCenter, color)
 }
 
-// DrawRightAlignedText is a convenience function for right-aligned text
+// DrawRightAlignedText is a convenience function for text right-aligned to x, ignoring the safe area
 func (atd *AlignedTextDrawer) DrawRightAlignedText(fb *FrameBuffer, x, y int, text string, color byte) error {
 	return atd.DrawAlignedText(fb, x, y, text, AlignRight, color)
 }
@@ -49,6 +49,30 @@ func TestDefaultBitmapFont(t *testing.T) {
 	}
 }
 
+func TestDefaultBitmapFontCoversPrintableASCII(t *testing.T) {
+	bf := DefaultBitmapFont()
+	seen := make(map[string]rune)
+
+	for ch := rune(0x21); ch <= 0x7E; ch++ {
+		glyph, err := bf.GetGlyph(ch)
+		if err != nil {
+			t.Fatalf("missing glyph for %q: %v", ch, err)
+		}
+
+		key := string(glyph.Data)
+		if prev, ok := seen[key]; ok {
+			t.Errorf("glyphs %q and %q share the same bitmap", prev, ch)
+		}
+		seen[key] = ch
+	}
+
+	// Top row of 'A' is the apex only
+	glyph, _ := bf.GetGlyph('A')
+	if glyph.Data[0] != 0b01110000 {
+		t.Errorf("unexpected top row for 'A': %08b", glyph.Data[0])
+	}
+}
+
 func TestBitmapFontDrawString(t *testing.T) {
 	dev := device.NewSSD1322(256, 64)
 	fb := NewFrameBuffer(dev)
@@ -86,6 +110,71 @@ func TestTextRenderer(t *testing.T) {
 	}
 }
 
+func TestTextRendererCharSpacing(t *testing.T) {
+	tr := NewTextRenderer(DefaultBitmapFont())
+
+	narrow, _, err := tr.MeasureText("Test")
+	if err != nil {
+		t.Fatalf("measure text failed: %v", err)
+	}
+
+	opts := DefaultTextOptions()
+	opts.CharSpacing = 3
+	tr.SetOptions(opts)
+
+	wide, _, _ := tr.MeasureText("Test")
+	if wide != narrow+3*3 {
+		t.Errorf("expected width %d with spacing, got %d", narrow+9, wide)
+	}
+
+	multiWidth, _, _ := tr.MeasureMultilineText("Test\nT")
+	if multiWidth != wide {
+		t.Errorf("expected multiline width %d, got %d", wide, multiWidth)
+	}
+
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	drawn, err := tr.DrawText(fb, 0, 0, "Test")
+	if err != nil {
+		t.Fatalf("draw text failed: %v", err)
+	}
+	if drawn != wide {
+		t.Errorf("expected drawn width %d, got %d", wide, drawn)
+	}
+}
+
+func TestTextRendererClipRect(t *testing.T) {
+	tr := NewTextRenderer(DefaultBitmapFont())
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	if err := tr.SetClipRect(10, 10, 20, 7); err != nil {
+		t.Fatalf("SetClipRect failed: %v", err)
+	}
+	tr.DrawText(fb, 10, 10, "HHHHHHHHHHHHHHHHHHHH")
+
+	for y := 0; y < fb.Height(); y++ {
+		for x := 30; x < fb.Width(); x++ {
+			if pixel, _ := fb.GetPixel(x, y); pixel != 0 {
+				t.Fatalf("pixel (%d, %d) beyond the clip should be zero", x, y)
+			}
+		}
+	}
+	if countSetPixels(fb) == 0 {
+		t.Error("expected text inside the clip to be drawn")
+	}
+
+	// The clip only applies while drawing text
+	fb.SetPixel(100, 40, 0x0F)
+	if pixel, _ := fb.GetPixel(100, 40); pixel != 0x0F {
+		t.Error("clip should not remain active on the framebuffer")
+	}
+
+	tr.ClearClip()
+	tr.DrawText(fb, 10, 30, "HHHHHHHHHH")
+	if pixel, _ := fb.GetPixel(58, 30); pixel == 0 {
+		t.Error("expected unclipped text past x=30 after ClearClip")
+	}
+}
+
 func TestMultilineText(t *testing.T) {
 	bf := DefaultBitmapFont()
 	tr := NewTextRenderer(bf)
@@ -117,6 +206,43 @@ func TestMeasureMultilineText(t *testing.T) {
 	}
 }
 
+func TestDrawWrappedText(t *testing.T) {
+	tr := NewTextRenderer(DefaultBitmapFont())
+	opts := DefaultTextOptions()
+	opts.LineSpacing = 2
+	tr.SetOptions(opts)
+
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	// 36 pixels fit six 6-pixel glyphs
+	height, err := tr.DrawWrappedText(fb, 0, 0, 36, "hello world foo")
+	if err != nil {
+		t.Fatalf("draw wrapped text failed: %v", err)
+	}
+	if height != 3*7+2*2 {
+		t.Errorf("expected height 25, got %d", height)
+	}
+
+	lines, _ := tr.wrapLines("abcdefghijkl mn", 36)
+	expected := []string{"abcdef", "ghijkl", "mn"}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+
+	// Character spacing narrows what fits on a line
+	opts.CharSpacing = 2
+	tr.SetOptions(opts)
+	lines, _ = tr.wrapLines("abcdef", 36)
+	if len(lines) != 2 || lines[0] != "abcd" {
+		t.Errorf("expected CharSpacing to wrap after 4 glyphs, got %v", lines)
+	}
+}
+
 func TestAlignedTextDrawer(t *testing.T) {
 	bf := DefaultBitmapFont()
 	atd := NewAlignedTextDrawer(bf)
@@ -151,3 +277,356 @@ func TestCenteredText(t *testing.T) {
 		t.Fatalf("centered text failed: %v", err)
 	}
 }
+
+func TestMultilineTextCenterAlignment(t *testing.T) {
+	bf := DefaultBitmapFont()
+	tr := NewTextRenderer(bf)
+
+	opts := DefaultTextOptions()
+	opts.Alignment = AlignCenter
+	tr.SetOptions(opts)
+
+	dev := device.NewSSD1322(256, 64)
+	fb := NewFrameBuffer(dev)
+
+	if err := tr.DrawMultilineTextInWidth(fb, 10, 10, 40, "HH\nH"); err != nil {
+		t.Fatalf("draw multiline text failed: %v", err)
+	}
+
+	// "HH" is 12 pixels wide: (40-12)/2 = 14
+	if x := leftmostPixel(fb, 10, 16); x != 24 {
+		t.Errorf("expected first line to start at x=24, got %d", x)
+	}
+
+	// "H" is 6 pixels wide: (40-6)/2 = 17
+	if x := leftmostPixel(fb, 17, 23); x != 27 {
+		t.Errorf("expected second line to start at x=27, got %d", x)
+	}
+}
+
+// leftmostPixel returns the smallest x with a set pixel in rows y0..y1, or -1
+func leftmostPixel(fb *FrameBuffer, y0, y1 int) int {
+	for x := 0; x < fb.Width(); x++ {
+		for y := y0; y <= y1; y++ {
+			if pixel, _ := fb.GetPixel(x, y); pixel != 0 {
+				return x
+			}
+		}
+	}
+	return -1
+}
+
+func TestBitmapFontGlyphManagement(t *testing.T) {
+	bf := NewBitmapFont(5, 7, 6)
+
+	bf.AddGlyph('A', GlyphData{Width: 5, Height: 7, Data: make([]byte, 7)})
+	bf.AddGlyph('B', GlyphData{Width: 5, Height: 7, Data: make([]byte, 7)})
+
+	if bf.GlyphCount() != 2 {
+		t.Errorf("expected 2 glyphs, got %d", bf.GlyphCount())
+	}
+
+	bf.RemoveGlyph('A')
+	if bf.GlyphCount() != 1 {
+		t.Errorf("expected 1 glyph after remove, got %d", bf.GlyphCount())
+	}
+	if _, err := bf.GetGlyph('A'); err == nil {
+		t.Error("removed glyph should not be found")
+	}
+
+	bf.ClearGlyphs()
+	if bf.GlyphCount() != 0 {
+		t.Errorf("expected 0 glyphs after clear, got %d", bf.GlyphCount())
+	}
+}
+
+func TestBitmapFontClone(t *testing.T) {
+	original := DefaultBitmapFont()
+	count := original.GlyphCount()
+
+	clone := original.Clone()
+	clone.RemoveGlyph('A')
+
+	glyph, _ := clone.GetGlyph('B')
+	glyph.Data[0] = 0x00
+
+	if original.GlyphCount() != count {
+		t.Errorf("original glyph count changed: expected %d, got %d", count, original.GlyphCount())
+	}
+
+	originalB, _ := original.GetGlyph('B')
+	if originalB.Data[0] == 0x00 {
+		t.Error("modifying clone glyph data should not affect the original")
+	}
+}
+
+func TestCenteredLineRespectsSafeArea(t *testing.T) {
+	bf := DefaultBitmapFont()
+	atd := NewAlignedTextDrawer(bf)
+
+	dev := device.NewSSD1322(256, 64)
+	fb := NewFrameBuffer(dev)
+
+	if err := fb.SetSafeArea(Insets{Left: 20, Right: 60}); err != nil {
+		t.Fatalf("set safe area failed: %v", err)
+	}
+
+	if err := atd.DrawCenteredLine(fb, 10, "H", 0x0F); err != nil {
+		t.Fatalf("centered line failed: %v", err)
+	}
+
+	// Safe area spans x=20..195, midpoint 108; "H" is 6 pixels wide
+	if x := leftmostPixel(fb, 10, 16); x != 105 {
+		t.Errorf("expected text to start at x=105 (safe area center), got %d", x)
+	}
+
+	// Wider text stays centered on the safe-area midpoint
+	fb.Clear(0x00)
+	if err := atd.DrawCenteredLine(fb, 10, "HHH", 0x0F); err != nil {
+		t.Fatalf("centered line failed: %v", err)
+	}
+	if x := leftmostPixel(fb, 10, 16); x != 99 {
+		t.Errorf("expected wider text to start at x=99 (safe area center), got %d", x)
+	}
+
+	// DrawCenteredText anchors on x and ignores the safe area
+	fb.Clear(0x00)
+	if err := atd.DrawCenteredText(fb, 128, 10, "H", 0x0F); err != nil {
+		t.Fatalf("centered text failed: %v", err)
+	}
+	if x := leftmostPixel(fb, 10, 16); x != 125 {
+		t.Errorf("expected text to start at x=125 (anchor), got %d", x)
+	}
+}
+
+// rowExtent returns the first and last rows containing a set pixel, or -1, -1
+func rowExtent(fb *FrameBuffer) (top, bottom int) {
+	top, bottom = -1, -1
+	for y := 0; y < fb.Height(); y++ {
+		for x := 0; x < fb.Width(); x++ {
+			if pixel, _ := fb.GetPixel(x, y); pixel != 0 {
+				if top < 0 {
+					top = y
+				}
+				bottom = y
+				break
+			}
+		}
+	}
+	return top, bottom
+}
+
+func TestDrawAlignedTextBox(t *testing.T) {
+	atd := NewAlignedTextDrawer(DefaultBitmapFont())
+
+	tests := []struct {
+		valign VerticalAlignment
+		top    int
+	}{
+		{AlignTop, 10},
+		{AlignMiddle, 21}, // 10 + (30-7)/2
+		{AlignBottom, 33}, // 10 + 30 - 7
+	}
+
+	for _, test := range tests {
+		fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+		if err := atd.DrawAlignedTextBox(fb, 0, 10, 60, 30, "H", AlignCenter, test.valign, 0x0F); err != nil {
+			t.Fatalf("draw text box failed: %v", err)
+		}
+		if top, _ := rowExtent(fb); top != test.top {
+			t.Errorf("valign %d: expected text to start at y=%d, got %d", test.valign, test.top, top)
+		}
+		if x := leftmostPixel(fb, 0, 63); x != 27 {
+			t.Errorf("valign %d: expected centered text at x=27, got %d", test.valign, x)
+		}
+	}
+
+	// Five 7-pixel lines overflow a 30-pixel box: top-align and drop what does not fit
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	atd.DrawAlignedTextBox(fb, 0, 10, 60, 30, "H\nH\nH\nH\nH", AlignLeft, AlignBottom, 0x0F)
+	top, bottom := rowExtent(fb)
+	if top != 10 || bottom >= 40 {
+		t.Errorf("expected overflowing text clipped to rows 10-39, got %d-%d", top, bottom)
+	}
+}
+
+func TestTrueTypeFont(t *testing.T) {
+	ttf := NewTrueTypeFont(24)
+
+	if ttf.Height() != 24 {
+		t.Errorf("expected height 24, got %d", ttf.Height())
+	}
+
+	width, height, err := ttf.MeasureString("Hello")
+	if err != nil {
+		t.Fatalf("measure failed: %v", err)
+	}
+	if height != 24 {
+		t.Errorf("expected measured height 24, got %d", height)
+	}
+	if bitmapWidth, _, _ := DefaultBitmapFont().MeasureString("Hello"); width <= bitmapWidth {
+		t.Errorf("expected 24px text wider than the 5x7 font, got %d", width)
+	}
+
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	drawn, err := ttf.DrawString(fb, 0, 0, "Hello", 0x0F)
+	if err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	if drawn != width {
+		t.Errorf("expected drawn width %d to match measured width %d", drawn, width)
+	}
+	if countSetPixels(fb) == 0 {
+		t.Error("expected glyph pixels to be drawn")
+	}
+
+	glyph, err := ttf.GetGlyph('H')
+	if err != nil {
+		t.Fatalf("GetGlyph failed: %v", err)
+	}
+	if glyph.Height <= 7 {
+		t.Errorf("expected 'H' taller than 7 pixels, got %d", glyph.Height)
+	}
+}
+
+func TestTrueTypeFontFromBytesInvalid(t *testing.T) {
+	if _, err := NewTrueTypeFontFromBytes([]byte("not a font"), 16); err == nil {
+		t.Error("expected error for invalid font data")
+	}
+	if _, err := NewTrueTypeFontFromFile("does-not-exist.ttf", 16); err == nil {
+		t.Error("expected error for missing font file")
+	}
+}
+
+func TestMarquee(t *testing.T) {
+	font := DefaultBitmapFont()
+
+	short := NewMarquee(font, "Hi", 10, 10, 60, 7, 20)
+	short.Update(1)
+	if short.Offset() != 0 {
+		t.Errorf("text that fits should stay static, got offset %v", short.Offset())
+	}
+
+	// 20 glyphs * 6 pixels = 120 pixels, scrolling through a 60-pixel box
+	m := NewMarquee(font, "ABCDEFGHIJKLMNOPQRST", 10, 10, 60, 7, 20)
+	m.SetGap(10)
+
+	m.Update(0.5)
+	if m.Offset() != 10 {
+		t.Errorf("expected offset 10, got %v", m.Offset())
+	}
+
+	m.Pause()
+	m.Update(1)
+	if m.Offset() != 10 {
+		t.Errorf("paused marquee should not advance, got %v", m.Offset())
+	}
+	m.Resume()
+
+	// One full cycle is the text width plus the gap
+	m.Update(6)
+	if m.Offset() != 0 {
+		t.Errorf("expected offset to wrap to 0 after a full cycle, got %v", m.Offset())
+	}
+
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	m.Update(1)
+	if err := m.Draw(fb); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+
+	for y := 0; y < fb.Height(); y++ {
+		for x := 0; x < fb.Width(); x++ {
+			pixel, _ := fb.GetPixel(x, y)
+			inside := x >= 10 && x < 70 && y >= 10 && y < 17
+			if pixel != 0 && !inside {
+				t.Fatalf("pixel (%d, %d) drawn outside the marquee box", x, y)
+			}
+		}
+	}
+	if countSetPixels(fb) == 0 {
+		t.Error("expected marquee text to be drawn")
+	}
+}
+
+func TestConsole(t *testing.T) {
+	font := DefaultBitmapFont()
+
+	// 6-pixel advance and 7-pixel height: 5 columns and 2 rows
+	c := NewConsole(font, 32, 15)
+	if c.Columns() != 5 || c.Rows() != 2 {
+		t.Fatalf("expected 5x2 grid, got %dx%d", c.Columns(), c.Rows())
+	}
+
+	c.Print("HELLOWORLD")
+	lines := c.Lines()
+	if lines[0] != "HELLO" || lines[1] != "WORLD" {
+		t.Fatalf("expected wrapped lines, got %q", lines)
+	}
+
+	// A full line followed by a newline does not leave a blank row
+	c.Println("")
+	c.Println("AB")
+	lines = c.Lines()
+	if lines[0] != "AB" || lines[1] != "" {
+		t.Errorf("expected scrolled grid [AB, ''], got %q", lines)
+	}
+
+	c.Print("X\tY")
+	if lines = c.Lines(); lines[1] != "X   Y" {
+		t.Errorf("expected tab stop at column 4, got %q", lines[1])
+	}
+
+	if err := c.SetCursor(5, 0); err == nil {
+		t.Error("expected error for cursor outside the grid")
+	}
+
+	c.Clear()
+	if col, row := c.Cursor(); col != 0 || row != 0 || c.Lines()[0] != "" {
+		t.Errorf("expected empty grid with cursor home, got cursor (%d, %d)", col, row)
+	}
+}
+
+func TestConsoleRender(t *testing.T) {
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+	c := NewConsole(DefaultBitmapFont(), 60, 14)
+	c.SetPosition(10, 20)
+	c.SetCursorVisible(true)
+
+	c.Print("A")
+	if err := c.Render(fb); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if left := leftmostPixel(fb, 20, 27); left != 10 {
+		t.Errorf("expected text starting at x=10, got %d", left)
+	}
+
+	// Underline cursor in the second cell of the first row
+	for x := 16; x < 22; x++ {
+		if pixel, _ := fb.GetPixel(x, 26); pixel != 0x0F {
+			t.Fatalf("expected cursor pixel at (%d, 26)", x)
+		}
+	}
+}
+
+func TestDrawInSafeAreaCentersVertically(t *testing.T) {
+	atd := NewAlignedTextDrawer(DefaultBitmapFont())
+	fb := NewFrameBuffer(device.NewSSD1322(256, 64))
+
+	if err := fb.SetSafeArea(Insets{Left: 20, Top: 20, Right: 60, Bottom: 4}); err != nil {
+		t.Fatalf("set safe area failed: %v", err)
+	}
+	if err := atd.DrawInSafeArea(fb, "H", AlignCenter, AlignMiddle, 0x0F); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+
+	// Safe area spans y=20..59, so the 7 pixel tall glyph sits about y=36..42 rather than the panel middle
+	top, bottom := rowExtent(fb)
+	if mid := (top + bottom) / 2; mid < 38 || mid > 40 {
+		t.Errorf("expected text centered about y=39, got rows %d..%d", top, bottom)
+	}
+	if x := leftmostPixel(fb, 0, 64); x != 105 {
+		t.Errorf("expected text to start at x=105 (safe area center), got %d", x)
+	}
+}
@@ -1,42 +1,208 @@
 package graphics
 
-// Simplified TrueType font support - currently delegates to bitmap fonts
-// Full TrueType rendering can be added later with golang.org/x/image/font
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
 
-// TrueTypeFont is a placeholder for TrueType font support
-// For now, delegates to bitmap font to keep implementation simple
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// TrueTypeFont renders TrueType/OpenType fonts rasterized to a pixel height
+// Glyphs are antialiased into 4-bit levels and cached after their first use
 type TrueTypeFont struct {
-	bitmapFont *BitmapFont
-	height     int
+	face   font.Face
+	height int
+	ascent int
+	glyphs map[rune]*ttfGlyph
+}
+
+// ttfGlyph is a rasterized glyph with 8-bit coverage per pixel
+type ttfGlyph struct {
+	bounds   image.Rectangle // Glyph pixels relative to the pen position on the baseline
+	coverage []byte          // Row-major coverage, 0 (empty) to 255 (solid)
+	advance  int
 }
 
-// NewTrueTypeFont creates a new TrueType font renderer
-// This is a simplified implementation that uses a bitmap font
+// NewTrueTypeFont creates a TrueType font renderer using the built-in Go Regular font
 func NewTrueTypeFont(height int) *TrueTypeFont {
-	bf := DefaultBitmapFont()
+	ttf, err := NewTrueTypeFontFromBytes(goregular.TTF, height)
+	if err != nil {
+		// The embedded font is known to parse; only an invalid height gets here
+		ttf, _ = NewTrueTypeFontFromBytes(goregular.TTF, DefaultBitmapFont().Height())
+	}
 
-	return &TrueTypeFont{
-		bitmapFont: bf,
-		height:     height,
+	return ttf
+}
+
+// NewTrueTypeFontFromFile loads a .ttf or .otf file rasterized to the given pixel height
+func NewTrueTypeFontFromFile(path string, height int) (*TrueTypeFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font file: %w", err)
+	}
+
+	return NewTrueTypeFontFromBytes(data, height)
+}
+
+// NewTrueTypeFontFromBytes parses TrueType/OpenType font data rasterized to the given pixel height
+func NewTrueTypeFontFromBytes(data []byte, height int) (*TrueTypeFont, error) {
+	if height <= 0 {
+		return nil, fmt.Errorf("invalid font height: %d", height)
+	}
+
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+
+	// At 72 DPI one point is one pixel, so the em square is height pixels tall
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+		Size:    float64(height),
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
 	}
+
+	return &TrueTypeFont{
+		face:   face,
+		height: height,
+		ascent: face.Metrics().Ascent.Ceil(),
+		glyphs: make(map[rune]*ttfGlyph),
+	}, nil
 }
 
 // Height returns the font height
 func (ttf *TrueTypeFont) Height() int {
-	return ttf.bitmapFont.Height()
+	return ttf.height
 }
 
-// DrawString draws text at the specified position
+// DrawString draws text with its top-left corner at (x, y)
+// Glyph edges are blended over the existing pixels according to their coverage
 func (ttf *TrueTypeFont) DrawString(fb *FrameBuffer, x, y int, text string, color byte) (int, error) {
-	return ttf.bitmapFont.DrawString(fb, x, y, text, color)
+	color = color & 0x0F
+	baseline := y + ttf.ascent
+	penX := 0
+	prev := rune(-1)
+
+	for _, ch := range text {
+		if prev >= 0 {
+			penX += ttf.face.Kern(prev, ch).Round()
+		}
+		prev = ch
+
+		glyph := ttf.glyph(ch)
+		if glyph == nil {
+			continue
+		}
+
+		ttf.drawGlyph(fb, x+penX, baseline, glyph, color)
+		penX += glyph.advance
+	}
+
+	return penX, nil
 }
 
-// MeasureString returns the width and height of text
+// MeasureString returns the width and height of text including kerning
 func (ttf *TrueTypeFont) MeasureString(text string) (width, height int, err error) {
-	return ttf.bitmapFont.MeasureString(text)
+	prev := rune(-1)
+
+	for _, ch := range text {
+		if prev >= 0 {
+			width += ttf.face.Kern(prev, ch).Round()
+		}
+		prev = ch
+
+		if glyph := ttf.glyph(ch); glyph != nil {
+			width += glyph.advance
+		}
+	}
+
+	return width, ttf.height, nil
 }
 
-// GetGlyph returns glyph data for a character
+// GetGlyph returns glyph data for a character, thresholded to 1 bit per pixel
 func (ttf *TrueTypeFont) GetGlyph(ch rune) (GlyphData, error) {
-	return ttf.bitmapFont.GetGlyph(ch)
+	glyph := ttf.glyph(ch)
+	if glyph == nil {
+		return GlyphData{}, fmt.Errorf("glyph not found: %c", ch)
+	}
+
+	width := glyph.bounds.Dx()
+	height := glyph.bounds.Dy()
+	bytesPerRow := (width + 7) / 8
+	data := make([]byte, bytesPerRow*height)
+
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			if glyph.coverage[row*width+col] >= 0x80 {
+				data[row*bytesPerRow+col/8] |= 0x80 >> (col % 8)
+			}
+		}
+	}
+
+	return GlyphData{
+		Width:    width,
+		Height:   height,
+		AdvanceX: glyph.advance,
+		BearingX: glyph.bounds.Min.X,
+		BearingY: ttf.ascent + glyph.bounds.Min.Y,
+		Data:     data,
+	}, nil
+}
+
+// glyph returns the cached rasterization of ch, rendering it on first use
+// Returns nil when the font has no glyph for ch
+func (ttf *TrueTypeFont) glyph(ch rune) *ttfGlyph {
+	if glyph, ok := ttf.glyphs[ch]; ok {
+		return glyph
+	}
+
+	dr, mask, maskp, advance, ok := ttf.face.Glyph(fixed.P(0, 0), ch)
+	if !ok {
+		ttf.glyphs[ch] = nil
+		return nil
+	}
+
+	glyph := &ttfGlyph{
+		bounds:   dr,
+		coverage: make([]byte, dr.Dx()*dr.Dy()),
+		advance:  advance.Round(),
+	}
+
+	for row := 0; row < dr.Dy(); row++ {
+		for col := 0; col < dr.Dx(); col++ {
+			alpha := color.AlphaModel.Convert(mask.At(maskp.X+col, maskp.Y+row)).(color.Alpha)
+			glyph.coverage[row*dr.Dx()+col] = alpha.A
+		}
+	}
+
+	ttf.glyphs[ch] = glyph
+	return glyph
+}
+
+// drawGlyph blends a rasterized glyph with its pen position at (x, baseline)
+func (ttf *TrueTypeFont) drawGlyph(fb *FrameBuffer, x, baseline int, glyph *ttfGlyph, color byte) {
+	width := glyph.bounds.Dx()
+
+	for row := 0; row < glyph.bounds.Dy(); row++ {
+		for col := 0; col < width; col++ {
+			coverage := glyph.coverage[row*width+col]
+			if coverage == 0 {
+				continue
+			}
+
+			px := x + glyph.bounds.Min.X + col
+			py := baseline + glyph.bounds.Min.Y + row
+
+			// Out of bounds pixels are clipped
+			fb.BlendPixel(px, py, color, float64(coverage)/255)
+		}
+	}
 }
@@ -2,6 +2,9 @@ package protocol
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/flavioheleno/oled-emulator/device"
 )
 
 // CommandInfo holds information about a command
@@ -13,50 +16,58 @@ type CommandInfo struct {
 }
 
 // SSD1322Commands defines all SSD1322 commands
+// Data byte counts come from device.SSD1322DataBytes, so the device and protocol agree
 var SSD1322Commands = map[byte]CommandInfo{
 	// Fundamental Commands
-	0x15: {Code: 0x15, Name: "SetColumnAddress", Description: "Set column address", DataBytes: 2},
-	0x75: {Code: 0x75, Name: "SetRowAddress", Description: "Set row address", DataBytes: 2},
-	0x5C: {Code: 0x5C, Name: "WriteRAM", Description: "Write RAM", DataBytes: 0},
-	0x5D: {Code: 0x5D, Name: "ReadRAM", Description: "Read RAM", DataBytes: 0},
+	0x15: ssd1322Command(0x15, "SetColumnAddress", "Set column address"),
+	0x75: ssd1322Command(0x75, "SetRowAddress", "Set row address"),
+	0x5C: ssd1322Command(0x5C, "WriteRAM", "Write RAM"),
+	0x5D: ssd1322Command(0x5D, "ReadRAM", "Read RAM"),
 
 	// Fundamental Commands - Contrast
-	0xC1: {Code: 0xC1, Name: "SetContrast", Description: "Set contrast", DataBytes: 1},
-	0xC7: {Code: 0xC7, Name: "MasterCurrentControl", Description: "Master current control", DataBytes: 1},
+	0xC1: ssd1322Command(0xC1, "SetContrast", "Set contrast"),
+	0xC7: ssd1322Command(0xC7, "MasterCurrentControl", "Master current control"),
 
 	// Display Setup
-	0xA0: {Code: 0xA0, Name: "SetRemap", Description: "Set remap and dual COM mode", DataBytes: 1},
-	0xA1: {Code: 0xA1, Name: "SetStartLine", Description: "Set display start line", DataBytes: 1},
-	0xA2: {Code: 0xA2, Name: "DisplayOffset", Description: "Set display offset", DataBytes: 1},
-	0xA4: {Code: 0xA4, Name: "DisplayMode", Description: "Set display mode", DataBytes: 0},
-	0xA5: {Code: 0xA5, Name: "EntireDisplayON", Description: "Entire display ON", DataBytes: 0},
-	0xA6: {Code: 0xA6, Name: "NormalDisplay", Description: "Normal display", DataBytes: 1},
-	0xA7: {Code: 0xA7, Name: "InverseDisplay", Description: "Inverse display", DataBytes: 0},
-	0xAE: {Code: 0xAE, Name: "SleepMode", Description: "Sleep mode (display OFF)", DataBytes: 0},
-	0xAF: {Code: 0xAF, Name: "NormalMode", Description: "Normal mode (display ON)", DataBytes: 0},
+	0xA0: ssd1322Command(0xA0, "SetRemap", "Set remap and dual COM mode"),
+	0xA1: ssd1322Command(0xA1, "SetStartLine", "Set display start line"),
+	0xA2: ssd1322Command(0xA2, "DisplayOffset", "Set display offset"),
+	0xA4: ssd1322Command(0xA4, "DisplayMode", "Set display mode"),
+	0xA5: ssd1322Command(0xA5, "EntireDisplayON", "Entire display ON"),
+	0xA6: ssd1322Command(0xA6, "NormalDisplay", "Normal display"),
+	0xA7: ssd1322Command(0xA7, "InverseDisplay", "Inverse display"),
+	0xAE: ssd1322Command(0xAE, "SleepMode", "Sleep mode (display OFF)"),
+	0xAF: ssd1322Command(0xAF, "NormalMode", "Normal mode (display ON)"),
 
 	// MUX Ratio & Timing
-	0xCA: {Code: 0xCA, Name: "SetMultiplexRatio", Description: "Set MUX ratio", DataBytes: 1},
-	0xB3: {Code: 0xB3, Name: "SetClockDivider", Description: "Set clock divider ratio", DataBytes: 1},
-	0xB1: {Code: 0xB1, Name: "SetPhaseLength", Description: "Set phase length", DataBytes: 1},
-	0xBB: {Code: 0xBB, Name: "SetPrecharge", Description: "Set second precharge period", DataBytes: 1},
-	0xBE: {Code: 0xBE, Name: "SetVCOMH", Description: "Set V_COMH deselect level", DataBytes: 1},
+	0xCA: ssd1322Command(0xCA, "SetMultiplexRatio", "Set MUX ratio"),
+	0xB3: ssd1322Command(0xB3, "SetClockDivider", "Set clock divider ratio"),
+	0xB1: ssd1322Command(0xB1, "SetPhaseLength", "Set phase length"),
+	0xBB: ssd1322Command(0xBB, "SetPrecharge", "Set second precharge period"),
+	0xBE: ssd1322Command(0xBE, "SetVCOMH", "Set V_COMH deselect level"),
 
 	// Display Enhancement
-	0xB4: {Code: 0xB4, Name: "DisplayEnhance", Description: "Display enhancement", DataBytes: 1},
-	0xD1: {Code: 0xD1, Name: "DisplayEnhanceB", Description: "Display enhancement B", DataBytes: 1},
+	0xB4: ssd1322Command(0xB4, "DisplayEnhance", "Display enhancement"),
+	0xD1: ssd1322Command(0xD1, "DisplayEnhanceB", "Display enhancement B"),
 
 	// Scrolling
-	0x26: {Code: 0x26, Name: "HorizontalScroll", Description: "Horizontal scroll setup", DataBytes: 5},
-	0x27: {Code: 0x27, Name: "ContinuousScroll", Description: "Horizontal scroll setup (continuous)", DataBytes: 5},
-	0x2E: {Code: 0x2E, Name: "DeactivateScroll", Description: "Deactivate scroll", DataBytes: 0},
-	0x2F: {Code: 0x2F, Name: "ActivateScroll", Description: "Activate scroll", DataBytes: 0},
+	0x26: ssd1322Command(0x26, "HorizontalScroll", "Horizontal scroll setup"),
+	0x27: ssd1322Command(0x27, "ContinuousScroll", "Horizontal scroll setup (continuous)"),
+	0x2E: ssd1322Command(0x2E, "DeactivateScroll", "Deactivate scroll"),
+	0x2F: ssd1322Command(0x2F, "ActivateScroll", "Activate scroll"),
 
 	// Grayscale
-	0xB9: {Code: 0xB9, Name: "GrayscaleTable", Description: "Set default grayscale table", DataBytes: 1},
+	0xB8: ssd1322Command(0xB8, "SetGrayscaleTable", "Load custom grayscale table"),
+	0xB9: ssd1322Command(0xB9, "GrayscaleTable", "Set default grayscale table"),
 
 	// Command Lock
-	0xFD: {Code: 0xFD, Name: "CommandLock", Description: "Set command lock", DataBytes: 1},
+	0xFD: ssd1322Command(0xFD, "CommandLock", "Set command lock"),
+}
+
+// ssd1322Command describes a command, taking its data byte count from the device
+func ssd1322Command(code byte, name, description string) CommandInfo {
+	count, _ := device.SSD1322DataBytes(code)
+	return CommandInfo{Code: code, Name: name, Description: description, DataBytes: count}
 }
 
 // GetCommandInfo returns information about a command
@@ -68,6 +79,32 @@ func GetCommandInfo(code byte) (CommandInfo, error) {
 	return info, nil
 }
 
+// FormatCommand renders a command and its data for logs, e.g. "SetContrast (0xC1) [0x7F]"
+// Unknown commands are shown as "Unknown (0xNN)"
+func FormatCommand(code byte, data []byte) string {
+	name := "Unknown"
+	if info, ok := SSD1322Commands[code]; ok {
+		name = info.Name
+	}
+
+	text := fmt.Sprintf("%s (0x%02X)", name, code)
+	if len(data) > 0 {
+		text += " " + formatBytes(data)
+	}
+
+	return text
+}
+
+// formatBytes renders data as "[0x01 0x02]"
+func formatBytes(data []byte) string {
+	values := make([]string, len(data))
+	for i, b := range data {
+		values[i] = fmt.Sprintf("0x%02X", b)
+	}
+
+	return "[" + strings.Join(values, " ") + "]"
+}
+
 // CommandBuilder helps construct SPI command sequences
 type CommandBuilder struct {
 	bytes []byte
@@ -112,61 +149,9 @@ func (cb *CommandBuilder) Reset() *CommandBuilder {
 }
 
 // SSD1322InitSequence generates a typical initialization sequence for SSD1322
+// It is SSD1322InitSteps flattened to bytes, without the delays
 func SSD1322InitSequence() []byte {
-	builder := NewCommandBuilder()
-
-	// Command unlock
-	builder.AddCommand(0xFD).AddData(0xB1)
-
-	// Display OFF
-	builder.AddCommand(0xAE)
-
-	// Set clock divider
-	builder.AddCommand(0xB3).AddData(0x00)
-
-	// Set MUX ratio
-	builder.AddCommand(0xCA).AddData(0x3F)
-
-	// Set display offset
-	builder.AddCommand(0xA2).AddData(0x00)
-
-	// Set display start line
-	builder.AddCommand(0xA1).AddData(0x00)
-
-	// Set remap
-	builder.AddCommand(0xA0).AddData(0x14)
-
-	// Set phase length
-	builder.AddCommand(0xB1).AddData(0x74)
-
-	// Display enhancement
-	builder.AddCommand(0xB4).AddData(0x00)
-
-	// Set contrast
-	builder.AddCommand(0xC1).AddData(0x7F)
-
-	// Master current
-	builder.AddCommand(0xC7).AddData(0x0F)
-
-	// Set precharge
-	builder.AddCommand(0xBB).AddData(0x3C)
-
-	// Set VCOMH
-	builder.AddCommand(0xBE).AddData(0x07)
-
-	// Normal display
-	builder.AddCommand(0xA6)
-
-	// Column addressing
-	builder.AddCommand(0x15).AddData(0x1C).AddData(0x5B)
-
-	// Row addressing
-	builder.AddCommand(0x75).AddData(0x00).AddData(0x3F)
-
-	// Display ON
-	builder.AddCommand(0xAF)
-
-	return builder.Build()
+	return InitStepBytes(SSD1322InitSteps())
 }
 
 // DrawPixelCommand creates a command sequence to draw a pixel
@@ -188,22 +173,58 @@ func DrawPixelCommand(x, y, color byte) []byte {
 	return builder.Build()
 }
 
-// FillScreenCommand creates a command sequence to fill the entire screen
+// SSD1322 RAM addressing: on a 256-pixel panel display x=0 is internal column 0x1C
+// and each column address covers 4 pixels in 2 data bytes
+const (
+	ssd1322FirstColumn  = 0x1C
+	ssd1322ColumnPixels = 4
+)
+
+// columnOffsetter is implemented by devices that report the internal column of display x=0 (e.g. BaseDevice)
+type columnOffsetter interface {
+	ColumnOffset() int
+}
+
+// firstColumn returns the column address of display x=0 on dev, the SSD1322 default when it doesn't say
+func firstColumn(dev device.Device) int {
+	if offsetter, ok := dev.(columnOffsetter); ok {
+		return offsetter.ColumnOffset()
+	}
+	return ssd1322FirstColumn
+}
+
+// FillScreenCommand creates a command sequence to fill an entire 256x64 screen
 func FillScreenCommand(color byte) []byte {
+	return fillScreenCommand(ssd1322FirstColumn, 256, 64, color)
+}
+
+// FillScreenCommandFor creates a command sequence to fill the entire screen of dev
+// The sequence uses SSD1322 addressing, so dev must be nibble-packed; the window starts at its column offset
+func FillScreenCommandFor(dev device.Device, color byte) ([]byte, error) {
+	if format := dev.PixelFormat(); format != device.HorizontalNibble {
+		return nil, fmt.Errorf("fill commands need a nibble-packed SSD1322-style device, got pixel format %d", format)
+	}
+
+	return fillScreenCommand(firstColumn(dev), dev.Width(), dev.Height(), color), nil
+}
+
+// fillScreenCommand sets a window of width x height pixels from firstColumn and writes every byte in it
+func fillScreenCommand(firstColumn, width, height int, color byte) []byte {
 	builder := NewCommandBuilder()
+	columns := (width + ssd1322ColumnPixels - 1) / ssd1322ColumnPixels
 
 	// Set column address
-	builder.AddCommand(0x15).AddData(0x1C).AddData(0x5B)
+	builder.AddCommand(0x15).AddData(byte(firstColumn)).AddData(byte(firstColumn + columns - 1))
 
 	// Set row address
-	builder.AddCommand(0x75).AddData(0x00).AddData(0x3F)
+	builder.AddCommand(0x75).AddData(0x00).AddData(byte(height - 1))
 
 	// Write RAM
 	builder.AddCommand(0x5C)
 
-	// Add color data for entire screen
-	// 256x64 display = 480 columns (internal) x 64 rows = 15360 bytes
-	for i := 0; i < 7680; i++ {
+	// Two pixels per byte, two bytes per column: 256x64 takes 8192 bytes
+	color &= 0x0F
+	for i := 0; i < columns*2*height; i++ {
 		builder.AddData((color << 4) | color)
 	}
 
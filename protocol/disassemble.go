@@ -0,0 +1,73 @@
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/flavioheleno/oled-emulator/device"
+)
+
+// DecodedCommand is a command and its data found in a raw byte stream
+type DecodedCommand struct {
+	Offset    int    // Position of the command byte in the stream
+	Code      byte   // Command byte
+	Name      string // Command name, "Unknown" for bytes not in SSD1322Commands
+	Data      []byte // Data bytes grouped with the command
+	Known     bool   // false for unknown command bytes
+	Truncated bool   // true when the stream ended before all data bytes
+	Trailing  bool   // true when Data is RAM data running to the end of the stream
+}
+
+// String formats the command as "0xC1 SetContrast [0x7F]"
+func (dc DecodedCommand) String() string {
+	text := fmt.Sprintf("0x%02X %s", dc.Code, dc.Name)
+
+	switch {
+	case dc.Trailing:
+		text += fmt.Sprintf(" +%d data bytes", len(dc.Data))
+	case len(dc.Data) > 0:
+		text += " " + formatBytes(dc.Data)
+	}
+
+	if dc.Truncated {
+		text += " (truncated)"
+	}
+
+	return text
+}
+
+// Disassemble splits a command byte stream into commands with their data, using the
+// data byte counts from SSD1322Commands
+// A raw stream has no DC line, so everything after WriteRAM is taken as trailing RAM data;
+// unknown bytes are reported as single commands and decoding resumes at the next byte
+func Disassemble(stream []byte) []DecodedCommand {
+	result := make([]DecodedCommand, 0)
+
+	for i := 0; i < len(stream); {
+		cmd := DecodedCommand{Offset: i, Code: stream[i], Name: "Unknown"}
+
+		info, ok := SSD1322Commands[cmd.Code]
+		if !ok {
+			result = append(result, cmd)
+			i++
+			continue
+		}
+		cmd.Name = info.Name
+		cmd.Known = true
+
+		end := i + 1 + info.DataBytes
+		if cmd.Code == device.CmdWriteRAM {
+			end = len(stream)
+			cmd.Trailing = end > i+1
+		}
+		if end > len(stream) {
+			end = len(stream)
+			cmd.Truncated = true
+		}
+
+		cmd.Data = append([]byte(nil), stream[i+1:end]...)
+		result = append(result, cmd)
+		i = end
+	}
+
+	return result
+}
@@ -0,0 +1,145 @@
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/flavioheleno/oled-emulator/device"
+)
+
+// I2C control bytes sent after the slave address
+const (
+	I2CControlCommand = 0x00 // Following bytes are commands (and their parameters)
+	I2CControlData    = 0x40 // Following bytes are display RAM data
+)
+
+// dataWriter is implemented by devices that accept display RAM data (e.g. SSD1322.WriteData)
+type dataWriter interface {
+	WriteData(data []byte) error
+}
+
+// dataReader is implemented by devices that support display RAM read-back (e.g. SSD1322.ReadData)
+type dataReader interface {
+	ReadData(length int) ([]byte, error)
+}
+
+// I2CBridge emulates I2C communication with the display device
+type I2CBridge struct {
+	device      device.Device
+	address     byte
+	lastAddress byte
+	lastControl byte
+	commandCode byte
+}
+
+// NewI2CBridge creates a new I2C bridge for a device at the given 7-bit slave address
+// Addresses above 0x7F are rejected, like in Write
+func NewI2CBridge(dev device.Device, address byte) (*I2CBridge, error) {
+	if address > 0x7F {
+		return nil, fmt.Errorf("7-bit address expected, got 0x%02X", address)
+	}
+
+	return &I2CBridge{
+		device:  dev,
+		address: address,
+	}, nil
+}
+
+// Address returns the 7-bit slave address of the device
+func (ib *I2CBridge) Address() byte {
+	return ib.address
+}
+
+// Write sends an I2C transaction to the given 7-bit slave address
+// The first byte is the control byte selecting command or data mode
+func (ib *I2CBridge) Write(addr byte, data []byte) error {
+	ib.lastAddress = addr
+
+	if addr > 0x7F {
+		return fmt.Errorf("7-bit address expected, got 0x%02X", addr)
+	}
+
+	if addr != ib.address {
+		return fmt.Errorf("no device at address 0x%02X", addr)
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	control := data[0]
+	ib.lastControl = control
+	payload := data[1:]
+
+	switch control {
+	case I2CControlCommand:
+		return ib.writeCommands(payload)
+	case I2CControlData:
+		return ib.writeData(payload)
+	default:
+		return fmt.Errorf("invalid control byte: 0x%02X", control)
+	}
+}
+
+// writeCommands splits a command stream into commands and their parameters
+func (ib *I2CBridge) writeCommands(data []byte) error {
+	for i := 0; i < len(data); {
+		cmd := data[i]
+		i++
+
+		dataBytes := 0
+		if info, err := GetCommandInfo(cmd); err == nil {
+			dataBytes = info.DataBytes
+		}
+
+		end := i + dataBytes
+		if end > len(data) {
+			end = len(data)
+		}
+
+		if err := ib.device.ProcessCommand(cmd, data[i:end]); err != nil {
+			return fmt.Errorf("command error: %w", err)
+		}
+		ib.commandCode = cmd
+		i = end
+	}
+
+	return nil
+}
+
+// writeData forwards display RAM data to the device
+func (ib *I2CBridge) writeData(data []byte) error {
+	writer, ok := ib.device.(dataWriter)
+	if !ok {
+		return fmt.Errorf("device does not support RAM data writes")
+	}
+
+	return writer.WriteData(data)
+}
+
+// Reset performs a hardware reset sequence
+func (ib *I2CBridge) Reset() error {
+	return ib.device.Reset()
+}
+
+// GetDevice returns the underlying device
+func (ib *I2CBridge) GetDevice() device.Device {
+	return ib.device
+}
+
+// GetStatus returns the current bridge status
+func (ib *I2CBridge) GetStatus() I2CStatus {
+	return I2CStatus{
+		Address:     ib.address,
+		LastAddress: ib.lastAddress,
+		LastControl: ib.lastControl,
+		LastCommand: ib.commandCode,
+	}
+}
+
+// I2CStatus holds the current status of the I2C bridge
+type I2CStatus struct {
+	Address     byte
+	LastAddress byte
+	LastControl byte
+	LastCommand byte
+}
@@ -0,0 +1,90 @@
+package protocol
+
+// InitStep is a single step of a structured initialization sequence
+// A step either sends a command with its data bytes or, when Delay is set, only waits
+type InitStep struct {
+	Command byte
+	Data    []byte
+	DelayMs int  // Milliseconds to wait after the step
+	Delay   bool // true for steps that only wait
+}
+
+// InitSequenceBuilder builds initialization sequences the way datasheets list them
+type InitSequenceBuilder struct {
+	steps []InitStep
+}
+
+// NewInitSequenceBuilder creates a new init sequence builder
+func NewInitSequenceBuilder() *InitSequenceBuilder {
+	return &InitSequenceBuilder{
+		steps: make([]InitStep, 0),
+	}
+}
+
+// Command adds a command with its data bytes
+func (isb *InitSequenceBuilder) Command(code byte, data ...byte) *InitSequenceBuilder {
+	step := InitStep{Command: code, Data: make([]byte, len(data))}
+	copy(step.Data, data)
+	isb.steps = append(isb.steps, step)
+	return isb
+}
+
+// Delay adds a wait of ms milliseconds
+func (isb *InitSequenceBuilder) Delay(ms int) *InitSequenceBuilder {
+	isb.steps = append(isb.steps, InitStep{DelayMs: ms, Delay: true})
+	return isb
+}
+
+// Build returns the steps
+func (isb *InitSequenceBuilder) Build() []InitStep {
+	result := make([]InitStep, len(isb.steps))
+	copy(result, isb.steps)
+	return result
+}
+
+// Bytes returns the commands and data as a flat byte sequence, dropping delays
+func (isb *InitSequenceBuilder) Bytes() []byte {
+	return InitStepBytes(isb.steps)
+}
+
+// InitStepBytes flattens steps into a command byte sequence, dropping delays
+func InitStepBytes(steps []InitStep) []byte {
+	builder := NewCommandBuilder()
+	for _, step := range steps {
+		if step.Delay {
+			continue
+		}
+
+		builder.AddCommand(step.Command).AddBytes(step.Data...)
+	}
+
+	return builder.Build()
+}
+
+// SSD1322InitSteps generates a typical initialization sequence for SSD1322 as structured steps
+// It sends the same commands as SSD1322InitSequence, with the waits from the datasheet
+func SSD1322InitSteps() []InitStep {
+	return NewInitSequenceBuilder().
+		// Wait for the supply to settle after reset
+		Delay(100).
+		Command(0xFD, 0xB1).
+		Command(0xAE).
+		Command(0xB3, 0x00).
+		Command(0xCA, 0x3F).
+		Command(0xA2, 0x00).
+		Command(0xA1, 0x00).
+		Command(0xA0, 0x14).
+		Command(0xB1, 0x74).
+		Command(0xB4, 0x00).
+		Command(0xC1, 0x7F).
+		Command(0xC7, 0x0F).
+		Command(0xBB, 0x3C).
+		Command(0xBE, 0x07).
+		Command(0xA6).
+		Command(0x15, 0x1C, 0x5B).
+		Command(0x75, 0x00, 0x3F).
+		Command(0xAF).
+		// Let the panel power up before drawing
+		Delay(100).
+		Build()
+}
@@ -1,9 +1,13 @@
 package protocol
 
 import (
+	"bytes"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/flavioheleno/oled-emulator/device"
+	"github.com/flavioheleno/oled-emulator/graphics"
 )
 
 func TestSPIBridgeBasic(t *testing.T) {
@@ -54,6 +58,19 @@ func TestGetCommandInfo(t *testing.T) {
 	}
 }
 
+func TestCommandDataBytesMatchDevice(t *testing.T) {
+	for code, info := range SSD1322Commands {
+		count, ok := device.SSD1322DataBytes(code)
+		if !ok {
+			t.Errorf("command 0x%02X missing from device data byte table", code)
+			continue
+		}
+		if count != info.DataBytes {
+			t.Errorf("command 0x%02X: protocol expects %d data bytes, device %d", code, info.DataBytes, count)
+		}
+	}
+}
+
 func TestUnknownCommand(t *testing.T) {
 	_, err := GetCommandInfo(0xFF)
 	if err == nil {
@@ -147,3 +164,712 @@ func TestSPIBridgeStatus(t *testing.T) {
 		t.Error("should be in command mode")
 	}
 }
+
+func TestI2CBridgeRouting(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	bridge, err := NewI2CBridge(dev, 0x3C)
+	if err != nil {
+		t.Fatalf("failed to create bridge: %v", err)
+	}
+
+	// Command stream: unlock, set contrast
+	if err := bridge.Write(0x3C, []byte{I2CControlCommand, 0xFD, 0xB1, 0xC1, 0x80}); err != nil {
+		t.Fatalf("command write failed: %v", err)
+	}
+
+	if dev.GetContrastLevel() != 0x80 {
+		t.Errorf("expected contrast 0x80, got 0x%02X", dev.GetContrastLevel())
+	}
+
+	status := bridge.GetStatus()
+	if status.LastAddress != 0x3C || status.LastControl != I2CControlCommand || status.LastCommand != 0xC1 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	// Set address window, write RAM, then send a data byte
+	if err := bridge.Write(0x3C, []byte{I2CControlCommand, 0x15, 0x1C, 0x1D, 0x75, 0x00, 0x00, 0x5C}); err != nil {
+		t.Fatalf("command write failed: %v", err)
+	}
+	if err := bridge.Write(0x3C, []byte{I2CControlData, 0xFF}); err != nil {
+		t.Fatalf("data write failed: %v", err)
+	}
+
+	if pixel, _ := dev.GetPixel(0, 0); pixel != 0x0F {
+		t.Errorf("expected pixel 0x0F after data write, got 0x%02X", pixel)
+	}
+
+	if bridge.GetStatus().LastControl != I2CControlData {
+		t.Error("status should report data control byte")
+	}
+}
+
+func TestI2CBridgeWrongAddress(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	bridge, err := NewI2CBridge(dev, 0x3C)
+	if err != nil {
+		t.Fatalf("failed to create bridge: %v", err)
+	}
+
+	if err := bridge.Write(0x3D, []byte{I2CControlCommand, 0xAF}); err == nil {
+		t.Error("should return error for mismatched address")
+	}
+
+	if dev.IsDisplayOn() {
+		t.Error("command for another address should not reach the device")
+	}
+
+	if bridge.GetStatus().LastAddress != 0x3D {
+		t.Error("status should record the last address")
+	}
+
+	// Addresses above 0x7F are rejected, even when their low 7 bits match
+	if err := bridge.Write(0xBC, []byte{I2CControlCommand, 0xAF}); err == nil || !strings.Contains(err.Error(), "7-bit address expected") {
+		t.Errorf("expected 7-bit address error for 0xBC, got %v", err)
+	}
+
+	// The constructor rejects the same addresses
+	if _, err := NewI2CBridge(dev, 0xF0); err == nil || !strings.Contains(err.Error(), "7-bit address expected") {
+		t.Errorf("expected 7-bit address error for 0xF0, got %v", err)
+	}
+
+	// The 8-bit write address 0x78 of 0x3C is a different 7-bit address
+	if err := bridge.Write(0x78, []byte{I2CControlCommand, 0xAF}); err == nil {
+		t.Error("should return error for the 8-bit write address")
+	}
+
+	if dev.IsDisplayOn() {
+		t.Error("commands for other addresses should not reach the device")
+	}
+}
+
+func TestSPIBridgeWriteRAM(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	bridge := NewSPIBridge(dev)
+
+	if err := bridge.SendInitSequence(SSD1322InitSequence()); err != nil {
+		t.Fatalf("init sequence failed: %v", err)
+	}
+
+	send := func(dc bool, data ...byte) {
+		bridge.SetDC(dc)
+		if err := bridge.Write(data); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	// Column and row window, parameters sent with DC high
+	// Column 0x1C is the first 4 pixels of the display
+	send(false, 0x15)
+	send(true, 0x1C, 0x1C)
+	send(false, 0x75)
+	send(true, 0x00, 0x00)
+
+	// Write RAM followed by pixel data, two bytes per column
+	send(false, 0x5C)
+	send(true, 0xAA, 0xAA)
+
+	for x := 0; x < 4; x++ {
+		pixel, err := dev.GetPixel(x, 0)
+		if err != nil {
+			t.Fatalf("get pixel failed: %v", err)
+		}
+		if pixel != 0x0A {
+			t.Errorf("expected pixel (%d, 0) to be 0x0A, got 0x%02X", x, pixel)
+		}
+	}
+
+	if pixel, _ := dev.GetPixel(0, 1); pixel != 0 {
+		t.Errorf("row outside the window should be untouched, got 0x%02X", pixel)
+	}
+}
+
+func TestSPIBridgeCommandThenData(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	bridge := NewSPIBridge(dev)
+
+	bridge.SetDC(false)
+	if err := bridge.Write([]byte{device.CmdSetContrast}); err != nil {
+		t.Fatalf("command write failed: %v", err)
+	}
+	bridge.SetDC(true)
+	if err := bridge.Write([]byte{0x42}); err != nil {
+		t.Fatalf("data write failed: %v", err)
+	}
+
+	// The next command completes SetContrast
+	bridge.SetDC(false)
+	if err := bridge.Write([]byte{device.CmdNormalDisplay}); err != nil {
+		t.Fatalf("command write failed: %v", err)
+	}
+
+	if dev.GetContrastLevel() != 0x42 {
+		t.Errorf("expected contrast 0x42, got 0x%02X", dev.GetContrastLevel())
+	}
+	if !dev.IsDisplayOn() {
+		t.Error("command without data should run immediately")
+	}
+}
+
+func TestSPIBridgeSplitData(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	dev.SetCommandHistorySize(4)
+	bridge := NewSPIBridge(dev)
+
+	// Column window parameters sent in separate writes
+	bridge.SetDC(false)
+	bridge.Write([]byte{device.CmdSetColumnAddress})
+	bridge.SetDC(true)
+	bridge.Write([]byte{0x1C})
+	bridge.Write([]byte{0x5B})
+
+	if len(dev.GetCommandHistory()) != 0 {
+		t.Error("command should not run before it is complete")
+	}
+
+	if err := bridge.SetCS(true); err != nil {
+		t.Fatalf("deselect failed: %v", err)
+	}
+
+	history := dev.GetCommandHistory()
+	if len(history) != 1 || history[0].Code != device.CmdSetColumnAddress {
+		t.Fatalf("expected a single column address command, got %v", history)
+	}
+	if len(history[0].Data) != 2 || history[0].Data[0] != 0x1C || history[0].Data[1] != 0x5B {
+		t.Errorf("expected data [0x1C 0x5B], got % X", history[0].Data)
+	}
+}
+
+func TestSPIBridgeDataWithoutCommand(t *testing.T) {
+	bridge := NewSPIBridge(device.NewSSD1322(256, 64))
+
+	bridge.SetDC(true)
+	if err := bridge.Write([]byte{0x01}); err == nil {
+		t.Error("expected error for data without a command")
+	}
+}
+
+func TestSPIBridgeInitSequence(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	bridge := NewSPIBridge(dev)
+
+	dev.ProcessCommand(device.CmdSetContrast, []byte{0x10})
+	dev.ProcessCommand(device.CmdInverseDisplay, nil)
+	dev.SetCommandHistorySize(64)
+
+	sequence := SSD1322InitSequence()
+	if err := bridge.SendInitSequence(sequence); err != nil {
+		t.Fatalf("init sequence failed: %v", err)
+	}
+
+	if dev.GetContrastLevel() != 0x7F {
+		t.Errorf("expected contrast 0x7F, got 0x%02X", dev.GetContrastLevel())
+	}
+	if dev.IsInverted() {
+		t.Error("expected normal display after init")
+	}
+	if !dev.IsDisplayOn() {
+		t.Error("expected display on after init")
+	}
+
+	// Unlock, then every command of the sequence with its own data bytes
+	history := dev.GetCommandHistory()
+	if len(history) == 0 || history[0].Code != device.CmdCommandLock {
+		t.Fatalf("expected unlock first, got %v", history)
+	}
+
+	history = history[1:]
+	offset := 0
+	for _, record := range history {
+		info, err := GetCommandInfo(record.Code)
+		if err != nil {
+			t.Fatalf("unexpected command 0x%02X", record.Code)
+		}
+		if record.Code != sequence[offset] || len(record.Data) != info.DataBytes {
+			t.Fatalf("offset %d: got %s with % X", offset, info.Name, record.Data)
+		}
+		for i, b := range record.Data {
+			if b != sequence[offset+1+i] {
+				t.Errorf("offset %d: data byte %d is 0x%02X, expected 0x%02X", offset, i, b, sequence[offset+1+i])
+			}
+		}
+		offset += 1 + info.DataBytes
+	}
+	if offset != len(sequence) {
+		t.Errorf("processed %d of %d sequence bytes", offset, len(sequence))
+	}
+
+	// The multiplex ratio reached the device with its data byte
+	found := false
+	for _, record := range history {
+		if record.Code == device.CmdSetMultiplexRatio && len(record.Data) == 1 && record.Data[0] == 0x3F {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected multiplex ratio command with data 0x3F")
+	}
+}
+
+func TestSPIBridgeInitSequenceTruncated(t *testing.T) {
+	bridge := NewSPIBridge(device.NewSSD1322(256, 64))
+
+	if err := bridge.SendInitSequence([]byte{0x15, 0x1C}); err == nil {
+		t.Error("expected error for missing data bytes")
+	}
+	if err := bridge.SendInitSequence([]byte{0x01}); err == nil {
+		t.Error("expected error for unknown command")
+	}
+}
+
+func TestInitSequenceBuilder(t *testing.T) {
+	builder := NewInitSequenceBuilder().Delay(5).Command(0xC1, 0x80).Command(0xAF)
+	steps := builder.Build()
+
+	if len(steps) != 3 || !steps[0].Delay || steps[0].DelayMs != 5 {
+		t.Fatalf("unexpected steps: %+v", steps)
+	}
+	if steps[1].Command != 0xC1 || len(steps[1].Data) != 1 || steps[1].Data[0] != 0x80 {
+		t.Errorf("unexpected contrast step: %+v", steps[1])
+	}
+
+	bytes := builder.Bytes()
+	expected := []byte{0xC1, 0x80, 0xAF}
+	if len(bytes) != len(expected) {
+		t.Fatalf("expected %d bytes, got % X", len(expected), bytes)
+	}
+	for i, b := range bytes {
+		if b != expected[i] {
+			t.Errorf("byte %d: expected 0x%02X, got 0x%02X", i, expected[i], b)
+		}
+	}
+}
+
+func TestSPIBridgeRunInitSequence(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	bridge := NewSPIBridge(dev)
+
+	var waits []time.Duration
+	bridge.SetSleepFunc(func(d time.Duration) {
+		// Commands before a delay must already have reached the device
+		if len(waits) == 1 && !dev.IsDisplayOn() {
+			t.Error("display should be on before the final delay")
+		}
+		waits = append(waits, d)
+	})
+
+	dev.ProcessCommand(device.CmdSetContrast, []byte{0x10})
+	if err := bridge.RunInitSequence(SSD1322InitSteps()); err != nil {
+		t.Fatalf("init steps failed: %v", err)
+	}
+
+	if len(waits) != 2 || waits[0] != 100*time.Millisecond || waits[1] != 100*time.Millisecond {
+		t.Errorf("expected two 100ms waits, got %v", waits)
+	}
+	if dev.GetContrastLevel() != 0x7F {
+		t.Errorf("expected contrast 0x7F, got 0x%02X", dev.GetContrastLevel())
+	}
+	if !dev.IsDisplayOn() {
+		t.Error("expected display on after init")
+	}
+}
+
+func TestSPIBridgeResetPin(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	bridge := NewSPIBridge(dev)
+
+	clock := time.Unix(0, 0)
+	bridge.SetNowFunc(func() time.Time { return clock })
+
+	dev.SetPixel(3, 3, 0x0F)
+
+	// Too short a pulse is rejected and leaves VRAM alone
+	bridge.SetReset(false)
+	clock = clock.Add(10 * time.Microsecond)
+	if err := bridge.SetReset(true); err == nil {
+		t.Error("expected error for short reset pulse")
+	}
+	if pixel, _ := dev.GetPixel(3, 3); pixel != 0x0F {
+		t.Error("short pulse should not reset the device")
+	}
+
+	bridge.SetReset(false)
+	if bridge.GetStatus().ResetPin {
+		t.Error("status should report reset asserted")
+	}
+
+	// Writes are ignored while reset is held
+	bridge.SetDC(false)
+	bridge.Write([]byte{device.CmdNormalDisplay})
+	if dev.IsDisplayOn() {
+		t.Error("writes should be ignored while reset is asserted")
+	}
+
+	clock = clock.Add(time.Millisecond)
+	if err := bridge.SetReset(true); err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+	if pixel, _ := dev.GetPixel(3, 3); pixel != 0 {
+		t.Errorf("expected VRAM cleared after reset, got 0x%02X", pixel)
+	}
+}
+
+func TestSPIBridgeBusy(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	bridge := NewSPIBridge(dev)
+
+	clock := time.Unix(0, 0)
+	bridge.SetNowFunc(func() time.Time { return clock })
+	var slept time.Duration
+	bridge.SetSleepFunc(func(d time.Duration) {
+		slept += d
+		clock = clock.Add(d)
+	})
+
+	bridge.SetDC(false)
+	bridge.Write([]byte{device.CmdWriteRAM})
+	bridge.Flush()
+	if bridge.IsBusy() {
+		t.Error("bridge should never be busy by default")
+	}
+
+	// The busy period starts once RAM data has been written, not on WriteRAM itself
+	bridge.SetBusyDuration(time.Millisecond)
+	bridge.SetDC(false)
+	bridge.Write([]byte{device.CmdWriteRAM})
+	if bridge.IsBusy() {
+		t.Fatal("expected ready after WriteRAM, before any data")
+	}
+	bridge.SetDC(true)
+	bridge.Write([]byte{0xFF})
+	if pixel, _ := dev.GetPixel(0, 0); pixel != 0x0F {
+		t.Errorf("expected the first burst written at once, got 0x%02X", pixel)
+	}
+	if !bridge.IsBusy() || !bridge.GetStatus().Busy {
+		t.Fatal("expected busy after RAM data")
+	}
+
+	// Data written while busy is queued
+	bridge.Write([]byte{0x11})
+	if bridge.QueuedWrites() != 1 {
+		t.Fatalf("expected 1 queued write, got %d", bridge.QueuedWrites())
+	}
+	if pixel, _ := dev.GetPixel(2, 0); pixel != 0 {
+		t.Error("queued data should not reach the device while busy")
+	}
+
+	// Flush waits until ready and sends the queue
+	if err := bridge.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if pixel, _ := dev.GetPixel(2, 0); pixel != 0x01 || bridge.QueuedWrites() != 0 {
+		t.Errorf("expected queued data written on flush, got 0x%02X with %d queued", pixel, bridge.QueuedWrites())
+	}
+	if slept != time.Millisecond {
+		t.Errorf("expected flush to wait out the busy period, slept %v", slept)
+	}
+
+	// Deselecting the chip sends the queue too
+	bridge.Write([]byte{0x22})
+	bridge.SetCS(true)
+	if pixel, _ := dev.GetPixel(4, 0); pixel != 0x02 || bridge.QueuedWrites() != 0 {
+		t.Errorf("expected queued data written on deselect, got 0x%02X with %d queued", pixel, bridge.QueuedWrites())
+	}
+	bridge.SetCS(false)
+
+	// Reads see queued writes, including the commands that set up the read
+	bridge.Write([]byte{0x33})
+	bridge.SetDC(false)
+	bridge.Write([]byte{device.CmdSetColumnAddress})
+	bridge.SetDC(true)
+	bridge.Write([]byte{0x1D, 0x5B})
+	bridge.SetDC(false)
+	bridge.Write([]byte{device.CmdReadRAM})
+	if bridge.QueuedWrites() == 0 {
+		t.Fatal("expected writes queued while busy")
+	}
+	data, err := bridge.ReadData(3)
+	if err != nil {
+		t.Fatalf("ReadData failed: %v", err)
+	}
+	if data[1] != 0x22 || data[2] != 0x33 {
+		t.Errorf("expected read-back [0x22 0x33] after the queue, got % X", data[1:])
+	}
+
+	bridge.SetBusyMode(BusyReject)
+	bridge.SetDC(false)
+	bridge.Write([]byte{device.CmdWriteRAM})
+	bridge.SetDC(true)
+	if err := bridge.Write([]byte{0xFF}); err != nil {
+		t.Fatalf("expected RAM data after WriteRAM to be accepted in reject mode: %v", err)
+	}
+	if err := bridge.Write([]byte{0xFF}); err == nil {
+		t.Error("expected error while busy in reject mode")
+	}
+
+	clock = clock.Add(time.Millisecond)
+	if err := bridge.WriteFrame(graphics.NewFrameBuffer(device.NewNullDevice(256, 64))); err != nil {
+		t.Errorf("expected WriteFrame to succeed in reject mode: %v", err)
+	}
+}
+
+func TestSPIBridgeReadData(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	bridge := NewSPIBridge(dev)
+
+	dev.SetPixel(0, 0, 0x03)
+	dev.SetPixel(1, 0, 0x0C)
+
+	bridge.SetDC(false)
+	bridge.Write([]byte{device.CmdReadRAM})
+
+	data, err := bridge.ReadData(2)
+	if err != nil {
+		t.Fatalf("ReadData failed: %v", err)
+	}
+
+	// Dummy byte first, then the nibble-packed pixel pair
+	if data[0] != 0x00 || data[1] != 0xC3 {
+		t.Errorf("expected [0x00 0xC3], got % X", data)
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	if got := FormatCommand(0xC1, []byte{0x7F}); got != "SetContrast (0xC1) [0x7F]" {
+		t.Errorf("unexpected format: %q", got)
+	}
+	if got := FormatCommand(0xAF, nil); got != "NormalMode (0xAF)" {
+		t.Errorf("unexpected format: %q", got)
+	}
+	if got := FormatCommand(0x01, []byte{0x02, 0x03}); got != "Unknown (0x01) [0x02 0x03]" {
+		t.Errorf("unexpected format: %q", got)
+	}
+}
+
+// replayCommands sends a command stream through the bridge, using the data byte counts
+// from SSD1322Commands; everything after Write RAM is sent as pixel data
+func replayCommands(t *testing.T, bridge *SPIBridge, stream []byte) {
+	t.Helper()
+
+	for i := 0; i < len(stream); {
+		cmd := stream[i]
+		info, err := GetCommandInfo(cmd)
+		if err != nil {
+			t.Fatalf("offset %d: %v", i, err)
+		}
+
+		end := i + 1 + info.DataBytes
+		if cmd == device.CmdWriteRAM {
+			end = len(stream)
+		}
+
+		bridge.SetDC(false)
+		if err := bridge.Write([]byte{cmd}); err != nil {
+			t.Fatalf("offset %d: %v", i, err)
+		}
+		if end > i+1 {
+			bridge.SetDC(true)
+			if err := bridge.Write(stream[i+1 : end]); err != nil {
+				t.Fatalf("offset %d: %v", i, err)
+			}
+		}
+		i = end
+	}
+
+	if err := bridge.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+}
+
+// offsetSSD1322 is an SSD1322 whose display starts at internal column 3
+type offsetSSD1322 struct {
+	*device.SSD1322
+}
+
+func (offsetSSD1322) ColumnOffset() int {
+	return 3
+}
+
+func TestFillScreenCommandFillsDisplay(t *testing.T) {
+	if n := len(FillScreenCommand(0x0F)); n != 7+8192 {
+		t.Errorf("expected 7 command bytes and 8192 data bytes, got %d bytes", n)
+	}
+
+	for _, size := range [][2]int{{256, 64}, {128, 32}} {
+		dev := device.NewSSD1322(size[0], size[1])
+		bridge := NewSPIBridge(dev)
+
+		commands, err := FillScreenCommandFor(dev, 0x0F)
+		if err != nil {
+			t.Fatalf("%dx%d: fill command failed: %v", size[0], size[1], err)
+		}
+		replayCommands(t, bridge, commands)
+
+		for y := 0; y < size[1]; y++ {
+			for x := 0; x < size[0]; x++ {
+				if pixel, _ := dev.GetPixel(x, y); pixel != 0x0F {
+					t.Fatalf("%dx%d: expected pixel (%d, %d) set, got 0x%X", size[0], size[1], x, y, pixel)
+				}
+			}
+		}
+	}
+
+	// The window starts at the device's own column offset
+	commands, err := FillScreenCommandFor(offsetSSD1322{device.NewSSD1322(16, 2)}, 0x0F)
+	if err != nil {
+		t.Fatalf("offset device: fill command failed: %v", err)
+	}
+	if !bytes.Equal(commands[:3], []byte{0x15, 0x03, 0x06}) {
+		t.Errorf("expected column window 0x03-0x06, got % X", commands[:3])
+	}
+
+	if _, err := FillScreenCommandFor(device.NewSSD1306(128, 64), 0x0F); err == nil {
+		t.Error("expected error for a device that is not nibble-packed")
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	stream := []byte{0xFD, 0xB1, 0x01, 0xC1, 0x7F, 0x5C, 0xAA, 0xBB, 0xCC}
+	cmds := Disassemble(stream)
+
+	expected := []string{
+		"0xFD CommandLock [0xB1]",
+		"0x01 Unknown",
+		"0xC1 SetContrast [0x7F]",
+		"0x5C WriteRAM +3 data bytes",
+	}
+	if len(cmds) != len(expected) {
+		t.Fatalf("expected %d commands, got %v", len(expected), cmds)
+	}
+	for i, want := range expected {
+		if got := cmds[i].String(); got != want {
+			t.Errorf("command %d: expected %q, got %q", i, want, got)
+		}
+	}
+
+	if cmds[1].Known || cmds[1].Offset != 2 || cmds[3].Offset != 5 || !cmds[3].Trailing {
+		t.Errorf("unexpected decode details: %+v", cmds)
+	}
+
+	// A command cut off by the end of the stream keeps the bytes it got
+	cmds = Disassemble([]byte{0x15, 0x1C})
+	if len(cmds) != 1 || !cmds[0].Truncated || cmds[0].String() != "0x15 SetColumnAddress [0x1C] (truncated)" {
+		t.Errorf("expected truncated column address, got %v", cmds)
+	}
+
+	// The init sequence decodes without unknown bytes
+	for _, cmd := range Disassemble(SSD1322InitSequence()) {
+		if !cmd.Known || cmd.Truncated {
+			t.Errorf("unexpected decode of init sequence: %v", cmd)
+		}
+	}
+}
+
+func TestSPIBridgeWriteFrame(t *testing.T) {
+	src := graphics.NewFrameBuffer(device.NewNullDevice(256, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 256; x++ {
+			src.SetPixel(x, y, byte(x+y)&0x0F)
+		}
+	}
+
+	dev := device.NewSSD1322(256, 64)
+	dev.ClearDirtyRegion()
+	bridge := NewSPIBridge(dev)
+
+	if err := bridge.WriteFrame(src); err != nil {
+		t.Fatalf("write frame failed: %v", err)
+	}
+
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 256; x++ {
+			if pixel, _ := dev.GetPixel(x, y); pixel != byte(x+y)&0x0F {
+				t.Fatalf("expected 0x%X at (%d, %d), got 0x%X", byte(x+y)&0x0F, x, y, pixel)
+			}
+		}
+	}
+
+	if x0, y0, x1, y1 := dev.GetDirtyRegion(); x0 != 0 || y0 != 0 || x1 != 255 || y1 != 63 {
+		t.Errorf("expected full dirty region, got (%d, %d)-(%d, %d)", x0, y0, x1, y1)
+	}
+
+	small := graphics.NewFrameBuffer(device.NewNullDevice(128, 64))
+	if err := bridge.WriteFrame(small); err == nil {
+		t.Error("expected error for mismatched frame size")
+	}
+
+	oled := graphics.NewFrameBuffer(device.NewNullDevice(128, 64))
+	if err := NewSPIBridge(device.NewSSD1306(128, 64)).WriteFrame(oled); err == nil {
+		t.Error("expected error for a device without SSD1322 addressing")
+	}
+}
+
+func TestSPIBridgeWriteFrameColumnOffset(t *testing.T) {
+	// The mock only exposes the Device interface, so the window falls back to the SSD1322 default
+	mock := device.WrapMockDevice(device.NewSSD1322(256, 64))
+	bridge := NewSPIBridge(mock)
+
+	if err := bridge.WriteFrame(graphics.NewFrameBuffer(device.NewNullDevice(256, 64))); err != nil {
+		t.Fatalf("write frame failed: %v", err)
+	}
+
+	ops := mock.Operations()
+	if len(ops) == 0 || ops[0].Command != device.CmdSetColumnAddress {
+		t.Fatalf("expected a column address command first, got %v", ops)
+	}
+	if data := ops[0].Data; len(data) != 2 || data[0] != 0x1C || data[1] != 0x5B {
+		t.Errorf("expected the default column window 0x1C-0x5B, got %X", data)
+	}
+
+	// A device reporting its column offset moves the window
+	offset := offsetMock{device.WrapMockDevice(device.NewSSD1322(256, 64))}
+	if err := NewSPIBridge(offset).WriteFrame(graphics.NewFrameBuffer(device.NewNullDevice(256, 64))); err != nil {
+		t.Fatalf("write frame failed: %v", err)
+	}
+
+	ops = offset.Operations()
+	if data := ops[0].Data; len(data) != 2 || data[0] != 0x03 || data[1] != 0x42 {
+		t.Errorf("expected column window 0x03-0x42 from the device offset, got %X", data)
+	}
+}
+
+// offsetMock is a mock device whose display starts at internal column 3
+type offsetMock struct {
+	*device.MockDevice
+}
+
+func (offsetMock) ColumnOffset() int {
+	return 3
+}
+
+func benchmarkFrame() *graphics.FrameBuffer {
+	src := graphics.NewFrameBuffer(device.NewNullDevice(256, 64))
+	src.DrawCircle(128, 32, 30, 0x0F, true)
+	return src
+}
+
+func BenchmarkSPIBridgeWriteFrame(b *testing.B) {
+	src := benchmarkFrame()
+	bridge := NewSPIBridge(device.NewSSD1322(256, 64))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := bridge.WriteFrame(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSetPixelFrame(b *testing.B) {
+	src := benchmarkFrame()
+	dev := device.NewSSD1322(256, 64)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 256; x++ {
+				level, _ := src.GetPixel(x, y)
+				dev.SetPixel(x, y, level)
+			}
+		}
+	}
+}
@@ -2,19 +2,49 @@ package protocol
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/flavioheleno/oled-emulator/device"
+	"github.com/flavioheleno/oled-emulator/graphics"
 )
 
+// DefaultMinResetPulse is the shortest reset low time accepted by the bridge
+const DefaultMinResetPulse = 100 * time.Microsecond
+
+// BusyMode selects what happens to writes while the bridge reports busy
+type BusyMode int
+
+const (
+	BusyQueue  BusyMode = iota // Writes are queued and sent once the device is ready
+	BusyReject                 // Writes return an error
+)
+
+// spiWrite is a write queued while the device was busy
+type spiWrite struct {
+	dc   bool
+	data []byte
+}
+
 // SPIBridge emulates SPI communication with the display device
 type SPIBridge struct {
 	device      device.Device
 	dcPin       bool // Data/Command pin state
 	csPin       bool // Chip Select pin state
+	resetPin    bool // Reset pin state, active low
+	resetLowAt  time.Time
+	minReset    time.Duration
 	buffer      []byte
 	commandMode bool
 	dataBuffer  []byte
 	commandCode byte
+	pending     bool // true while commandCode waits for its data bytes
+	ramWrite    bool // true after WriteRAM, data bytes go to display RAM
+	sleep       func(time.Duration)
+	now         func() time.Time
+	busyFor     time.Duration // Busy time after RAM writes, zero means never busy
+	busyUntil   time.Time
+	busyMode    BusyMode
+	queue       []spiWrite
 }
 
 // NewSPIBridge creates a new SPI bridge
@@ -23,9 +53,13 @@ func NewSPIBridge(dev device.Device) *SPIBridge {
 		device:      dev,
 		dcPin:       false,
 		csPin:       false,
+		resetPin:    true,
+		minReset:    DefaultMinResetPulse,
 		buffer:      make([]byte, 256),
 		commandMode: true,
 		dataBuffer:  make([]byte, 0),
+		sleep:       time.Sleep,
+		now:         time.Now,
 	}
 }
 
@@ -37,14 +71,84 @@ func (sb *SPIBridge) SetDC(state bool) {
 
 // SetCS sets the Chip Select pin state
 // false = selected, true = not selected
-func (sb *SPIBridge) SetCS(state bool) {
+// Deselecting the chip ends the transaction, dispatching any pending command
+func (sb *SPIBridge) SetCS(state bool) error {
 	sb.csPin = state
+	if state {
+		return sb.Flush()
+	}
+
+	return nil
+}
+
+// SetReset sets the reset pin state
+// false = reset asserted, true = released
+// Releasing the pin resets the device when it was held low for at least the minimum pulse width
+func (sb *SPIBridge) SetReset(state bool) error {
+	if state == sb.resetPin {
+		return nil
+	}
+
+	sb.resetPin = state
+	if !state {
+		sb.resetLowAt = sb.now()
+		return nil
+	}
+
+	if width := sb.now().Sub(sb.resetLowAt); width < sb.minReset {
+		return fmt.Errorf("reset pulse too short: %v, need at least %v", width, sb.minReset)
+	}
+
+	return sb.Reset()
+}
+
+// SetMinResetPulse sets the minimum time the reset pin must be held low
+func (sb *SPIBridge) SetMinResetPulse(width time.Duration) {
+	sb.minReset = width
+}
+
+// SetNowFunc replaces the clock used to time the reset pulse
+// Passing nil restores time.Now
+func (sb *SPIBridge) SetNowFunc(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+
+	sb.now = now
+}
+
+// SetBusyDuration sets how long the device stays busy after each RAM data write
+// The default of zero means the device is never busy
+func (sb *SPIBridge) SetBusyDuration(d time.Duration) {
+	sb.busyFor = d
+}
+
+// SetBusyMode selects whether writes while busy are queued or rejected
+func (sb *SPIBridge) SetBusyMode(mode BusyMode) {
+	sb.busyMode = mode
+}
+
+// IsBusy returns the state of the busy line
+func (sb *SPIBridge) IsBusy() bool {
+	return sb.busyFor > 0 && sb.now().Before(sb.busyUntil)
+}
+
+// QueuedWrites returns the number of writes waiting for the device to become ready
+func (sb *SPIBridge) QueuedWrites() int {
+	return len(sb.queue)
+}
+
+// markBusy starts a busy period
+func (sb *SPIBridge) markBusy() {
+	if sb.busyFor > 0 {
+		sb.busyUntil = sb.now().Add(sb.busyFor)
+	}
 }
 
 // Write sends data over SPI
 func (sb *SPIBridge) Write(data []byte) error {
-	if sb.csPin {
-		// Chip not selected, ignore write
+	if sb.csPin || !sb.resetPin {
+		// Chip not selected or held in reset, ignore write
 		return nil
 	}
 
@@ -52,7 +156,27 @@ func (sb *SPIBridge) Write(data []byte) error {
 		return nil
 	}
 
-	if sb.dcPin {
+	if err := sb.drainQueue(); err != nil {
+		return err
+	}
+
+	if sb.IsBusy() || len(sb.queue) > 0 {
+		if sb.busyMode == BusyReject {
+			return fmt.Errorf("device busy")
+		}
+
+		queued := spiWrite{dc: sb.dcPin, data: make([]byte, len(data))}
+		copy(queued.data, data)
+		sb.queue = append(sb.queue, queued)
+		return nil
+	}
+
+	return sb.write(sb.dcPin, data)
+}
+
+// write routes bytes by the DC pin state
+func (sb *SPIBridge) write(dc bool, data []byte) error {
+	if dc {
 		// Data mode
 		return sb.writeData(data)
 	}
@@ -61,75 +185,258 @@ func (sb *SPIBridge) Write(data []byte) error {
 	return sb.writeCommand(data)
 }
 
+// waitReady sends every queued write, sleeping out busy periods like a driver polling the busy line
+func (sb *SPIBridge) waitReady() error {
+	for len(sb.queue) > 0 {
+		if sb.IsBusy() {
+			sb.sleep(sb.busyUntil.Sub(sb.now()))
+			sb.busyUntil = time.Time{} // The wait covered the busy period
+		}
+
+		next := sb.queue[0]
+		sb.queue = sb.queue[1:]
+		if err := sb.write(next.dc, next.data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drainQueue sends queued writes until the device becomes busy again
+func (sb *SPIBridge) drainQueue() error {
+	for len(sb.queue) > 0 && !sb.IsBusy() {
+		next := sb.queue[0]
+		sb.queue = sb.queue[1:]
+		if err := sb.write(next.dc, next.data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // writeCommand processes command bytes
+// Each command byte completes the previous command, which is dispatched with the data collected since
 func (sb *SPIBridge) writeCommand(data []byte) error {
 	for _, b := range data {
-		if err := sb.device.ProcessCommand(b, sb.dataBuffer); err != nil {
-			return fmt.Errorf("command error: %w", err)
+		if err := sb.dispatch(); err != nil {
+			return err
 		}
-		sb.dataBuffer = sb.dataBuffer[:0]
+
 		sb.commandCode = b
+		sb.ramWrite = b == device.CmdWriteRAM
+		sb.pending = true
+
+		// Commands known to take no data run at once, so RAM access and display on/off need no flush
+		if info, err := GetCommandInfo(b); err == nil && info.DataBytes == 0 {
+			if err := sb.dispatch(); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
 // writeData processes data bytes
+// After WriteRAM the bytes are pixel data, otherwise they are collected as parameters for the pending command
 func (sb *SPIBridge) writeData(data []byte) error {
-	// For SSD1322, data mode typically follows a WriteRAM command
-	// The device implementation handles writing to VRAM through SetPixel or similar
-	// For now, we'll just acknowledge the data
-	// A full implementation would process the data into the display buffer
+	if sb.ramWrite {
+		writer, ok := sb.device.(dataWriter)
+		if !ok {
+			return fmt.Errorf("device does not support RAM data writes")
+		}
+
+		if err := writer.WriteData(data); err != nil {
+			return err
+		}
+
+		// The device is busy once it has the data, while it updates the panel
+		sb.markBusy()
+		return nil
+	}
+
+	if !sb.pending {
+		return fmt.Errorf("data bytes without a pending command")
+	}
+
+	sb.dataBuffer = append(sb.dataBuffer, data...)
+	return nil
+}
+
+// Flush sends any writes queued while busy, then dispatches the pending command with the data bytes collected so far
+// It is called automatically when the chip is deselected and before reads
+func (sb *SPIBridge) Flush() error {
+	if err := sb.waitReady(); err != nil {
+		return err
+	}
+
+	return sb.dispatch()
+}
+
+// dispatch runs the pending command with the data bytes collected so far
+// It is called when the next command byte arrives
+func (sb *SPIBridge) dispatch() error {
+	if !sb.pending {
+		return nil
+	}
+
+	sb.pending = false
+	err := sb.device.ProcessCommand(sb.commandCode, sb.dataBuffer)
+	sb.dataBuffer = sb.dataBuffer[:0]
+	if err != nil {
+		return fmt.Errorf("command error: %w", err)
+	}
 
 	return nil
 }
 
 // Reset performs a hardware reset sequence
+// A pending command and queued writes are discarded
 func (sb *SPIBridge) Reset() error {
 	sb.dataBuffer = sb.dataBuffer[:0]
+	sb.pending = false
+	sb.ramWrite = false
+	sb.queue = nil
+	sb.busyUntil = time.Time{}
 	return sb.device.Reset()
 }
 
-// ReadData reads from the display (if supported)
-// Note: This is a placeholder - real SSD1322 does support reading
+// ReadData reads display RAM from the device after a ReadRAM command
+// The first byte after ReadRAM is a dummy byte, as on the real chip
 func (sb *SPIBridge) ReadData(length int) ([]byte, error) {
-	result := make([]byte, length)
+	if sb.csPin {
+		return nil, fmt.Errorf("chip not selected")
+	}
+
+	if err := sb.Flush(); err != nil {
+		return nil, err
+	}
 
-	// For now, return zeros - real implementation would read VRAM
-	for i := 0; i < length; i++ {
-		result[i] = 0
+	reader, ok := sb.device.(dataReader)
+	if !ok {
+		return nil, fmt.Errorf("device does not support RAM data reads")
 	}
 
-	return result, nil
+	return reader.ReadData(length)
 }
 
 // SendInitSequence sends an initialization sequence
+// Each command byte is sent with DC low followed by its data bytes with DC high,
+// using the data byte counts from SSD1322Commands
 func (sb *SPIBridge) SendInitSequence(sequence []byte) error {
 	// Command unlock
-	sb.SetDC(false)
-	if err := sb.Write([]byte{0xFD}); err != nil {
+	if err := sb.sendCommand(device.CmdCommandLock, []byte{0xB1}); err != nil {
 		return fmt.Errorf("unlock command failed: %w", err)
 	}
 
-	sb.SetDC(false)
-	if err := sb.Write([]byte{0xB1}); err != nil {
-		return err
+	for i := 0; i < len(sequence); {
+		cmd := sequence[i]
+		info, err := GetCommandInfo(cmd)
+		if err != nil {
+			return fmt.Errorf("init sequence offset %d: %w", i, err)
+		}
+
+		end := i + 1 + info.DataBytes
+		if end > len(sequence) {
+			return fmt.Errorf("init sequence offset %d: %s needs %d data bytes, got %d", i, info.Name, info.DataBytes, len(sequence)-i-1)
+		}
+
+		if err := sb.sendCommand(cmd, sequence[i+1:end]); err != nil {
+			return err
+		}
+		i = end
 	}
 
-	// Send initialization sequence
-	for i := 0; i < len(sequence); i++ {
-		if i%2 == 0 {
-			// Command byte
-			sb.SetDC(false)
-			if err := sb.Write([]byte{sequence[i]}); err != nil {
-				return err
+	return sb.Flush()
+}
+
+// SetSleepFunc replaces the function used to wait during RunInitSequence and for queued writes while busy
+// Passing nil restores time.Sleep
+func (sb *SPIBridge) SetSleepFunc(sleep func(time.Duration)) {
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	sb.sleep = sleep
+}
+
+// RunInitSequence sends structured init steps, waiting after steps that have a delay
+// Each command is dispatched before its delay starts
+func (sb *SPIBridge) RunInitSequence(steps []InitStep) error {
+	for i, step := range steps {
+		if !step.Delay {
+			if err := sb.sendCommand(step.Command, step.Data); err != nil {
+				return fmt.Errorf("init step %d: %w", i, err)
 			}
-		} else {
-			// Data byte
-			sb.SetDC(false)
-			if err := sb.Write([]byte{sequence[i]}); err != nil {
+			if err := sb.Flush(); err != nil {
+				return fmt.Errorf("init step %d: %w", i, err)
+			}
+		}
+
+		if step.DelayMs > 0 {
+			sb.sleep(time.Duration(step.DelayMs) * time.Millisecond)
+		}
+	}
+
+	return nil
+}
+
+// WriteFrame sends the whole framebuffer the way a driver does: it sets a full-screen
+// column and row window, issues WriteRAM and streams the nibble-packed pixels in one burst
+// It uses SSD1322 addressing, so the device must be nibble-packed; the window starts at the device column offset
+func (sb *SPIBridge) WriteFrame(fb *graphics.FrameBuffer) error {
+	if format := sb.device.PixelFormat(); format != device.HorizontalNibble {
+		return fmt.Errorf("frame writes need a nibble-packed SSD1322-style device, got pixel format %d", format)
+	}
+
+	width, height := fb.Width(), fb.Height()
+	if width != sb.device.Width() || height != sb.device.Height() {
+		return fmt.Errorf("frame is %dx%d, device is %dx%d", width, height, sb.device.Width(), sb.device.Height())
+	}
+
+	columns := (width + ssd1322ColumnPixels - 1) / ssd1322ColumnPixels
+	rowBytes := columns * ssd1322ColumnPixels / 2
+	frame := make([]byte, rowBytes*height)
+
+	// Two pixels per byte, low nibble first
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			level, err := fb.GetPixel(x, y)
+			if err != nil {
 				return err
 			}
+
+			frame[y*rowBytes+x/2] |= (level & 0x0F) << (4 * (x % 2))
+		}
+	}
+
+	first := firstColumn(sb.device)
+	if err := sb.sendCommand(device.CmdSetColumnAddress, []byte{byte(first), byte(first + columns - 1)}); err != nil {
+		return err
+	}
+	if err := sb.sendCommand(device.CmdSetRowAddress, []byte{0x00, byte(height - 1)}); err != nil {
+		return err
+	}
+	if err := sb.sendCommand(device.CmdWriteRAM, frame); err != nil {
+		return err
+	}
+
+	return sb.Flush()
+}
+
+// sendCommand writes a command byte followed by its data bytes
+func (sb *SPIBridge) sendCommand(cmd byte, data []byte) error {
+	sb.SetDC(false)
+	if err := sb.Write([]byte{cmd}); err != nil {
+		return err
+	}
+
+	if len(data) > 0 {
+		sb.SetDC(true)
+		if err := sb.Write(data); err != nil {
+			return err
 		}
 	}
 
@@ -146,6 +453,8 @@ func (sb *SPIBridge) GetStatus() Status {
 	return Status{
 		DCPin:       sb.dcPin,
 		CSPin:       sb.csPin,
+		ResetPin:    sb.resetPin,
+		Busy:        sb.IsBusy(),
 		CommandMode: !sb.dcPin,
 		LastCommand: sb.commandCode,
 	}
@@ -155,6 +464,8 @@ func (sb *SPIBridge) GetStatus() Status {
 type Status struct {
 	DCPin       bool
 	CSPin       bool
+	ResetPin    bool
+	Busy        bool
 	CommandMode bool
 	LastCommand byte
 }
@@ -0,0 +1,20 @@
+package render
+
+import (
+	"image"
+
+	"github.com/flavioheleno/oled-emulator/device"
+)
+
+// Headless renders the device display to an RGBA image without opening a window
+// It runs the same pixel pipeline as the emulator window (palette, brightness, inversion,
+// grayscale table and pixel mapping) and never touches the GPU, so it is safe in CI.
+// A nil palette uses the default grayscale palette; scales below 1 are treated as 1
+func Headless(dev device.Device, scale int, palette *Palette) image.Image {
+	r := NewRenderer(dev, max(scale, 1))
+	if palette != nil {
+		r.SetPalette(palette)
+	}
+
+	return r.RenderToRGBA()
+}
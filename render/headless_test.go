@@ -0,0 +1,36 @@
+package render
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/flavioheleno/oled-emulator/device"
+)
+
+func TestHeadless(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	dev.ProcessCommand(device.CmdSetContrast, []byte{0xFF})
+	dev.SetPixel(3, 2, 0x0F)
+
+	palette := NewWhitePalette()
+	img := Headless(dev, 2, palette)
+
+	if bounds := img.Bounds(); bounds.Dx() != 512 || bounds.Dy() != 128 {
+		t.Fatalf("expected 512x128 image, got %v", bounds)
+	}
+
+	white := color.RGBAModel.Convert(palette.Colors[15])
+	if got := img.At(7, 5); got != white {
+		t.Errorf("expected lit pixel %v, got %v", white, got)
+	}
+	if got := img.At(0, 0); got != color.RGBAModel.Convert(palette.Colors[0]) {
+		t.Errorf("expected off pixel, got %v", got)
+	}
+
+	// Inversion uses the live renderer logic
+	dev.ProcessCommand(device.CmdInverseDisplay, nil)
+	inverted := Headless(dev, 1, palette)
+	if got := inverted.At(0, 0); got != white {
+		t.Errorf("expected inverted off pixel to render lit, got %v", got)
+	}
+}
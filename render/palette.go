@@ -0,0 +1,117 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+)
+
+// Palette defines color mapping for different grayscale levels
+type Palette struct {
+	Colors [16]color.Color
+}
+
+// offColor is the color of unlit pixels (level 0) in the built-in palettes
+var offColor = color.RGBA{R: 20, G: 20, B: 20, A: 255}
+
+// NewGrayscalePalette creates a standard grayscale palette
+func NewGrayscalePalette() *Palette {
+	p := &Palette{}
+
+	// Create grayscale levels from black to white
+	for i := 0; i < 16; i++ {
+		level := uint8((i * 255) / 15)
+		// OLED-style: yellow tint for bright pixels, slight blue tint for dim
+		if i < 8 {
+			// Darker pixels: slight blue tint
+			p.Colors[i] = color.RGBA{
+				R: level * 200 / 255,
+				G: level * 150 / 255,
+				B: level * 255 / 255,
+				A: 255,
+			}
+		} else {
+			// Brighter pixels: yellow tint (characteristic of OLEDs)
+			p.Colors[i] = color.RGBA{
+				R: level,
+				G: level * 200 / 255,
+				B: level * 100 / 255,
+				A: 255,
+			}
+		}
+	}
+
+	// Ensure color 0 is pure black for off pixels
+	p.Colors[0] = offColor
+
+	return p
+}
+
+// NewMonochromePalette creates a palette ramping from off (level 0) to the given color (level 15)
+func NewMonochromePalette(on color.Color) *Palette {
+	p := &Palette{}
+	r, g, b, _ := on.RGBA()
+
+	p.Colors[0] = offColor
+	for i := 1; i < 16; i++ {
+		p.Colors[i] = color.RGBA{
+			R: uint8((r >> 8) * uint32(i) / 15),
+			G: uint8((g >> 8) * uint32(i) / 15),
+			B: uint8((b >> 8) * uint32(i) / 15),
+			A: 255,
+		}
+	}
+
+	return p
+}
+
+// NewWhitePalette creates a palette for white OLED panels
+func NewWhitePalette() *Palette {
+	return NewMonochromePalette(color.RGBA{R: 255, G: 255, B: 255, A: 255})
+}
+
+// NewAmberPalette creates a palette for amber OLED panels
+func NewAmberPalette() *Palette {
+	return NewMonochromePalette(color.RGBA{R: 255, G: 176, B: 0, A: 255})
+}
+
+// NewBluePalette creates a palette for monochrome blue OLED panels
+func NewBluePalette() *Palette {
+	return NewMonochromePalette(color.RGBA{R: 40, G: 170, B: 255, A: 255})
+}
+
+// FromColors replaces all 16 palette entries; index 0 is the color of unlit pixels
+// Returns the palette to allow chaining
+func (p *Palette) FromColors(colors [16]color.Color) *Palette {
+	p.Colors = colors
+	return p
+}
+
+// palettePresets maps preset names accepted by NewPalettePreset to their constructors
+var palettePresets = map[string]func() *Palette{
+	"grayscale": NewGrayscalePalette,
+	"white":     NewWhitePalette,
+	"amber":     NewAmberPalette,
+	"blue":      NewBluePalette,
+}
+
+// PalettePresets returns the names of the built-in palette presets in sorted order
+func PalettePresets() []string {
+	names := make([]string, 0, len(palettePresets))
+	for name := range palettePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NewPalettePreset creates the named built-in palette
+func NewPalettePreset(name string) (*Palette, error) {
+	constructor, ok := palettePresets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown palette preset: %q", name)
+	}
+
+	return constructor(), nil
+}
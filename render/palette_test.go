@@ -0,0 +1,36 @@
+package render
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestPalettePresets(t *testing.T) {
+	for _, name := range PalettePresets() {
+		p, err := NewPalettePreset(name)
+		if err != nil {
+			t.Fatalf("preset %q: %v", name, err)
+		}
+		if p.Colors[0] != offColor {
+			t.Errorf("preset %q: index 0 should be the off color, got %v", name, p.Colors[0])
+		}
+	}
+
+	if _, err := NewPalettePreset("magenta"); err == nil {
+		t.Error("expected error for unknown preset")
+	}
+
+	amber := NewAmberPalette()
+	if amber.Colors[15] != (color.RGBA{R: 255, G: 176, B: 0, A: 255}) {
+		t.Errorf("expected full amber at level 15, got %v", amber.Colors[15])
+	}
+
+	var colors [16]color.Color
+	for i := range colors {
+		colors[i] = color.Gray{Y: uint8(i * 17)}
+	}
+	custom := (&Palette{}).FromColors(colors)
+	if custom.Colors[7] != colors[7] {
+		t.Error("FromColors should copy the given colors")
+	}
+}
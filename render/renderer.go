@@ -0,0 +1,410 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"math"
+
+	"github.com/flavioheleno/oled-emulator/device"
+)
+
+// displayPixelReader is implemented by devices that map screen rows through
+// the start line and display offset (e.g. SSD1322.GetDisplayPixel)
+type displayPixelReader interface {
+	GetDisplayPixel(x, y int) (byte, error)
+}
+
+// DisplayPixel reads the 4-bit level shown at screen coordinates (x, y) of dev, before inversion
+// Devices that remap rows are read through GetDisplayPixel, others straight from VRAM
+func DisplayPixel(dev device.Device, x, y int) (byte, error) {
+	if reader, ok := dev.(displayPixelReader); ok {
+		return reader.GetDisplayPixel(x, y)
+	}
+	return dev.GetPixel(x, y)
+}
+
+// rowMapper is implemented by devices whose start line, display offset and multiplex ratio remap RAM rows on screen
+type rowMapper interface {
+	GetStartLine() int
+	GetDisplayOffset() int
+	GetMultiplexRatio() int
+}
+
+// grayscaleMapper is implemented by devices with a programmable grayscale table
+type grayscaleMapper interface {
+	GetGrayscaleTable() []byte
+}
+
+// renderState captures the settings that change every rendered pixel without marking VRAM dirty
+type renderState struct {
+	palette       [16]color.Color
+	brightness    float64
+	inverted      bool
+	allOn         bool
+	startLine     int
+	displayOffset int
+	muxRatio      int
+	background    color.Color
+	pixelGap      int
+	grayscale     [device.GrayscaleTableSize]byte
+}
+
+// Renderer converts device VRAM to scaled RGBA images
+// It has no GPU or window dependency, so it works in headless tests and CI
+type Renderer struct {
+	device          device.Device
+	palette         *Palette
+	scale           int
+	backgroundColor color.Color
+	lastState       renderState
+	persistence     int       // Frames for a pixel to fade out, 0 = disabled
+	glow            []float64 // Displayed RGB per pixel while persistence is enabled
+	glowing         bool      // Some pixels are still fading
+	pixelGap        int       // Background pixels between logical pixels
+}
+
+// NewRenderer creates a renderer using the grayscale palette
+func NewRenderer(dev device.Device, scale int) *Renderer {
+	return &Renderer{
+		device:          dev,
+		palette:         NewGrayscalePalette(),
+		scale:           scale,
+		backgroundColor: color.RGBA{R: 20, G: 20, B: 20, A: 255},
+	}
+}
+
+// SetPalette sets a custom palette
+func (r *Renderer) SetPalette(p *Palette) {
+	r.palette = p
+}
+
+// Palette returns the active palette
+func (r *Renderer) Palette() *Palette {
+	return r.palette
+}
+
+// SetDevice changes the device being rendered
+func (r *Renderer) SetDevice(dev device.Device) {
+	r.device = dev
+}
+
+// Device returns the device being rendered
+func (r *Renderer) Device() device.Device {
+	return r.device
+}
+
+// Scale returns the number of image pixels per device pixel
+func (r *Renderer) Scale() int {
+	return r.scale
+}
+
+// SetPixelGap draws each logical pixel as a (scale-gap) square, leaving a gap of the background color
+// between pixels like a magnified OLED panel. The gap must be smaller than the scale
+func (r *Renderer) SetPixelGap(gap int) error {
+	if gap < 0 || gap >= r.scale {
+		return fmt.Errorf("pixel gap %d must be between 0 and scale-1 (%d)", gap, r.scale-1)
+	}
+
+	r.pixelGap = gap
+	return nil
+}
+
+// SetPersistence makes pixels that dim fade out over the given number of frames, like OLED phosphor decay
+// A value of 0 disables the effect and restores exact rendering
+func (r *Renderer) SetPersistence(frames int) {
+	if frames < 0 {
+		frames = 0
+	}
+
+	r.persistence = frames
+	r.glow = nil
+	r.glowing = false
+}
+
+// SetBackgroundColor sets the background color (off pixel color)
+func (r *Renderer) SetBackgroundColor(c color.Color) {
+	r.backgroundColor = c
+}
+
+// NeedsFullRender reports whether the whole display must be redrawn rather than just the dirty region
+// This is the case when palette, brightness, inversion or row mapping (including the MUX) changed since the last call,
+// or when rows are remapped so a pending device dirty region no longer matches screen rows
+func (r *Renderer) NeedsFullRender() bool {
+	state := r.currentState()
+	changed := state != r.lastState
+	r.lastState = state
+
+	// Fading pixels change every frame without marking VRAM dirty
+	if changed || r.glowing {
+		return true
+	}
+
+	remapped := state.startLine != 0 || state.displayOffset != 0
+	x0, _, _, _ := r.device.GetDirtyRegion()
+
+	return remapped && x0 != -1
+}
+
+// currentState samples the device and renderer settings that affect every pixel
+func (r *Renderer) currentState() renderState {
+	state := renderState{
+		palette:    r.palette.Colors,
+		brightness: r.brightnessFactor(),
+		background: r.backgroundColor,
+		pixelGap:   r.pixelGap,
+	}
+
+	if reporter, ok := r.device.(device.InvertReporter); ok {
+		state.inverted = reporter.IsInverted()
+	}
+	if reporter, ok := r.device.(device.AllOnReporter); ok {
+		state.allOn = reporter.IsAllOn()
+	}
+	if mapper, ok := r.device.(rowMapper); ok {
+		state.startLine = mapper.GetStartLine()
+		state.displayOffset = mapper.GetDisplayOffset()
+		state.muxRatio = mapper.GetMultiplexRatio()
+	}
+	if mapper, ok := r.device.(grayscaleMapper); ok {
+		copy(state.grayscale[:], mapper.GetGrayscaleTable())
+	}
+
+	return state
+}
+
+// RenderToRGBA renders the entire VRAM to a standard image
+// Palette, scale, brightness and inversion are applied as on screen.
+// Fading pixels are shown as currently displayed without advancing the fade
+func (r *Renderer) RenderToRGBA() *image.RGBA {
+	return r.renderRegion(0, 0, r.device.Width()-1, r.device.Height()-1, false)
+}
+
+// RenderRegion renders device pixels (x0, y0)-(x1, y1) as a new on-screen frame, advancing the persistence fade
+// The image bounds are in scaled screen coordinates, so it can be written straight into a sub-image
+func (r *Renderer) RenderRegion(x0, y0, x1, y1 int) *image.RGBA {
+	return r.renderRegion(x0, y0, x1, y1, true)
+}
+
+// RenderToPaletted renders the display to a palettized image suitable for GIF encoding
+// Grayscale devices use the brightness-adjusted palette, RGB devices the Plan 9 palette
+func (r *Renderer) RenderToPaletted() *image.Paletted {
+	rgba := r.RenderToRGBA()
+
+	var pal color.Palette
+	if _, ok := r.device.(device.RGBDevice); ok {
+		pal = palette.Plan9
+	} else {
+		colors := r.effectivePalette(r.brightnessFactor())
+		pal = colors[:]
+	}
+
+	img := image.NewPaletted(rgba.Bounds(), pal)
+	draw.Draw(img, img.Bounds(), rgba, image.Point{}, draw.Src)
+
+	return img
+}
+
+// renderRegion renders device pixels (x0, y0)-(x1, y1) into a scaled image
+// The image bounds are in scaled screen coordinates, so it can be written straight into a sub-image.
+// advance steps the persistence fade by one frame (on-screen renders only)
+func (r *Renderer) renderRegion(x0, y0, x1, y1 int, advance bool) *image.RGBA {
+	x0 = max(x0, 0)
+	y0 = max(y0, 0)
+	x1 = min(x1, r.device.Width()-1)
+	y1 = min(y1, r.device.Height()-1)
+
+	img := image.NewRGBA(image.Rect(x0*r.scale, y0*r.scale, (x1+1)*r.scale, (y1+1)*r.scale))
+	colorAt := r.colorFunc()
+
+	fullFrame := x0 == 0 && y0 == 0 && x1 == r.device.Width()-1 && y1 == r.device.Height()-1
+	if advance && fullFrame {
+		r.glowing = false
+	}
+
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			pixelColor := colorAt(x, y)
+			if r.persistence > 0 {
+				pixelColor = r.persist(x, y, pixelColor, advance)
+			}
+
+			rect := image.Rect(
+				x*r.scale, y*r.scale,
+				(x+1)*r.scale, (y+1)*r.scale,
+			)
+
+			// The gap occupies the right and bottom edge of each pixel
+			lit := image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X-r.pixelGap, rect.Max.Y-r.pixelGap)
+
+			for py := rect.Min.Y; py < rect.Max.Y; py++ {
+				for px := rect.Min.X; px < rect.Max.X; px++ {
+					if px < lit.Max.X && py < lit.Max.Y {
+						img.Set(px, py, pixelColor)
+					} else {
+						img.Set(px, py, r.backgroundColor)
+					}
+				}
+			}
+		}
+	}
+
+	return img
+}
+
+// persist blends the target color with the fading glow of pixel (x, y)
+// Brighter targets light up instantly; dimmer ones fade linearly over the persistence frames
+func (r *Renderer) persist(x, y int, target color.Color, advance bool) color.Color {
+	if r.glow == nil {
+		r.glow = make([]float64, r.device.Width()*r.device.Height()*3)
+	}
+
+	tr, tg, tb, _ := target.RGBA()
+	targets := [3]float64{float64(tr >> 8), float64(tg >> 8), float64(tb >> 8)}
+	step := 256 / float64(r.persistence)
+
+	glow := r.glow[(y*r.device.Width()+x)*3:][:3]
+	var out [3]byte
+	for i, t := range targets {
+		value := glow[i]
+		if advance {
+			value -= step
+		}
+		value = math.Max(t, value)
+
+		if advance {
+			glow[i] = value
+			if value > t {
+				r.glowing = true
+			}
+		}
+		out[i] = byte(value)
+	}
+
+	return color.RGBA{R: out[0], G: out[1], B: out[2], A: 255}
+}
+
+// pixelLevel returns the 4-bit level displayed at screen coordinates (x, y)
+func (r *Renderer) pixelLevel(x, y int) byte {
+	if reporter, ok := r.device.(device.AllOnReporter); ok && reporter.IsAllOn() {
+		return 0x0F
+	}
+
+	pixel, err := DisplayPixel(r.device, x, y)
+	if err != nil {
+		pixel = 0
+	}
+
+	pixel = pixel & 0x0F
+
+	// Inverse display complements the level before the palette lookup
+	if reporter, ok := r.device.(device.InvertReporter); ok && reporter.IsInverted() {
+		pixel = 0x0F - pixel
+	}
+
+	return pixel
+}
+
+// grayscaleLevels maps each 4-bit level through the device grayscale table to
+// the palette index with the closest brightness. Level 0 is always off.
+func (r *Renderer) grayscaleLevels() [16]byte {
+	var levels [16]byte
+	for i := range levels {
+		levels[i] = byte(i)
+	}
+
+	mapper, ok := r.device.(grayscaleMapper)
+	if !ok {
+		return levels
+	}
+
+	table := mapper.GetGrayscaleTable()
+	for i := 1; i < len(levels) && i <= len(table); i++ {
+		levels[i] = byte((int(table[i-1])*15 + device.GrayscaleMax/2) / device.GrayscaleMax)
+	}
+
+	return levels
+}
+
+// pixelColor returns the color displayed at screen coordinates (x, y)
+func (r *Renderer) pixelColor(x, y int) color.Color {
+	return r.colorFunc()(x, y)
+}
+
+// colorFunc returns a function mapping screen coordinates to output colors
+// Device state (brightness, palette) is sampled once so a frame renders consistently
+func (r *Renderer) colorFunc() func(x, y int) color.Color {
+	factor := r.brightnessFactor()
+
+	if rgbDev, ok := r.device.(device.RGBDevice); ok {
+		return func(x, y int) color.Color {
+			return scaleColor(r.rgbColor(rgbDev, x, y), factor)
+		}
+	}
+
+	palette := r.effectivePalette(factor)
+	levels := r.grayscaleLevels()
+	return func(x, y int) color.Color {
+		return palette[levels[r.pixelLevel(x, y)]]
+	}
+}
+
+// rgbColor returns the raw color displayed at (x, y) on a color device
+func (r *Renderer) rgbColor(rgbDev device.RGBDevice, x, y int) color.Color {
+	if reporter, ok := r.device.(device.AllOnReporter); ok && reporter.IsAllOn() {
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+
+	red, green, blue, err := rgbDev.GetPixelRGB(x, y)
+	if err != nil {
+		return color.RGBA{A: 255}
+	}
+
+	if reporter, ok := r.device.(device.InvertReporter); ok && reporter.IsInverted() {
+		red, green, blue = 255-red, 255-green, 255-blue
+	}
+
+	return color.RGBA{R: red, G: green, B: blue, A: 255}
+}
+
+// brightnessFactor returns the device brightness in the range 0 to 1
+// Modeled as (contrast/255) * (masterCurrent/15)
+func (r *Renderer) brightnessFactor() float64 {
+	reporter, ok := r.device.(device.BrightnessReporter)
+	if !ok {
+		return 1
+	}
+
+	return float64(reporter.GetContrastLevel()) / 255 * float64(reporter.GetMasterCurrent()&0x0F) / 15
+}
+
+// effectivePalette returns the palette scaled by the given brightness factor
+func (r *Renderer) effectivePalette(factor float64) [16]color.Color {
+	if factor >= 1 {
+		return r.palette.Colors
+	}
+
+	var colors [16]color.Color
+	for i, c := range r.palette.Colors {
+		colors[i] = scaleColor(c, factor)
+	}
+
+	return colors
+}
+
+// scaleColor scales the RGB channels of a color by factor, keeping alpha
+func scaleColor(c color.Color, factor float64) color.Color {
+	if factor >= 1 {
+		return c
+	}
+
+	r, g, b, a := c.RGBA()
+	return color.RGBA64{
+		R: uint16(float64(r) * factor),
+		G: uint16(float64(g) * factor),
+		B: uint16(float64(b) * factor),
+		A: uint16(a),
+	}
+}
@@ -0,0 +1,229 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/flavioheleno/oled-emulator/device"
+)
+
+func TestRendererInvertDisplay(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	renderer := NewRenderer(dev, 1)
+
+	dev.SetPixel(0, 0, 0x00)
+	dev.SetPixel(1, 0, 0x05)
+	dev.SetPixel(2, 0, 0x0F)
+
+	// Full contrast renders palette entries unscaled
+	dev.ProcessCommand(device.CmdSetContrast, []byte{0xFF})
+	dev.ProcessCommand(device.CmdInverseDisplay, nil)
+
+	tests := []struct {
+		x        int
+		expected byte
+	}{
+		{0, 0x0F},
+		{1, 0x0A},
+		{2, 0x00},
+	}
+
+	for _, test := range tests {
+		if level := renderer.pixelLevel(test.x, 0); level != test.expected {
+			t.Errorf("pixel %d: expected inverted level 0x%02X, got 0x%02X", test.x, test.expected, level)
+		}
+
+		if renderer.pixelColor(test.x, 0) != renderer.palette.Colors[test.expected] {
+			t.Errorf("pixel %d: rendered color should use palette entry 0x%02X", test.x, test.expected)
+		}
+	}
+}
+
+func TestRendererContrastScaling(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	renderer := NewRenderer(dev, 1)
+
+	dev.SetPixel(0, 0, 0x0F)
+
+	// Full contrast renders the palette value unchanged
+	dev.ProcessCommand(device.CmdSetContrast, []byte{0xFF})
+
+	r, g, b, _ := renderer.pixelColor(0, 0).RGBA()
+	pr, pg, pb, _ := renderer.palette.Colors[0x0F].RGBA()
+	if r != pr || g != pg || b != pb {
+		t.Errorf("full contrast should render palette color, got (%d, %d, %d)", r, g, b)
+	}
+
+	// Zero contrast renders near-black
+	dev.ProcessCommand(device.CmdSetContrast, []byte{0x00})
+
+	r, g, b, _ = renderer.pixelColor(0, 0).RGBA()
+	if r > 0x0100 || g > 0x0100 || b > 0x0100 {
+		t.Errorf("zero contrast should render near-black, got (%d, %d, %d)", r, g, b)
+	}
+}
+
+func TestRenderToRGBA(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	renderer := NewRenderer(dev, 2)
+
+	dev.SetPixel(1, 0, 0x0F)
+
+	img := renderer.RenderToRGBA()
+	if img.Bounds().Dx() != 512 || img.Bounds().Dy() != 128 {
+		t.Fatalf("expected 512x128 image, got %v", img.Bounds())
+	}
+
+	// Each device pixel covers a scale x scale block
+	lit := renderer.pixelColor(1, 0)
+	for _, p := range [][2]int{{2, 0}, {3, 1}} {
+		if img.At(p[0], p[1]) != color.RGBAModel.Convert(lit) {
+			t.Errorf("pixel %v should use the lit color", p)
+		}
+	}
+	if img.At(0, 0) != color.RGBAModel.Convert(renderer.pixelColor(0, 0)) {
+		t.Error("pixel (0, 0) should use the off color")
+	}
+}
+
+func TestNeedsFullRender(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	renderer := NewRenderer(dev, 1)
+
+	if !renderer.NeedsFullRender() {
+		t.Error("first frame should need a full render")
+	}
+	if renderer.NeedsFullRender() {
+		t.Error("unchanged state should not need a full render")
+	}
+
+	dev.ProcessCommand(device.CmdInverseDisplay, nil)
+	if !renderer.NeedsFullRender() {
+		t.Error("inverting the display should need a full render")
+	}
+
+	renderer.SetPalette(NewAmberPalette())
+	if !renderer.NeedsFullRender() {
+		t.Error("changing the palette should need a full render")
+	}
+
+	var colors [16]color.Color
+	for i := range colors {
+		colors[i] = color.Gray{Y: uint8(i * 17)}
+	}
+	renderer.palette.FromColors(colors)
+	if !renderer.NeedsFullRender() {
+		t.Error("replacing the palette colors in place should need a full render")
+	}
+
+	dev.SetPixel(0, 0, 0x0F)
+	if renderer.NeedsFullRender() {
+		t.Error("pixel changes should use the dirty region")
+	}
+
+	// The multiplex ratio blanks rows without touching VRAM
+	dev.ProcessCommand(device.CmdSetMultiplexRatio, []byte{0x1F})
+	if !renderer.NeedsFullRender() {
+		t.Error("changing the multiplex ratio should need a full render")
+	}
+}
+
+func TestRendererPersistence(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	renderer := NewRenderer(dev, 1)
+	dev.ProcessCommand(device.CmdSetContrast, []byte{0xFF})
+	renderer.SetPersistence(4)
+
+	brightness := func(img *image.RGBA) uint32 {
+		r, _, _, _ := img.At(0, 0).RGBA()
+		return r >> 8
+	}
+
+	dev.SetPixel(0, 0, 0x0F)
+	lit := brightness(renderer.renderRegion(0, 0, 255, 63, true))
+
+	// The pixel turns off but fades over the following frames
+	dev.SetPixel(0, 0, 0x00)
+	off, _, _, _ := renderer.palette.Colors[0].RGBA()
+
+	previous := lit
+	for frame := 1; frame < 4; frame++ {
+		current := brightness(renderer.renderRegion(0, 0, 255, 63, true))
+		if current >= previous || current <= off>>8 {
+			t.Fatalf("frame %d: expected fading brightness between %d and %d, got %d", frame, off>>8, previous, current)
+		}
+		previous = current
+	}
+
+	if !renderer.NeedsFullRender() {
+		t.Error("fading pixels should force a full render")
+	}
+
+	if current := brightness(renderer.renderRegion(0, 0, 255, 63, true)); current != off>>8 {
+		t.Errorf("pixel should be fully off after the persistence frames, got %d", current)
+	}
+
+	// Disabling persistence restores exact rendering
+	dev.SetPixel(0, 0, 0x0F)
+	renderer.renderRegion(0, 0, 255, 63, true)
+	dev.SetPixel(0, 0, 0x00)
+	renderer.SetPersistence(0)
+	if current := brightness(renderer.renderRegion(0, 0, 255, 63, true)); current != off>>8 {
+		t.Errorf("persistence 0 should render exactly, got %d", current)
+	}
+}
+
+func TestRendererPixelGap(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	renderer := NewRenderer(dev, 4)
+	renderer.SetBackgroundColor(color.RGBA{R: 1, G: 2, B: 3, A: 255})
+
+	if err := renderer.SetPixelGap(4); err == nil {
+		t.Error("expected error for gap equal to the scale")
+	}
+	if err := renderer.SetPixelGap(1); err != nil {
+		t.Fatalf("SetPixelGap failed: %v", err)
+	}
+
+	dev.SetPixel(0, 0, 0x0F)
+	img := renderer.RenderToRGBA()
+
+	lit := color.RGBAModel.Convert(renderer.pixelColor(0, 0))
+	gap := color.RGBAModel.Convert(renderer.backgroundColor)
+
+	if img.At(2, 2) != lit {
+		t.Error("pixel body should use the pixel color")
+	}
+	if img.At(3, 0) != gap || img.At(0, 3) != gap {
+		t.Error("right and bottom edges should use the background color")
+	}
+}
+
+func TestRendererGrayscaleTable(t *testing.T) {
+	dev := device.NewSSD1322(256, 64)
+	renderer := NewRenderer(dev, 1)
+
+	renderer.NeedsFullRender()
+	dev.SetPixel(0, 0, 0x08)
+	colors := renderer.effectivePalette(renderer.brightnessFactor())
+	if got := renderer.pixelColor(0, 0); got != colors[8] {
+		t.Errorf("expected default table to be linear, got %v", got)
+	}
+
+	// Halve every level's brightness
+	table := make([]byte, device.GrayscaleTableSize)
+	for i := range table {
+		table[i] = byte((i + 1) * device.GrayscaleMax / device.GrayscaleTableSize / 2)
+	}
+	if err := dev.SetGrayscaleTable(table); err != nil {
+		t.Fatalf("SetGrayscaleTable failed: %v", err)
+	}
+
+	if got := renderer.pixelColor(0, 0); got != colors[4] {
+		t.Errorf("expected level 8 to render as level 4, got %v", got)
+	}
+	if !renderer.NeedsFullRender() {
+		t.Error("changing the grayscale table should force a full render")
+	}
+}
@@ -0,0 +1,128 @@
+// Package testutil provides helpers for snapshot-testing rendered displays
+package testutil
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// UpdateGoldensEnv is the environment variable that switches CompareToReference into update mode
+// When set to a non-empty value, the reference image is overwritten with the rendered one
+const UpdateGoldensEnv = "UPDATE_GOLDENS"
+
+// mismatchColor marks differing pixels in diff images
+var mismatchColor = color.RGBA{R: 255, A: 255}
+
+// CompareToReference compares got against the golden PNG at refPath
+// Pixels match when every channel differs by at most tolerance (0-255). The returned diff
+// image shows matching pixels dimmed and mismatches in red; it is nil when the reference
+// cannot be read. In update mode the reference is rewritten from got and the result is a match
+func CompareToReference(got image.Image, refPath string, tolerance int) (bool, image.Image) {
+	if os.Getenv(UpdateGoldensEnv) != "" {
+		if err := WritePNG(refPath, got); err != nil {
+			return false, nil
+		}
+		return true, nil
+	}
+
+	want, err := ReadPNG(refPath)
+	if err != nil {
+		return false, nil
+	}
+
+	return CompareImages(got, want, tolerance)
+}
+
+// CompareImages compares two images pixel by pixel within tolerance
+// Images of different sizes never match; the diff covers both and marks non-overlapping pixels red
+func CompareImages(got, want image.Image, tolerance int) (bool, image.Image) {
+	gotBounds := got.Bounds()
+	wantBounds := want.Bounds()
+
+	union := image.Rect(0, 0, max(gotBounds.Dx(), wantBounds.Dx()), max(gotBounds.Dy(), wantBounds.Dy()))
+	diff := image.NewRGBA(union)
+	match := gotBounds.Size() == wantBounds.Size()
+
+	for y := 0; y < union.Dy(); y++ {
+		for x := 0; x < union.Dx(); x++ {
+			gp := image.Pt(gotBounds.Min.X+x, gotBounds.Min.Y+y)
+			wp := image.Pt(wantBounds.Min.X+x, wantBounds.Min.Y+y)
+
+			if !gp.In(gotBounds) || !wp.In(wantBounds) {
+				diff.Set(x, y, mismatchColor)
+				continue
+			}
+
+			gc := got.At(gp.X, gp.Y)
+			if !colorsMatch(gc, want.At(wp.X, wp.Y), tolerance) {
+				diff.Set(x, y, mismatchColor)
+				match = false
+				continue
+			}
+
+			diff.Set(x, y, dim(gc))
+		}
+	}
+
+	return match, diff
+}
+
+// ReadPNG loads a PNG image from path
+func ReadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reference image: %w", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode reference image: %w", err)
+	}
+
+	return img, nil
+}
+
+// WritePNG saves img as a PNG file, creating parent directories as needed
+func WritePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create image file: %w", err)
+	}
+
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	return file.Close()
+}
+
+// colorsMatch reports whether every 8-bit channel of a and b differs by at most tolerance
+func colorsMatch(a, b color.Color, tolerance int) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+
+	for _, pair := range [][2]uint32{{ar, br}, {ag, bg}, {ab, bb}, {aa, ba}} {
+		delta := int(pair[0]>>8) - int(pair[1]>>8)
+		if delta < -tolerance || delta > tolerance {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dim returns c at a quarter of its brightness, used for matching pixels in diff images
+func dim(c color.Color) color.Color {
+	r, g, b, _ := c.RGBA()
+	return color.RGBA{R: uint8(r >> 10), G: uint8(g >> 10), B: uint8(b >> 10), A: 255}
+}
@@ -0,0 +1,62 @@
+package testutil
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func solidImage(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCompareImages(t *testing.T) {
+	want := solidImage(4, 4, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+	got := solidImage(4, 4, color.RGBA{R: 102, G: 100, B: 99, A: 255})
+
+	if ok, _ := CompareImages(got, want, 2); !ok {
+		t.Error("expected images within tolerance to match")
+	}
+
+	got.Set(1, 2, color.White)
+	ok, diff := CompareImages(got, want, 2)
+	if ok {
+		t.Fatal("expected mismatch")
+	}
+	if diff.At(1, 2) != mismatchColor {
+		t.Errorf("expected mismatch highlighted, got %v", diff.At(1, 2))
+	}
+	if diff.At(0, 0) == mismatchColor {
+		t.Error("matching pixel should not be highlighted")
+	}
+
+	if ok, _ := CompareImages(solidImage(4, 3, color.Black), want, 255); ok {
+		t.Error("images of different sizes should not match")
+	}
+}
+
+func TestCompareToReference(t *testing.T) {
+	ref := filepath.Join(t.TempDir(), "golden", "screen.png")
+	img := solidImage(8, 8, color.RGBA{R: 40, G: 170, B: 255, A: 255})
+
+	if ok, diff := CompareToReference(img, ref, 0); ok || diff != nil {
+		t.Error("missing reference should fail without a diff")
+	}
+
+	t.Setenv(UpdateGoldensEnv, "1")
+	if ok, _ := CompareToReference(img, ref, 0); !ok {
+		t.Fatal("update mode should write the reference")
+	}
+
+	t.Setenv(UpdateGoldensEnv, "")
+	if ok, _ := CompareToReference(img, ref, 0); !ok {
+		t.Error("expected rendered image to match the written reference")
+	}
+}